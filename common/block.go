@@ -0,0 +1,215 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// BlockNumber is a block parameter of the JSON-RPC API: a block number or,
+// when negative, one of the block tags.
+type BlockNumber int64
+
+// The block tags.
+const (
+	Pending   BlockNumber = -1
+	Latest    BlockNumber = -2
+	Finalized BlockNumber = -3
+	Safe      BlockNumber = -4
+	Earliest  BlockNumber = -5
+)
+
+var blockTags = map[BlockNumber]string{
+	Pending:   "pending",
+	Latest:    "latest",
+	Finalized: "finalized",
+	Safe:      "safe",
+	Earliest:  "earliest",
+}
+
+// BigToBlockNumber returns the block number n, Latest for nil. It fails if n
+// is negative or does not fit in an int64, rather than yield a block tag.
+func BigToBlockNumber(n *big.Int) (BlockNumber, error) {
+	if n == nil {
+		return Latest, nil
+	}
+	if n.Sign() < 0 || !n.IsInt64() {
+		return 0, fmt.Errorf("Invalid block number %s, out of range", n)
+	}
+	return BlockNumber(n.Int64()), nil
+}
+
+// Uint64ToBlockNumber returns the block number n. It fails if n does not fit
+// in an int64, rather than yield a block tag.
+func Uint64ToBlockNumber(n uint64) (BlockNumber, error) {
+	if n > math.MaxInt64 {
+		return 0, fmt.Errorf("Invalid block number %d, out of range", n)
+	}
+	return BlockNumber(n), nil
+}
+
+// IsTag tells whether n is a block tag rather than a number.
+func (n BlockNumber) IsTag() bool {
+	return n < 0
+}
+
+// String returns the tag, or the number as a hex quantity.
+func (n BlockNumber) String() string {
+	if tag, ok := blockTags[n]; ok {
+		return tag
+	}
+	return Uint64ToHex(uint64(n))
+}
+
+// MarshalText encodes the tag, or the number as a hex quantity.
+func (n BlockNumber) MarshalText() ([]byte, error) {
+	if n < 0 && blockTags[n] == "" {
+		return nil, fmt.Errorf("Invalid block number %d", int64(n))
+	}
+	return []byte(n.String()), nil
+}
+
+// UnmarshalText decodes a tag or a hex quantity.
+func (n *BlockNumber) UnmarshalText(text []byte) error {
+	for number, tag := range blockTags {
+		if string(text) == tag {
+			*n = number
+			return nil
+		}
+	}
+
+	var number HexUint64
+	if err := number.UnmarshalText(text); err != nil {
+		return fmt.Errorf("Invalid block number %q, want a tag or a quantity", text)
+	}
+	if number > math.MaxInt64 {
+		return fmt.Errorf("Invalid block number %q, too large", text)
+	}
+	*n = BlockNumber(number)
+	return nil
+}
+
+// BlockNumberOrHash names a block by number or tag, or by hash as of EIP-1898.
+// The zero value is the latest block.
+type BlockNumberOrHash struct {
+	BlockNumber *BlockNumber `json:"blockNumber,omitempty"`
+	BlockHash   *Hash        `json:"blockHash,omitempty"`
+	// RequireCanonical makes the node fail if the block named by hash is
+	// not in the canonical chain.
+	RequireCanonical bool `json:"requireCanonical,omitempty"`
+}
+
+// BlockNumberOrHashWithNumber names the block by number or tag.
+func BlockNumberOrHashWithNumber(number BlockNumber) BlockNumberOrHash {
+	return BlockNumberOrHash{BlockNumber: &number}
+}
+
+// BlockNumberOrHashWithHash names the block by hash.
+func BlockNumberOrHashWithHash(hash Hash, requireCanonical bool) BlockNumberOrHash {
+	return BlockNumberOrHash{BlockHash: &hash, RequireCanonical: requireCanonical}
+}
+
+// Number returns the block number or tag, if the block is not named by hash.
+func (b BlockNumberOrHash) Number() (BlockNumber, bool) {
+	if b.BlockHash != nil {
+		return 0, false
+	}
+	if b.BlockNumber == nil {
+		return Latest, true
+	}
+	return *b.BlockNumber, true
+}
+
+// Hash returns the hash of the block, if it is named by hash.
+func (b BlockNumberOrHash) Hash() (Hash, bool) {
+	if b.BlockHash == nil {
+		return Hash{}, false
+	}
+	return *b.BlockHash, true
+}
+
+func (b BlockNumberOrHash) String() string {
+	if hash, ok := b.Hash(); ok {
+		return hash.String()
+	}
+	number, _ := b.Number()
+	return number.String()
+}
+
+// MarshalJSON encodes a block named by number as a plain number or tag, which
+// all nodes understand, and a block named by hash as an EIP-1898 object.
+func (b BlockNumberOrHash) MarshalJSON() ([]byte, error) {
+	if b.BlockHash != nil && b.BlockNumber != nil {
+		return nil, errors.New("Invalid block, both a number and a hash are given")
+	}
+	if b.BlockHash == nil {
+		number, _ := b.Number()
+		return json.Marshal(number)
+	}
+	type object BlockNumberOrHash
+	return json.Marshal(object(b))
+}
+
+// UnmarshalJSON decodes a number, a tag, a hash or an EIP-1898 object. null
+// leaves b unchanged.
+func (b *BlockNumberOrHash) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		var hash Hash
+		if len(text) == 2+2*hashLength && hash.UnmarshalText([]byte(text)) == nil {
+			*b = BlockNumberOrHashWithHash(hash, false)
+			return nil
+		}
+		var number BlockNumber
+		if err := number.UnmarshalText([]byte(text)); err != nil {
+			return err
+		}
+		*b = BlockNumberOrHashWithNumber(number)
+		return nil
+	}
+
+	type object BlockNumberOrHash
+	var decoded object
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return fmt.Errorf("Invalid block %s", data)
+	}
+	if (decoded.BlockHash == nil) == (decoded.BlockNumber == nil) {
+		return fmt.Errorf("Invalid block %s, want either a number or a hash", data)
+	}
+	*b = BlockNumberOrHash(decoded)
+	return nil
+}
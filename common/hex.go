@@ -0,0 +1,161 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package common
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxQuantityBits bounds the quantities decoded into a HexBig, quantities
+// being at most 256 bit words.
+const maxQuantityBits = 256
+
+// HexBig is a QUANTITY of the JSON-RPC API, encoded as a 0x prefixed hex
+// string without leading zeros, 0x0 for zero.
+type HexBig big.Int
+
+// NewHexBig returns value as a HexBig, nil for nil.
+func NewHexBig(value *big.Int) *HexBig {
+	return (*HexBig)(value)
+}
+
+// ToInt returns the quantity as a big.Int, nil for nil.
+func (b *HexBig) ToInt() *big.Int {
+	return (*big.Int)(b)
+}
+
+// String ...
+func (b *HexBig) String() string {
+	return BigToHex(b.ToInt())
+}
+
+// MarshalText encodes the quantity. Negative values are rejected.
+func (b HexBig) MarshalText() ([]byte, error) {
+	value := (*big.Int)(&b)
+	if value.Sign() < 0 {
+		return nil, fmt.Errorf("Invalid quantity %s, must not be negative", value)
+	}
+	return []byte(BigToHex(value)), nil
+}
+
+// UnmarshalText decodes a quantity of up to 256 bits.
+func (b *HexBig) UnmarshalText(text []byte) error {
+	digits, err := quantityDigits(text)
+	if err != nil {
+		return err
+	}
+	if len(digits) > maxQuantityBits/4 {
+		return fmt.Errorf("Invalid quantity %q, larger than 256 bits", text)
+	}
+	value, ok := new(big.Int).SetString(digits, 16)
+	if !ok {
+		return fmt.Errorf("Invalid quantity %q", text)
+	}
+	*b = HexBig(*value)
+	return nil
+}
+
+// HexUint64 is a QUANTITY of the JSON-RPC API that fits in 64 bits, such as
+// a block number or an index.
+type HexUint64 uint64
+
+// MarshalText encodes the quantity.
+func (u HexUint64) MarshalText() ([]byte, error) {
+	return []byte(Uint64ToHex(uint64(u))), nil
+}
+
+// UnmarshalText decodes a quantity of up to 64 bits.
+func (u *HexUint64) UnmarshalText(text []byte) error {
+	digits, err := quantityDigits(text)
+	if err != nil {
+		return err
+	}
+	value, err := strconv.ParseUint(digits, 16, 64)
+	if err != nil {
+		return fmt.Errorf("Invalid quantity %q, larger than 64 bits", text)
+	}
+	*u = HexUint64(value)
+	return nil
+}
+
+// HexBytes is DATA of the JSON-RPC API, encoded as a 0x prefixed hex string of
+// two digits per byte, 0x when empty.
+type HexBytes []byte
+
+// MarshalText encodes the data.
+func (b HexBytes) MarshalText() ([]byte, error) {
+	return []byte(BytesToHex(b)), nil
+}
+
+// UnmarshalText decodes data, which must have an even number of digits.
+func (b *HexBytes) UnmarshalText(text []byte) error {
+	s := string(text)
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return fmt.Errorf("Invalid data %q, missing 0x prefix", s)
+	}
+	if len(s)%2 != 0 {
+		return fmt.Errorf("Invalid data %q, odd number of digits", s)
+	}
+	decoded := make([]byte, len(s)/2-1)
+	if _, err := hex.Decode(decoded, text[2:]); err != nil {
+		return fmt.Errorf("Invalid data %q, %v", s, err)
+	}
+	*b = decoded
+	return nil
+}
+
+// String ...
+func (b HexBytes) String() string {
+	return BytesToHex(b)
+}
+
+// quantityDigits returns the hex digits of a quantity, checking that there is
+// at least one and no leading zero.
+func quantityDigits(text []byte) (string, error) {
+	s := string(text)
+	switch {
+	case !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X"):
+		return "", fmt.Errorf("Invalid quantity %q, missing 0x prefix", s)
+	case len(s) == 2:
+		return "", fmt.Errorf("Invalid quantity %q, missing digits", s)
+	case len(s) > 3 && s[2] == '0':
+		return "", fmt.Errorf("Invalid quantity %q, leading zero", s)
+	}
+	for _, c := range s[2:] {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return "", fmt.Errorf("Invalid quantity %q", s)
+		}
+	}
+	return s[2:], nil
+}
@@ -30,13 +30,22 @@
 package common
 
 import (
+	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/33cn/chain33/common/crypto/sha3"
 )
 
 const (
 	hashLength    = 32
 	addressLength = 20
+	nonceLength   = 8
+	bloomLength   = 256
 )
 
 // Hash ...
@@ -47,10 +56,36 @@ func NewHash(data []byte) (result Hash) {
 	return result
 }
 
-func (hash *Hash) String() string {
+func (hash Hash) String() string {
 	return BytesToHex(hash[:])
 }
 
+// MarshalParam encodes the hash as a hex string in requests.
+func (hash Hash) MarshalParam() interface{} {
+	return hash.String()
+}
+
+// MarshalText encodes the hash as a 0x prefixed hex string.
+func (hash Hash) MarshalText() ([]byte, error) {
+	return []byte(hash.String()), nil
+}
+
+// UnmarshalText decodes a 0x prefixed hex string of exactly 32 bytes.
+func (hash *Hash) UnmarshalText(text []byte) error {
+	return decodeFixedHex("hash", text, hash[:])
+}
+
+// MarshalJSON encodes the hash as a hex string.
+func (hash Hash) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hash.String())
+}
+
+// UnmarshalJSON decodes a hex string of exactly 32 bytes. null leaves the hash
+// unchanged.
+func (hash *Hash) UnmarshalJSON(data []byte) error {
+	return unmarshalHexJSON("hash", data, hash.UnmarshalText)
+}
+
 // Address ...
 type Address [addressLength]byte
 
@@ -59,10 +94,186 @@ func NewAddress(data []byte) (result Address) {
 	return result
 }
 
-func (addr *Address) String() string {
+func (addr Address) String() string {
 	return BytesToHex(addr[:])
 }
 
+// Hex returns the address as a hex string with the EIP-55 checksum, in mixed
+// case. String gives it in lower case.
+func (addr Address) Hex() string {
+	return addr.HexForChain(0)
+}
+
+// HexForChain returns the address with the EIP-1191 checksum of the chain,
+// which few chains use, or the EIP-55 checksum if chainID is 0.
+func (addr Address) HexForChain(chainID uint64) string {
+	digits := []byte(hex.EncodeToString(addr[:]))
+	prefix := ""
+	if chainID != 0 {
+		prefix = strconv.FormatUint(chainID, 10) + "0x"
+	}
+	d := sha3.NewKeccak256()
+	d.Write([]byte(prefix))
+	d.Write(digits)
+	hash := d.Sum(nil)
+
+	for i, c := range digits {
+		nibble := hash[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if c >= 'a' && nibble&0xf >= 8 {
+			digits[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(digits)
+}
+
+// ParseAddress parses a hex address of exactly 20 bytes, the 0x prefix being
+// optional. An address in mixed case must carry a valid EIP-55 checksum.
+func ParseAddress(s string) (Address, error) {
+	return ParseAddressForChain(s, 0)
+}
+
+// ParseAddressForChain is like ParseAddress but checks mixed case addresses
+// against the EIP-1191 checksum of the chain, unless chainID is 0.
+func ParseAddressForChain(s string, chainID uint64) (Address, error) {
+	var addr Address
+	digits := s
+	if strings.HasPrefix(digits, "0x") || strings.HasPrefix(digits, "0X") {
+		digits = digits[2:]
+	}
+	if len(digits) != 2*addressLength {
+		return Address{}, fmt.Errorf("Invalid address %q, want %d bytes", s, addressLength)
+	}
+	if _, err := hex.Decode(addr[:], []byte(digits)); err != nil {
+		return Address{}, fmt.Errorf("Invalid address %q, %v", s, err)
+	}
+	if digits != strings.ToLower(digits) && digits != strings.ToUpper(digits) &&
+		digits != addr.HexForChain(chainID)[2:] {
+		return Address{}, fmt.Errorf("Invalid address %q, bad checksum", s)
+	}
+	return addr, nil
+}
+
+// MarshalParam encodes the address as a hex string in requests.
+func (addr Address) MarshalParam() interface{} {
+	return addr.String()
+}
+
+// MarshalText encodes the address as a 0x prefixed hex string.
+func (addr Address) MarshalText() ([]byte, error) {
+	return []byte(addr.String()), nil
+}
+
+// UnmarshalText decodes a 0x prefixed hex string of exactly 20 bytes.
+func (addr *Address) UnmarshalText(text []byte) error {
+	return decodeFixedHex("address", text, addr[:])
+}
+
+// MarshalJSON encodes the address as a hex string.
+func (addr Address) MarshalJSON() ([]byte, error) {
+	return json.Marshal(addr.String())
+}
+
+// UnmarshalJSON decodes a hex string of exactly 20 bytes. null leaves the
+// address unchanged.
+func (addr *Address) UnmarshalJSON(data []byte) error {
+	return unmarshalHexJSON("address", data, addr.UnmarshalText)
+}
+
+// BlockNonce is the 8 byte proof of work nonce of a block.
+type BlockNonce [nonceLength]byte
+
+// Uint64ToBlockNonce returns nonce as a BlockNonce.
+func Uint64ToBlockNonce(nonce uint64) (result BlockNonce) {
+	binary.BigEndian.PutUint64(result[:], nonce)
+	return result
+}
+
+// Uint64 returns the nonce as a number.
+func (nonce BlockNonce) Uint64() uint64 {
+	return binary.BigEndian.Uint64(nonce[:])
+}
+
+func (nonce BlockNonce) String() string {
+	return BytesToHex(nonce[:])
+}
+
+// MarshalText encodes the nonce as a 0x prefixed hex string.
+func (nonce BlockNonce) MarshalText() ([]byte, error) {
+	return []byte(nonce.String()), nil
+}
+
+// UnmarshalText decodes a 0x prefixed hex string of exactly 8 bytes.
+func (nonce *BlockNonce) UnmarshalText(text []byte) error {
+	return decodeFixedHex("nonce", text, nonce[:])
+}
+
+// UnmarshalJSON decodes a hex string of exactly 8 bytes. null leaves the nonce
+// unchanged.
+func (nonce *BlockNonce) UnmarshalJSON(data []byte) error {
+	return unmarshalHexJSON("nonce", data, nonce.UnmarshalText)
+}
+
+// Bloom is the 256 byte bloom filter of the logs of a block or a receipt.
+type Bloom [bloomLength]byte
+
+func NewBloom(data []byte) (result Bloom) {
+	copy(result[:], data)
+	return result
+}
+
+func (bloom Bloom) String() string {
+	return BytesToHex(bloom[:])
+}
+
+// MarshalText encodes the bloom as a 0x prefixed hex string.
+func (bloom Bloom) MarshalText() ([]byte, error) {
+	return []byte(bloom.String()), nil
+}
+
+// UnmarshalText decodes a 0x prefixed hex string of exactly 256 bytes.
+func (bloom *Bloom) UnmarshalText(text []byte) error {
+	return decodeFixedHex("bloom", text, bloom[:])
+}
+
+// UnmarshalJSON decodes a hex string of exactly 256 bytes. null leaves the
+// bloom unchanged.
+func (bloom *Bloom) UnmarshalJSON(data []byte) error {
+	return unmarshalHexJSON("bloom", data, bloom.UnmarshalText)
+}
+
+// decodeFixedHex decodes text, a 0x prefixed hex string of exactly len(out)
+// bytes, into out. out is left untouched on failure.
+func decodeFixedHex(kind string, text []byte, out []byte) error {
+	s := string(text)
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return fmt.Errorf("Invalid %s %q, missing 0x prefix", kind, s)
+	}
+	if len(s)-2 != 2*len(out) {
+		return fmt.Errorf("Invalid %s %q, want %d bytes", kind, s, len(out))
+	}
+	decoded := make([]byte, len(out))
+	if _, err := hex.Decode(decoded, text[2:]); err != nil {
+		return fmt.Errorf("Invalid %s %q, %v", kind, s, err)
+	}
+	copy(out, decoded)
+	return nil
+}
+
+// unmarshalHexJSON hands the string in data to unmarshalText, ignoring null.
+func unmarshalHexJSON(kind string, data []byte, unmarshalText func([]byte) error) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("Invalid %s %s, want a hex string", kind, data)
+	}
+	return unmarshalText([]byte(s))
+}
+
 // SyncStatus ...
 type SyncStatus struct {
 	Result        bool
@@ -86,10 +297,33 @@ func (tx *TransactionRequest) String() string {
 	return string(jsonBytes)
 }
 
+// MarshalParam encodes the transaction as the call object expected by
+// eth_sendTransaction, eth_call and eth_estimateGas. Unset fields are left out,
+// and so is a zero To, as for contract creations.
+func (tx *TransactionRequest) MarshalParam() interface{} {
+	param := map[string]interface{}{"from": tx.From.String()}
+	if tx.To != (Address{}) {
+		param["to"] = tx.To.String()
+	}
+	if tx.Gas != nil {
+		param["gas"] = BigToHex(tx.Gas)
+	}
+	if tx.GasPrice != nil {
+		param["gasPrice"] = BigToHex(tx.GasPrice)
+	}
+	if tx.Value != nil {
+		param["value"] = BigToHex(tx.Value)
+	}
+	if len(tx.Data) > 0 {
+		param["data"] = BytesToHex(tx.Data)
+	}
+	return param
+}
+
 // Transaction ...
 type Transaction struct {
 	Hash             Hash     `json:"hash"`
-	Nonce            Hash     `json:"nonce"`
+	Nonce            uint64   `json:"nonce"`
 	BlockHash        Hash     `json:"blockHash"`
 	BlockNumber      *big.Int `json:"blockNumber"`
 	TransactionIndex uint64   `json:"transactionIndex"`
@@ -106,10 +340,79 @@ func (tx *Transaction) String() string {
 	return string(jsonBytes)
 }
 
+// Topic is a log topic, or a position in the topics of a filter. In filters,
+// a topic with several values in Any matches any of them, and a topic without
+// Data nor Any is a wildcard, encoded as null.
 type Topic struct {
 	Data []byte
+	Any  [][]byte
 }
 
+// topicLength is the length of topics, which are 32 byte words.
+const topicLength = 32
+
+// IsWildcard tells whether the topic matches anything.
+func (topic Topic) IsWildcard() bool {
+	return topic.Data == nil && len(topic.Any) == 0
+}
+
+// MarshalJSON encodes the topic as a hex string, an array of them for Any, or
+// null for a wildcard.
+func (topic Topic) MarshalJSON() ([]byte, error) {
+	if len(topic.Any) > 0 {
+		values := make([]string, 0, len(topic.Any))
+		for _, value := range topic.Any {
+			values = append(values, BytesToHex(value))
+		}
+		return json.Marshal(values)
+	}
+	if topic.Data == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(BytesToHex(topic.Data))
+}
+
+// UnmarshalJSON decodes a hex string of exactly 32 bytes, an array of them, or
+// null. An array holding null is a wildcard, as it matches anything.
+func (topic *Topic) UnmarshalJSON(data []byte) error {
+	*topic = Topic{}
+	if string(data) == "null" {
+		return nil
+	}
+
+	var values []*string
+	if err := json.Unmarshal(data, &values); err != nil {
+		value, err := decodeTopic(data)
+		topic.Data = value
+		return err
+	}
+	for _, value := range values {
+		if value == nil {
+			topic.Any = nil
+			return nil
+		}
+		decoded := make([]byte, topicLength)
+		if err := decodeFixedHex("topic", []byte(*value), decoded); err != nil {
+			return err
+		}
+		topic.Any = append(topic.Any, decoded)
+	}
+	return nil
+}
+
+func decodeTopic(data []byte) ([]byte, error) {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("Invalid topic %s, want a hex string, an array or null", data)
+	}
+	decoded := make([]byte, topicLength)
+	if err := decodeFixedHex("topic", []byte(s), decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+// Topics ...
 type Topics []Topic
 
 // Log ...
@@ -143,23 +446,88 @@ func (tx *TransactionReceipt) String() string {
 
 // Block ...
 type Block struct {
-	Number          *big.Int `json:"number"`
-	Hash            Hash     `json:"hash"`
-	ParentHash      Hash     `json:"parentHash"`
-	Nonce           Hash     `json:"nonce"`
-	Sha3Uncles      Hash     `json:"sha3Uncles"`
-	Bloom           Hash     `json:"logsBloom"`
-	TransactionRoot Hash     `json:"transactionsRoot"`
-	StateRoot       Hash     `json:"stateRoot"`
-	Miner           Address  `json:"miner"`
-	Difficulty      *big.Int `json:"difficulty"`
-	TotalDifficulty *big.Int `json:"totalDifficulty"`
-	ExtraData       Hash     `json:"extraData"`
-	Size            *big.Int `json:"size"`
-	GasLimit        *big.Int `json:"gasLimit"`
-	GasUsed         *big.Int `json:"gasUsed"`
-	Timestamp       *big.Int `json:"timestamp"`
-	Transactions    []Hash   `json:"transactions"`
-	Uncles          []Hash   `json:"uncles"`
+	Number          *big.Int          `json:"number"`
+	Hash            Hash              `json:"hash"`
+	ParentHash      Hash              `json:"parentHash"`
+	Nonce           BlockNonce        `json:"nonce"`
+	Sha3Uncles      Hash              `json:"sha3Uncles"`
+	Bloom           Bloom             `json:"logsBloom"`
+	TransactionRoot Hash              `json:"transactionsRoot"`
+	StateRoot       Hash              `json:"stateRoot"`
+	Miner           Address           `json:"miner"`
+	Difficulty      *big.Int          `json:"difficulty"`
+	TotalDifficulty *big.Int          `json:"totalDifficulty"`
+	ExtraData       []byte            `json:"extraData"`
+	Size            *big.Int          `json:"size"`
+	GasLimit        *big.Int          `json:"gasLimit"`
+	GasUsed         *big.Int          `json:"gasUsed"`
+	Timestamp       *big.Int          `json:"timestamp"`
+	Transactions    BlockTransactions `json:"transactions"`
+	Uncles          []Hash            `json:"uncles"`
 	//MinGasPrice     *big.Int `json:"minGasPrice"`
 }
+
+// BlockTransactions are the transactions of a block: only their hashes, or
+// the full transactions if the block was requested with them.
+type BlockTransactions struct {
+	hashes []Hash
+	full   []Transaction
+	isFull bool
+}
+
+// NewTransactionHashes returns the transactions of a block known by hash.
+func NewTransactionHashes(hashes []Hash) BlockTransactions {
+	return BlockTransactions{hashes: hashes}
+}
+
+// NewFullTransactions returns the full transactions of a block.
+func NewFullTransactions(txs []Transaction) BlockTransactions {
+	return BlockTransactions{full: txs, isFull: true}
+}
+
+// Len returns the number of transactions.
+func (txs BlockTransactions) Len() int {
+	return len(txs.hashes) + len(txs.full)
+}
+
+// Hashes returns the hashes of the transactions, full or not.
+func (txs BlockTransactions) Hashes() []Hash {
+	if !txs.isFull {
+		return txs.hashes
+	}
+	hashes := make([]Hash, 0, len(txs.full))
+	for _, tx := range txs.full {
+		hashes = append(hashes, tx.Hash)
+	}
+	return hashes
+}
+
+// Full returns the full transactions. ok is false if only their hashes are
+// known.
+func (txs BlockTransactions) Full() (full []Transaction, ok bool) {
+	return txs.full, txs.isFull
+}
+
+// MarshalJSON encodes the hashes, or the full transactions.
+func (txs BlockTransactions) MarshalJSON() ([]byte, error) {
+	if txs.Len() == 0 {
+		return []byte("[]"), nil
+	}
+	if txs.isFull {
+		return json.Marshal(txs.full)
+	}
+	return json.Marshal(txs.hashes)
+}
+
+// TransactionWithReceipt pairs a transaction with its receipt.
+type TransactionWithReceipt struct {
+	Transaction Transaction        `json:"transaction"`
+	Receipt     TransactionReceipt `json:"receipt"`
+}
+
+// BlockWithReceipts is a block along with its transactions and their
+// receipts, in the order of the block.
+type BlockWithReceipts struct {
+	Block        *Block                   `json:"block"`
+	Transactions []TransactionWithReceipt `json:"transactions"`
+}
@@ -0,0 +1,325 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package common
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	testHash    = "0xe670ec64341771606e55d6b4ca35a1a6b75ee3d5145a99d05921026d1527331b"
+	testAddress = "0x407d73d8a49eeb85d32cf465507dd71d507100c1"
+)
+
+type TypesTestSuite struct {
+	suite.Suite
+}
+
+func (suite *TypesTestSuite) Test_Hash() {
+	var hash Hash
+	assert.NoError(suite.T(), json.Unmarshal([]byte(`"`+testHash+`"`), &hash), "Should be no error")
+	assert.Equal(suite.T(), StringToHash(testHash), hash, "Should be equal")
+	assert.Equal(suite.T(), testHash, hash.String(), "Should be equal")
+
+	data, err := json.Marshal(hash)
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.Equal(suite.T(), `"`+testHash+`"`, string(data), "Should be equal")
+
+	text, err := hash.MarshalText()
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.Equal(suite.T(), testHash, string(text), "Should be equal")
+
+	// Hashes work as map keys too, through encoding.TextMarshaler.
+	data, err = json.Marshal(map[Hash]int{hash: 1})
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.Equal(suite.T(), `{"`+testHash+`":1}`, string(data), "Should be equal")
+
+	assert.NoError(suite.T(), json.Unmarshal([]byte("null"), &hash), "Should be no error")
+	assert.Equal(suite.T(), testHash, hash.String(), "Should be unchanged")
+
+	for _, invalid := range []string{
+		`"` + testHash[2:] + `"`,
+		`"` + testHash[:64] + `"`,
+		`"` + testHash + `00"`,
+		`"0x` + "zz" + testHash[4:] + `"`,
+		`32`,
+	} {
+		assert.Error(suite.T(), json.Unmarshal([]byte(invalid), &hash), invalid)
+	}
+	assert.Equal(suite.T(), testHash, hash.String(), "Should be unchanged")
+}
+
+func (suite *TypesTestSuite) Test_Address() {
+	var addr Address
+	assert.NoError(suite.T(), addr.UnmarshalText([]byte(testAddress)), "Should be no error")
+	assert.Equal(suite.T(), StringToAddress(testAddress), addr, "Should be equal")
+
+	data, err := json.Marshal(&TransactionRequest{From: addr})
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.Contains(suite.T(), string(data), `"from":"`+testAddress+`"`, "Should be equal")
+
+	assert.EqualError(suite.T(), addr.UnmarshalJSON([]byte(`"0x407d"`)), `Invalid address "0x407d", want 20 bytes`, "Should be equal")
+	assert.EqualError(suite.T(), addr.UnmarshalJSON([]byte(`{}`)), `Invalid address {}, want a hex string`, "Should be equal")
+}
+
+func (suite *TypesTestSuite) Test_Topics() {
+	var topics Topics
+	data := `["` + testHash + `",null,["` + testHash + `","0x0000000000000000000000000000000000000000000000000000000000000000"],[null]]`
+	if assert.NoError(suite.T(), json.Unmarshal([]byte(data), &topics), "Should be no error") && assert.Len(suite.T(), topics, 4) {
+		assert.Equal(suite.T(), HexToBytes(testHash), topics[0].Data, "Should be equal")
+		assert.True(suite.T(), topics[1].IsWildcard(), "Should be a wildcard")
+		assert.Len(suite.T(), topics[2].Any, 2, "Should be equal")
+		assert.True(suite.T(), topics[3].IsWildcard(), "Should be a wildcard")
+	}
+
+	encoded, err := json.Marshal(topics[:3])
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.Equal(suite.T(), data[:len(data)-len(`,[null]]`)]+"]", string(encoded), "Should be equal")
+
+	assert.Error(suite.T(), json.Unmarshal([]byte(`["0x01"]`), &topics), "Should be an error")
+	assert.Error(suite.T(), json.Unmarshal([]byte(`[["0x01"]]`), &topics), "Should be an error")
+	assert.Error(suite.T(), json.Unmarshal([]byte(`[1]`), &topics), "Should be an error")
+}
+
+func (suite *TypesTestSuite) Test_HexBig() {
+	max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	for text, value := range map[string]*big.Int{
+		`"0x0"`:                    big.NewInt(0),
+		`"0x1b4"`:                  big.NewInt(0x1b4),
+		`"0x` + max.Text(16) + `"`: max,
+	} {
+		var quantity HexBig
+		if assert.NoError(suite.T(), json.Unmarshal([]byte(text), &quantity), text) {
+			assert.Equal(suite.T(), 0, value.Cmp(quantity.ToInt()), text)
+		}
+		data, err := json.Marshal(NewHexBig(value))
+		assert.NoError(suite.T(), err, "Should be no error")
+		assert.Equal(suite.T(), text, string(data), "Should be equal")
+	}
+
+	var quantity *HexBig
+	assert.NoError(suite.T(), json.Unmarshal([]byte("null"), &quantity), "Should be no error")
+	assert.Nil(suite.T(), quantity.ToInt(), "Should be nil")
+
+	for _, invalid := range []string{`"0x"`, `"0x01"`, `"1b4"`, `"0xg"`, `"-0x1"`, `"0x1` + max.Text(16) + `"`, `436`} {
+		assert.Error(suite.T(), json.Unmarshal([]byte(invalid), new(HexBig)), invalid)
+	}
+	_, err := json.Marshal(NewHexBig(big.NewInt(-1)))
+	assert.Error(suite.T(), err, "Should be an error")
+}
+
+func (suite *TypesTestSuite) Test_HexUint64() {
+	var quantity HexUint64
+	assert.NoError(suite.T(), json.Unmarshal([]byte(`"0xffffffffffffffff"`), &quantity), "Should be no error")
+	assert.EqualValues(suite.T(), uint64(1<<64-1), quantity, "Should be equal")
+
+	data, err := json.Marshal(HexUint64(0))
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.Equal(suite.T(), `"0x0"`, string(data), "Should be equal")
+
+	for _, invalid := range []string{`"0x"`, `"0x00"`, `"0x10000000000000000"`, `"12"`, `12`} {
+		assert.Error(suite.T(), json.Unmarshal([]byte(invalid), &quantity), invalid)
+	}
+	assert.EqualValues(suite.T(), uint64(1<<64-1), quantity, "Should be unchanged")
+}
+
+func (suite *TypesTestSuite) Test_HexBytes() {
+	var data HexBytes
+	assert.NoError(suite.T(), json.Unmarshal([]byte(`"0x00ff"`), &data), "Should be no error")
+	assert.Equal(suite.T(), HexBytes{0x00, 0xff}, data, "Should be equal")
+	assert.NoError(suite.T(), json.Unmarshal([]byte(`"0x"`), &data), "Should be no error")
+	assert.Empty(suite.T(), data, "Should be empty")
+
+	encoded, err := json.Marshal(struct{ Input HexBytes }{})
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.Equal(suite.T(), `{"Input":"0x"}`, string(encoded), "Should be equal")
+
+	for _, invalid := range []string{`"0x0"`, `"00ff"`, `"0xzz"`, `[0]`} {
+		assert.Error(suite.T(), json.Unmarshal([]byte(invalid), &data), invalid)
+	}
+}
+
+func (suite *TypesTestSuite) Test_BlockNumber() {
+	for text, number := range map[string]BlockNumber{
+		`"latest"`:    Latest,
+		`"pending"`:   Pending,
+		`"earliest"`:  Earliest,
+		`"safe"`:      Safe,
+		`"finalized"`: Finalized,
+		`"0x0"`:       0,
+		`"0x1b4"`:     BlockNumber(0x1b4),
+	} {
+		var decoded BlockNumber
+		assert.NoError(suite.T(), json.Unmarshal([]byte(text), &decoded), text)
+		assert.Equal(suite.T(), number, decoded, text)
+
+		data, err := json.Marshal(number)
+		assert.NoError(suite.T(), err, "Should be no error")
+		assert.Equal(suite.T(), text, string(data), "Should be equal")
+	}
+	number, err := BigToBlockNumber(big.NewInt(0x1b4))
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.Equal(suite.T(), BlockNumber(0x1b4), number, "Should be equal")
+	number, err = BigToBlockNumber(nil)
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.Equal(suite.T(), Latest, number, "Should be equal")
+	number, err = Uint64ToBlockNumber(42)
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.Equal(suite.T(), "0x2a", number.String(), "Should be equal")
+
+	_, err = BigToBlockNumber(big.NewInt(-1))
+	assert.EqualError(suite.T(), err, "Invalid block number -1, out of range", "Should be equal")
+	_, err = BigToBlockNumber(new(big.Int).Lsh(big.NewInt(1), 63))
+	assert.Error(suite.T(), err, "Should be error")
+	_, err = Uint64ToBlockNumber(math.MaxUint64)
+	assert.Error(suite.T(), err, "Should be error")
+
+	for _, invalid := range []string{`"Latest"`, `"lastest"`, `"0x01"`, `"42"`, `"0x8000000000000000"`, `42`} {
+		assert.Error(suite.T(), json.Unmarshal([]byte(invalid), &number), invalid)
+	}
+	_, err = json.Marshal(BlockNumber(-42))
+	assert.Error(suite.T(), err, "Should be an error")
+}
+
+func (suite *TypesTestSuite) Test_BlockNumberOrHash() {
+	hash := StringToHash(testHash)
+	for text, block := range map[string]BlockNumberOrHash{
+		`"latest"`: {},
+		`"0x1b4"`:  BlockNumberOrHashWithNumber(0x1b4),
+		`{"blockHash":"` + testHash + `","requireCanonical":true}`: BlockNumberOrHashWithHash(hash, true),
+	} {
+		data, err := json.Marshal(block)
+		assert.NoError(suite.T(), err, "Should be no error")
+		assert.Equal(suite.T(), text, string(data), "Should be equal")
+	}
+
+	var block BlockNumberOrHash
+	assert.NoError(suite.T(), json.Unmarshal([]byte(`"`+testHash+`"`), &block), "Should be no error")
+	if decoded, ok := block.Hash(); assert.True(suite.T(), ok, "Should be named by hash") {
+		assert.Equal(suite.T(), hash, decoded, "Should be equal")
+	}
+	assert.NoError(suite.T(), json.Unmarshal([]byte(`{"blockNumber":"safe"}`), &block), "Should be no error")
+	if number, ok := block.Number(); assert.True(suite.T(), ok, "Should be named by number") {
+		assert.Equal(suite.T(), Safe, number, "Should be equal")
+	}
+
+	for _, invalid := range []string{`"0x"`, `{}`, `{"blockNumber":"0x1","blockHash":"` + testHash + `"}`, `1`} {
+		assert.Error(suite.T(), json.Unmarshal([]byte(invalid), &block), invalid)
+	}
+	_, err := json.Marshal(BlockNumberOrHash{BlockNumber: new(BlockNumber), BlockHash: &hash})
+	assert.Error(suite.T(), err, "Should be an error")
+}
+
+func (suite *TypesTestSuite) Test_AddressChecksum() {
+	for _, checksummed := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	} {
+		addr, err := ParseAddress(checksummed)
+		if assert.NoError(suite.T(), err, checksummed) {
+			assert.Equal(suite.T(), checksummed, addr.Hex(), "Should be equal")
+			assert.Equal(suite.T(), strings.ToLower(checksummed), addr.String(), "Should be equal")
+		}
+	}
+
+	addr, err := ParseAddressForChain("0xFb6916095cA1Df60bb79ce92cE3EA74c37c5d359", 30)
+	if assert.NoError(suite.T(), err, "Should be no error") {
+		assert.Equal(suite.T(), "0xFb6916095cA1Df60bb79ce92cE3EA74c37c5d359", addr.HexForChain(30), "Should be equal")
+	}
+	_, err = ParseAddress("0xFb6916095cA1Df60bb79ce92cE3EA74c37c5d359")
+	assert.EqualError(suite.T(), err, `Invalid address "0xFb6916095cA1Df60bb79ce92cE3EA74c37c5d359", bad checksum`, "Should be equal")
+
+	for _, valid := range []string{testAddress, strings.ToUpper(testAddress[2:]), testAddress[2:]} {
+		addr, err := ParseAddress(valid)
+		assert.NoError(suite.T(), err, valid)
+		assert.Equal(suite.T(), testAddress, addr.String(), "Should be equal")
+	}
+	_, err = ParseAddress(testAddress[:40])
+	assert.EqualError(suite.T(), err, `Invalid address "0x407d73d8a49eeb85d32cf465507dd71d507100", want 20 bytes`, "Should be equal")
+	_, err = ParseAddress("0x" + strings.Repeat("zz", 20))
+	assert.Error(suite.T(), err, "Should be an error")
+}
+
+func (suite *TypesTestSuite) Test_BlockNonceAndBloom() {
+	var nonce BlockNonce
+	assert.NoError(suite.T(), json.Unmarshal([]byte(`"0x689056015818adbe"`), &nonce), "Should be no error")
+	assert.EqualValues(suite.T(), uint64(0x689056015818adbe), nonce.Uint64(), "Should be equal")
+	assert.Equal(suite.T(), "0x0000000000000042", Uint64ToBlockNonce(0x42).String(), "Should be equal")
+	assert.Error(suite.T(), json.Unmarshal([]byte(`"0x42"`), &nonce), "Should be an error")
+
+	var bloom Bloom
+	data := `"0x` + strings.Repeat("00", 255) + `01"`
+	assert.NoError(suite.T(), json.Unmarshal([]byte(data), &bloom), "Should be no error")
+	assert.EqualValues(suite.T(), 1, bloom[255], "Should be equal")
+	encoded, err := json.Marshal(bloom)
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.Equal(suite.T(), data, string(encoded), "Should be equal")
+	assert.Error(suite.T(), json.Unmarshal([]byte(`"`+testHash+`"`), &bloom), "Should be an error")
+}
+
+func (suite *TypesTestSuite) Test_BlockTransactions() {
+	hash := StringToHash(testHash)
+	hashes := NewTransactionHashes([]Hash{hash})
+	full := NewFullTransactions([]Transaction{{Hash: hash}})
+	for _, txs := range []BlockTransactions{hashes, full} {
+		assert.Equal(suite.T(), 1, txs.Len(), "Should be equal")
+		assert.Equal(suite.T(), []Hash{hash}, txs.Hashes(), "Should be equal")
+	}
+
+	data, err := json.Marshal(hashes)
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.Equal(suite.T(), `["`+testHash+`"]`, string(data), "Should be equal")
+	_, ok := hashes.Full()
+	assert.False(suite.T(), ok, "Should only have the hashes")
+	_, ok = full.Full()
+	assert.True(suite.T(), ok, "Should have the full transactions")
+
+	// Empty blocks keep the mode they were built with.
+	_, ok = NewTransactionHashes(nil).Full()
+	assert.False(suite.T(), ok, "Should only have the hashes")
+	empty, ok := NewFullTransactions(nil).Full()
+	assert.True(suite.T(), ok && len(empty) == 0, "Should have no full transactions")
+	data, err = json.Marshal(NewFullTransactions(nil))
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.Equal(suite.T(), `[]`, string(data), "Should be equal")
+}
+
+func Test_TypesTestSuite(t *testing.T) {
+	suite.Run(t, new(TypesTestSuite))
+}
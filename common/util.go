@@ -32,6 +32,7 @@ package common
 import (
 	"bytes"
 	"encoding/gob"
+	"math/big"
 	"regexp"
 	"strconv"
 	"strings"
@@ -61,6 +62,16 @@ func BytesToHex(data []byte) string {
 	return "0x" + buffer.String()
 }
 
+// BigToHex encodes a quantity as a hex string without leading zeros.
+func BigToHex(value *big.Int) string {
+	return "0x" + value.Text(16)
+}
+
+// Uint64ToHex encodes a quantity as a hex string without leading zeros.
+func Uint64ToHex(value uint64) string {
+	return "0x" + strconv.FormatUint(value, 16)
+}
+
 func HexToBytes(hex string) []byte {
 	hex = HexToString(hex)
 	length := len(hex) / 2
@@ -34,22 +34,24 @@ import (
 	"fmt"
 
 	"github.com/yangyuan6/web3go/provider"
-	"github.com/yangyuan6/web3go/rpc"
 	"github.com/yangyuan6/web3go/web3"
 )
 
-var hostname = flag.String("hostname", "localhost", "The ethereum client RPC host")
-var port = flag.String("port", "8545", "The ethereum client RPC port")
+var endpoint = flag.String("url", "http://localhost:8545", "The ethereum client RPC URL")
 var verbose = flag.Bool("verbose", false, "Print verbose messages")
 
 func main() {
 	flag.Parse()
 
 	if *verbose {
-		fmt.Printf("Connect to %s:%s\n", *hostname, *port)
+		fmt.Printf("Connect to %s\n", *endpoint)
 	}
 
-	provider := provider.NewHTTPProvider(*hostname+":"+*port, rpc.GetDefaultMethod())
+	provider, err := provider.Dial(*endpoint)
+	if err != nil {
+		fmt.Printf("Failed to create provider, %v\n", err)
+		return
+	}
 	web3Api := web3.NewWeb3(provider)
 
 	filter, err := web3Api.Eth.NewBlockFilter()
@@ -27,31 +27,51 @@
 // ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
 // POSSIBILITY OF SUCH DAMAGE.
 
-package test
+package main
 
 import (
-	"encoding/json"
+	"flag"
 	"fmt"
 
-	"github.com/yangyuan6/web3go/rpc"
+	"github.com/yangyuan6/web3go/provider"
+	"github.com/yangyuan6/web3go/web3"
 )
 
-func generateResponse(rpc rpc.RPC, request rpc.Request, result interface{}) (response rpc.Response, err error) {
-	data := struct {
-		Version string      `json:"jsonrpc"`
-		ID      uint64      `json:"id"`
-		Result  interface{} `json:"result"`
-	}{
-		request.Get("version").(string),
-		request.ID(),
-		result,
+var endpoint = flag.String("url", "ws://localhost:8546", "The ethereum client WebSocket or IPC URL")
+var verbose = flag.Bool("verbose", false, "Print verbose messages")
+
+func main() {
+	flag.Parse()
+
+	if *verbose {
+		fmt.Printf("Connect to %s\n", *endpoint)
+	}
+
+	provider, err := provider.Dial(*endpoint)
+	if err != nil {
+		fmt.Printf("Failed to create provider, %v\n", err)
+		return
 	}
-	rawData, err := json.Marshal(data)
+	web3Api := web3.NewWeb3(provider)
+
+	sub, err := web3Api.Eth.SubscribeNewHeads()
 	if err != nil {
-		return nil, err
+		fmt.Printf("Failed to subscribe, %v\n", err)
+		return
 	}
-	if resp := rpc.NewResponse(rawData); resp != nil {
-		return resp, nil
+	defer sub.Close()
+
+	fmt.Printf("Subscription ID: %s\n", sub.ID())
+
+	for {
+		header, err := sub.Next()
+		if err != nil {
+			if subErr, ok := <-sub.Err(); ok {
+				err = subErr
+			}
+			fmt.Printf("%v\n", err)
+			return
+		}
+		fmt.Printf("Header: %v\n", header)
 	}
-	return nil, fmt.Errorf("Failed to generate response")
 }
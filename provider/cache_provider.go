@@ -0,0 +1,444 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package provider
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yangyuan6/web3go/rpc"
+)
+
+// immutableMethods return the same result forever once they return one.
+var immutableMethods = map[string]bool{
+	"eth_chainId":                           true,
+	"net_version":                           true,
+	"eth_getBlockByHash":                    true,
+	"eth_getBlockTransactionCountByHash":    true,
+	"eth_getTransactionByBlockHashAndIndex": true,
+	"eth_getUncleByBlockHashAndIndex":       true,
+	"eth_getUncleCountByBlockHash":          true,
+}
+
+// minedMethods return data about a transaction, which only settles once it is
+// mined and may still change in a reorganization.
+var minedMethods = map[string]bool{
+	"eth_getTransactionByHash":  true,
+	"eth_getTransactionReceipt": true,
+}
+
+// blockParams gives the index of the block number parameter of the methods
+// whose result depends on it.
+var blockParams = map[string]int{
+	"eth_getBalance":                          1,
+	"eth_getCode":                             1,
+	"eth_getTransactionCount":                 1,
+	"eth_getStorageAt":                        2,
+	"eth_call":                                1,
+	"eth_getBlockByNumber":                    0,
+	"eth_getBlockTransactionCountByNumber":    0,
+	"eth_getTransactionByBlockNumberAndIndex": 0,
+	"eth_getUncleByBlockNumberAndIndex":       0,
+	"eth_getUncleCountByBlockNumber":          0,
+}
+
+// CacheConfig tunes a CacheProvider.
+type CacheConfig struct {
+	// Size is the number of responses kept, the least recently used ones
+	// being evicted first.
+	Size int
+	// TTL is how long responses that may still change, such as those about
+	// recent blocks, are kept. Zero disables caching them.
+	TTL time.Duration
+	// FinalityDepth is the number of blocks after which a block is deemed
+	// final, so that responses about it are kept until evicted. It is
+	// measured from the latest eth_blockNumber seen. Zero disables it.
+	FinalityDepth uint64
+}
+
+// DefaultCacheConfig is a sensible CacheConfig for Ethereum mainnet.
+var DefaultCacheConfig = CacheConfig{
+	Size:          4096,
+	TTL:           10 * time.Second,
+	FinalityDepth: 64,
+}
+
+// CacheStats counts how a CacheProvider fared.
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Entries   int
+}
+
+// CacheProvider caches the responses of the provider it wraps, keyed on the
+// method and the normalized params of requests. Only methods whose results
+// are known not to change, or to change predictably, are cached; requests
+// about the latest, pending, safe or finalized block never are. Errors and
+// null results are not cached either.
+type CacheProvider struct {
+	provider Provider
+	config   CacheConfig
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	head    uint64
+	stats   CacheStats
+}
+
+type cacheEntry struct {
+	key     string
+	result  json.RawMessage
+	expires time.Time
+}
+
+// NewCacheProvider wraps provider so that responses are cached.
+func NewCacheProvider(provider Provider, config CacheConfig) Provider {
+	if config.Size <= 0 {
+		config.Size = DefaultCacheConfig.Size
+	}
+	return &CacheProvider{
+		provider: provider,
+		config:   config,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// IsConnected ...
+func (provider *CacheProvider) IsConnected() bool {
+	return provider.provider.IsConnected()
+}
+
+// Send answers the request from the cache, or sends it and caches the
+// response.
+func (provider *CacheProvider) Send(request rpc.Request) (rpc.Response, error) {
+	return provider.SendContext(context.Background(), request)
+}
+
+// SendContext is like Send but takes a context.
+func (provider *CacheProvider) SendContext(ctx context.Context, request rpc.Request) (rpc.Response, error) {
+	if result, ok := provider.lookup(request); ok {
+		return provider.GetRPCMethod().NewReply(request, result, nil), nil
+	}
+
+	resp, err := provider.provider.SendContext(ctx, request)
+	if err == nil {
+		provider.store(request, resp)
+	}
+	return resp, err
+}
+
+// SendBatch answers the requests it can from the cache and sends the others
+// in one round trip if the wrapped provider supports it.
+func (provider *CacheProvider) SendBatch(requests []rpc.Request) ([]rpc.Response, error) {
+	return provider.SendBatchContext(context.Background(), requests)
+}
+
+// SendBatchContext is like SendBatch but takes a context.
+func (provider *CacheProvider) SendBatchContext(ctx context.Context, requests []rpc.Request) ([]rpc.Response, error) {
+	responses := make([]rpc.Response, len(requests))
+	var missed []rpc.Request
+	var indexes []int
+	for i, request := range requests {
+		if result, ok := provider.lookup(request); ok {
+			responses[i] = provider.GetRPCMethod().NewReply(request, result, nil)
+		} else {
+			missed = append(missed, request)
+			indexes = append(indexes, i)
+		}
+	}
+	if len(missed) == 0 {
+		return responses, nil
+	}
+
+	sent, err := SendBatch(ctx, provider.provider, missed)
+	if err != nil {
+		return nil, err
+	}
+	for i, resp := range sent {
+		provider.store(missed[i], resp)
+		responses[indexes[i]] = resp
+	}
+	return responses, nil
+}
+
+// Subscribe subscribes through the wrapped provider. Notifications are not
+// cached.
+func (provider *CacheProvider) Subscribe(ctx context.Context, request rpc.Request, handler NotificationHandler) (rpc.Response, error) {
+	return Subscribe(ctx, provider.provider, request, handler)
+}
+
+// Unsubscribe ...
+func (provider *CacheProvider) Unsubscribe(subscriptionID string) {
+	Unsubscribe(provider.provider, subscriptionID)
+}
+
+// GetRPCMethod ...
+func (provider *CacheProvider) GetRPCMethod() rpc.RPC {
+	return provider.provider.GetRPCMethod()
+}
+
+// Stats returns the cache statistics so far.
+func (provider *CacheProvider) Stats() CacheStats {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	stats := provider.stats
+	stats.Entries = provider.lru.Len()
+	return stats
+}
+
+// Purge empties the cache.
+func (provider *CacheProvider) Purge() {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	provider.entries = make(map[string]*list.Element)
+	provider.lru.Init()
+}
+
+// lookup returns the cached result of request, if any.
+func (provider *CacheProvider) lookup(request rpc.Request) (json.RawMessage, bool) {
+	method, _ := request.Get("method").(string)
+	params, key, ok := normalizeParams(method, request.Get("params"))
+	if !ok || !provider.cacheable(method, params) {
+		return nil, false
+	}
+	return provider.get(key)
+}
+
+// store caches resp, the response to request, if it may be.
+func (provider *CacheProvider) store(request rpc.Request, resp rpc.Response) {
+	if resp == nil {
+		return
+	}
+	method, _ := request.Get("method").(string)
+	if method == "eth_blockNumber" {
+		provider.observeHead(resp)
+	}
+
+	params, key, ok := normalizeParams(method, request.Get("params"))
+	if !ok || resp.Error() != nil || resp.Get("result") == nil {
+		return
+	}
+	if ttl, ok := provider.ttl(method, params, resp.Get("result")); ok {
+		var result json.RawMessage
+		if err := resp.DecodeResult(&result); err == nil {
+			provider.put(key, result, ttl)
+		}
+	}
+}
+
+// cacheable tells whether the response to a request may be cached at all.
+func (provider *CacheProvider) cacheable(method string, params []interface{}) bool {
+	_, ok := provider.ttl(method, params, nil)
+	return ok
+}
+
+// ttl tells how long the response to a request may be cached, zero meaning
+// until evicted. result is nil when the request has not been sent yet.
+func (provider *CacheProvider) ttl(method string, params []interface{}, result interface{}) (time.Duration, bool) {
+	switch {
+	case immutableMethods[method]:
+		return 0, true
+	case minedMethods[method]:
+		if result == nil {
+			return 0, true
+		}
+		tx, _ := result.(map[string]interface{})
+		if tx == nil || tx["blockHash"] == nil {
+			return 0, false
+		}
+		return provider.blockTTL(tx["blockNumber"])
+	case method == "eth_getLogs":
+		if len(params) == 0 {
+			return 0, false
+		}
+		filter, _ := params[0].(map[string]interface{})
+		if filter == nil {
+			return 0, false
+		}
+		if filter["blockHash"] != nil {
+			return 0, true
+		}
+		if _, ok := provider.blockTTL(filter["fromBlock"]); !ok {
+			return 0, false
+		}
+		return provider.blockTTL(filter["toBlock"])
+	}
+
+	index, ok := blockParams[method]
+	if !ok {
+		return 0, false
+	}
+	if index >= len(params) {
+		return 0, false
+	}
+	return provider.blockTTL(params[index])
+}
+
+// blockTTL tells how long data about the given block may be cached. block is
+// a block number, a tag, or an object naming a block by hash or number.
+func (provider *CacheProvider) blockTTL(block interface{}) (time.Duration, bool) {
+	switch b := block.(type) {
+	case string:
+		if b == "earliest" {
+			return 0, true
+		}
+		number, ok := parseQuantity(b)
+		if !ok {
+			return 0, false
+		}
+
+		provider.mu.Lock()
+		head := provider.head
+		provider.mu.Unlock()
+		if provider.config.FinalityDepth > 0 && head > 0 && number+provider.config.FinalityDepth <= head {
+			return 0, true
+		}
+		return provider.config.TTL, provider.config.TTL > 0
+	case map[string]interface{}:
+		if b["blockHash"] != nil {
+			return 0, true
+		}
+		return provider.blockTTL(b["blockNumber"])
+	}
+	return 0, false
+}
+
+func (provider *CacheProvider) observeHead(resp rpc.Response) {
+	result, _ := resp.Get("result").(string)
+	if number, ok := parseQuantity(result); ok {
+		provider.mu.Lock()
+		if number > provider.head {
+			provider.head = number
+		}
+		provider.mu.Unlock()
+	}
+}
+
+func (provider *CacheProvider) get(key string) (json.RawMessage, bool) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	element, ok := provider.entries[key]
+	if ok {
+		entry := element.Value.(*cacheEntry)
+		if entry.expires.IsZero() || time.Now().Before(entry.expires) {
+			provider.lru.MoveToFront(element)
+			provider.stats.Hits++
+			return entry.result, true
+		}
+		provider.lru.Remove(element)
+		delete(provider.entries, key)
+	}
+	provider.stats.Misses++
+	return nil, false
+}
+
+func (provider *CacheProvider) put(key string, result json.RawMessage, ttl time.Duration) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	entry := &cacheEntry{key: key, result: result}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+	if element, ok := provider.entries[key]; ok {
+		element.Value = entry
+		provider.lru.MoveToFront(element)
+		return
+	}
+
+	provider.entries[key] = provider.lru.PushFront(entry)
+	for provider.lru.Len() > provider.config.Size {
+		oldest := provider.lru.Back()
+		provider.lru.Remove(oldest)
+		delete(provider.entries, oldest.Value.(*cacheEntry).key)
+		provider.stats.Evictions++
+	}
+}
+
+// normalizeParams returns params as decoded from JSON, along with a cache key
+// that is the same for equal requests: object keys are sorted and hex strings
+// lower cased.
+func normalizeParams(method string, params interface{}) ([]interface{}, string, bool) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, "", false
+	}
+	var normalized []interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, "", false
+	}
+	for i := range normalized {
+		normalized[i] = lowerHex(normalized[i])
+	}
+
+	key, err := json.Marshal(normalized)
+	if err != nil {
+		return nil, "", false
+	}
+	return normalized, method + string(key), true
+}
+
+func lowerHex(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if strings.HasPrefix(v, "0x") || strings.HasPrefix(v, "0X") {
+			return strings.ToLower(v)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = lowerHex(v[i])
+		}
+	case map[string]interface{}:
+		for k := range v {
+			v[k] = lowerHex(v[k])
+		}
+	}
+	return value
+}
+
+// parseQuantity parses a hex encoded quantity such as a block number.
+func parseQuantity(s string) (uint64, bool) {
+	if !strings.HasPrefix(s, "0x") {
+		return 0, false
+	}
+	number, err := strconv.ParseUint(s[2:], 16, 64)
+	return number, err == nil
+}
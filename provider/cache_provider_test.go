@@ -0,0 +1,249 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/yangyuan6/web3go/rpc"
+)
+
+// countingProvider answers each method with a fixed result, or fails with a
+// fixed error, and counts the requests it receives.
+type countingProvider struct {
+	results  map[string]string
+	failures map[string]error
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (p *countingProvider) count(method string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls[method]
+}
+
+func (p *countingProvider) IsConnected() bool {
+	return true
+}
+
+func (p *countingProvider) Send(request rpc.Request) (rpc.Response, error) {
+	return p.SendContext(context.Background(), request)
+}
+
+func (p *countingProvider) SendContext(ctx context.Context, request rpc.Request) (rpc.Response, error) {
+	method := request.Get("method").(string)
+
+	p.mu.Lock()
+	p.calls[method]++
+	p.mu.Unlock()
+
+	if err, ok := p.failures[method]; ok {
+		return nil, err
+	}
+	result, ok := p.results[method]
+	if !ok {
+		body := fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"error":{"code":-32601,"message":"method not found"}}`, request.ID())
+		return p.GetRPCMethod().NewResponse([]byte(body)), nil
+	}
+	return p.GetRPCMethod().NewResponse([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":%s}`, request.ID(), result))), nil
+}
+
+func (p *countingProvider) GetRPCMethod() rpc.RPC {
+	return rpc.GetDefaultMethod()
+}
+
+type CacheProviderTestSuite struct {
+	suite.Suite
+	inner    *countingProvider
+	provider *CacheProvider
+	id       uint64
+}
+
+func (suite *CacheProviderTestSuite) send(method string, params ...interface{}) rpc.Response {
+	suite.id++
+	req := &rpc.JSONRPCRequest{Version: "2.0", Method: method, Params: params, Identifier: rpc.NumberID(suite.id)}
+	resp, err := suite.provider.Send(req)
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.EqualValues(suite.T(), req.ID(), resp.ID(), "Should answer the request")
+	return resp
+}
+
+func (suite *CacheProviderTestSuite) Test_Immutable() {
+	for i := 0; i < 3; i++ {
+		resp := suite.send("eth_chainId")
+		assert.EqualValues(suite.T(), "0x1", resp.Get("result"), "Should be equal")
+	}
+	assert.Equal(suite.T(), 1, suite.inner.count("eth_chainId"), "Should be equal")
+
+	suite.send("eth_getBlockByHash", "0xABCD", false)
+	suite.send("eth_getBlockByHash", "0xabcd", false)
+	suite.send("eth_getBlockByHash", "0xabcd", true)
+	assert.Equal(suite.T(), 2, suite.inner.count("eth_getBlockByHash"), "Should normalize hex params")
+
+	stats := suite.provider.Stats()
+	assert.EqualValues(suite.T(), 3, stats.Hits, "Should be equal")
+	assert.EqualValues(suite.T(), 3, stats.Misses, "Should be equal")
+	assert.EqualValues(suite.T(), 3, stats.Entries, "Should be equal")
+}
+
+func (suite *CacheProviderTestSuite) Test_CopiesResults() {
+	resp := suite.send("eth_getBlockByHash", "0xabcd", false)
+	resp.Get("result").(map[string]interface{})["number"] = "0x0"
+
+	resp = suite.send("eth_getBlockByHash", "0xabcd", false)
+	assert.EqualValues(suite.T(), "0x10", resp.Get("result").(map[string]interface{})["number"], "Should be equal")
+}
+
+func (suite *CacheProviderTestSuite) Test_MovingTags() {
+	for _, tag := range []string{"latest", "pending", "safe", "finalized"} {
+		suite.send("eth_getBalance", "0x1", tag)
+		suite.send("eth_getBalance", "0x1", tag)
+	}
+	suite.send("eth_getLogs", map[string]interface{}{"fromBlock": "0x1"})
+	suite.send("eth_getLogs", map[string]interface{}{"fromBlock": "0x1"})
+	suite.send("eth_blockNumber")
+	suite.send("eth_blockNumber")
+
+	assert.Equal(suite.T(), 8, suite.inner.count("eth_getBalance"), "Should be equal")
+	assert.Equal(suite.T(), 2, suite.inner.count("eth_getLogs"), "Should be equal")
+	assert.Equal(suite.T(), 2, suite.inner.count("eth_blockNumber"), "Should be equal")
+	assert.EqualValues(suite.T(), 0, suite.provider.Stats().Entries, "Should be equal")
+}
+
+func (suite *CacheProviderTestSuite) Test_Finality() {
+	// The head is unknown, so block 0x10 may still change.
+	suite.send("eth_getBalance", "0x1", "0x10")
+	suite.provider.mu.Lock()
+	expires := suite.provider.lru.Front().Value.(*cacheEntry).expires
+	suite.provider.mu.Unlock()
+	assert.False(suite.T(), expires.IsZero(), "Should expire")
+
+	// eth_blockNumber answers 0x100, so block 0x10 is final.
+	suite.send("eth_blockNumber")
+	suite.send("eth_getBalance", "0x2", "0x10")
+	suite.provider.mu.Lock()
+	expires = suite.provider.lru.Front().Value.(*cacheEntry).expires
+	suite.provider.mu.Unlock()
+	assert.True(suite.T(), expires.IsZero(), "Should not expire")
+
+	suite.send("eth_getLogs", map[string]interface{}{"fromBlock": "0x1", "toBlock": "0x10"})
+	suite.send("eth_getLogs", map[string]interface{}{"toBlock": "0x10", "fromBlock": "0x1"})
+	assert.Equal(suite.T(), 1, suite.inner.count("eth_getLogs"), "Should be equal")
+}
+
+func (suite *CacheProviderTestSuite) Test_TTL() {
+	suite.provider.config.TTL = 20 * time.Millisecond
+	suite.send("eth_getBlockByNumber", "0x10", false)
+	suite.send("eth_getBlockByNumber", "0x10", false)
+	assert.Equal(suite.T(), 1, suite.inner.count("eth_getBlockByNumber"), "Should be equal")
+
+	time.Sleep(30 * time.Millisecond)
+	suite.send("eth_getBlockByNumber", "0x10", false)
+	assert.Equal(suite.T(), 2, suite.inner.count("eth_getBlockByNumber"), "Should be equal")
+}
+
+func (suite *CacheProviderTestSuite) Test_NotCached() {
+	// Pending transactions and null results may change.
+	suite.inner.results["eth_getTransactionByHash"] = `{"hash":"0x1","blockHash":null,"blockNumber":null}`
+	suite.send("eth_getTransactionByHash", "0x1")
+	suite.send("eth_getTransactionByHash", "0x1")
+	suite.send("eth_getTransactionReceipt", "0x1")
+	suite.send("eth_getTransactionReceipt", "0x1")
+	assert.Equal(suite.T(), 2, suite.inner.count("eth_getTransactionByHash"), "Should be equal")
+	assert.Equal(suite.T(), 2, suite.inner.count("eth_getTransactionReceipt"), "Should be equal")
+
+	// Errors are passed through.
+	resp := suite.send("eth_getUncleCountByBlockHash", "0x1")
+	assert.Error(suite.T(), resp.Error(), "Should be error")
+	suite.send("eth_getUncleCountByBlockHash", "0x1")
+	assert.Equal(suite.T(), 2, suite.inner.count("eth_getUncleCountByBlockHash"), "Should be equal")
+}
+
+func (suite *CacheProviderTestSuite) Test_MinedTransaction() {
+	suite.send("eth_blockNumber")
+	suite.inner.results["eth_getTransactionByHash"] = `{"hash":"0x1","blockHash":"0xabcd","blockNumber":"0x10"}`
+	suite.send("eth_getTransactionByHash", "0x1")
+	resp := suite.send("eth_getTransactionByHash", "0x1")
+	assert.Equal(suite.T(), 1, suite.inner.count("eth_getTransactionByHash"), "Should be equal")
+
+	data, _ := json.Marshal(resp.Get("result"))
+	assert.JSONEq(suite.T(), suite.inner.results["eth_getTransactionByHash"], string(data), "Should be equal")
+}
+
+func (suite *CacheProviderTestSuite) Test_Eviction() {
+	suite.provider.config.Size = 2
+	suite.send("eth_getBlockByHash", "0x1", false)
+	suite.send("eth_getBlockByHash", "0x2", false)
+	suite.send("eth_getBlockByHash", "0x1", false)
+	suite.send("eth_getBlockByHash", "0x3", false)
+
+	// 0x2 was the least recently used.
+	suite.send("eth_getBlockByHash", "0x1", false)
+	assert.Equal(suite.T(), 3, suite.inner.count("eth_getBlockByHash"), "Should be equal")
+	suite.send("eth_getBlockByHash", "0x2", false)
+	assert.Equal(suite.T(), 4, suite.inner.count("eth_getBlockByHash"), "Should be equal")
+
+	stats := suite.provider.Stats()
+	assert.EqualValues(suite.T(), 2, stats.Evictions, "Should be equal")
+	assert.EqualValues(suite.T(), 2, stats.Entries, "Should be equal")
+
+	suite.provider.Purge()
+	assert.EqualValues(suite.T(), 0, suite.provider.Stats().Entries, "Should be equal")
+}
+
+func (suite *CacheProviderTestSuite) SetupTest() {
+	suite.inner = &countingProvider{
+		results: map[string]string{
+			"eth_chainId":               `"0x1"`,
+			"eth_blockNumber":           `"0x100"`,
+			"eth_getBalance":            `"0x64"`,
+			"eth_getBlockByHash":        `{"number":"0x10","hash":"0xabcd"}`,
+			"eth_getBlockByNumber":      `{"number":"0x10","hash":"0xabcd"}`,
+			"eth_getLogs":               `[]`,
+			"eth_getTransactionByHash":  `null`,
+			"eth_getTransactionReceipt": `null`,
+		},
+		calls: make(map[string]int),
+	}
+	suite.provider = NewCacheProvider(suite.inner, DefaultCacheConfig).(*CacheProvider)
+}
+
+func Test_CacheProviderTestSuite(t *testing.T) {
+	suite.Run(t, new(CacheProviderTestSuite))
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/yangyuan6/web3go/rpc"
+)
+
+type DialTestSuite struct {
+	suite.Suite
+	server  *httptest.Server
+	request *http.Request
+}
+
+func (suite *DialTestSuite) Test_HTTP() {
+	u := strings.Replace(suite.server.URL, "http://", "http://alice:secret@", 1) + "/rpc?key=1&timeout=5s&header=X-Api-Key:%20abc&header=X-Team:core"
+	provider, err := Dial(u, DialHeader("X-Extra", "1"))
+	if !assert.NoError(suite.T(), err, "Should be no error") {
+		return
+	}
+
+	httpProvider := provider.(*HTTPProvider)
+	assert.Equal(suite.T(), suite.server.URL+"/rpc?key=1", httpProvider.host, "Should be equal")
+	assert.Equal(suite.T(), 5*time.Second, httpProvider.timeout, "Should be equal")
+
+	resp, err := provider.Send(provider.GetRPCMethod().NewRequest("eth_chainId"))
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.EqualValues(suite.T(), "0x1", resp.Get("result"), "Should be equal")
+
+	username, password, ok := suite.request.BasicAuth()
+	assert.True(suite.T(), ok, "Should use basic auth")
+	assert.Equal(suite.T(), "alice", username, "Should be equal")
+	assert.Equal(suite.T(), "secret", password, "Should be equal")
+	assert.Equal(suite.T(), "abc", suite.request.Header.Get("X-Api-Key"), "Should be equal")
+	assert.Equal(suite.T(), "core", suite.request.Header.Get("X-Team"), "Should be equal")
+	assert.Equal(suite.T(), "1", suite.request.Header.Get("X-Extra"), "Should be equal")
+}
+
+func (suite *DialTestSuite) Test_RPC() {
+	provider, err := Dial(suite.server.URL + "?rpc=jsonrpc1")
+	if !assert.NoError(suite.T(), err, "Should be no error") {
+		return
+	}
+
+	assert.Equal(suite.T(), suite.server.URL, provider.(*HTTPProvider).host, "Should be equal")
+	assert.Equal(suite.T(), "jsonrpc1", provider.GetRPCMethod().Name(), "Should be equal")
+	_, err = provider.Send(provider.GetRPCMethod().NewRequest("eth_chainId"))
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.Equal(suite.T(), "application/json", suite.request.Header.Get("Accept"), "Should be equal")
+}
+
+func (suite *DialTestSuite) Test_WebSocket() {
+	provider, err := Dial("wss://bob:pw@node.example.com/ws?timeout=2s&header=X-Api-Key:abc")
+	if !assert.NoError(suite.T(), err, "Should be no error") {
+		return
+	}
+
+	wsProvider := provider.(*WebSocketProvider)
+	assert.Equal(suite.T(), "wss://node.example.com/ws", wsProvider.url, "Should be equal")
+	assert.Equal(suite.T(), "abc", wsProvider.header.Get("X-Api-Key"), "Should be equal")
+	assert.True(suite.T(), strings.HasPrefix(wsProvider.header.Get("Authorization"), "Basic "), "Should use basic auth")
+	assert.Equal(suite.T(), 2*time.Second, wsProvider.dialer.HandshakeTimeout, "Should be equal")
+}
+
+func (suite *DialTestSuite) Test_IPC() {
+	provider, err := Dial("ipc:///tmp/geth.ipc")
+	if assert.NoError(suite.T(), err, "Should be no error") {
+		assert.Equal(suite.T(), "/tmp/geth.ipc", provider.(*IPCProvider).path, "Should be equal")
+	}
+
+	provider, err = Dial("ipc://geth.ipc")
+	if assert.NoError(suite.T(), err, "Should be no error") {
+		assert.Equal(suite.T(), "geth.ipc", provider.(*IPCProvider).path, "Should be equal")
+	}
+
+	provider, err = Dial("ipc:///tmp/geth.ipc?timeout=2s")
+	if assert.NoError(suite.T(), err, "Should be no error") {
+		assert.Equal(suite.T(), 2*time.Second, provider.(*IPCProvider).timeout, "Should be equal")
+	}
+
+	_, err = Dial("ipc://alice@geth.ipc")
+	assert.Error(suite.T(), err, "Should not accept credentials")
+	_, err = Dial("ipc:///tmp/geth.ipc", DialHeader("X-Api-Key", "secret"))
+	assert.Error(suite.T(), err, "Should not accept headers")
+}
+
+func (suite *DialTestSuite) Test_RegisterScheme() {
+	var config *DialConfig
+	RegisterScheme("Custom", func(u *url.URL, c *DialConfig) (Provider, error) {
+		config = c
+		return NewHTTPProvider("http://"+u.Host, c.RPC), nil
+	})
+
+	method := rpc.NewJSONRPC()
+	_, err := Dial("custom://carol@node:1234?timeout=1s", DialRPC(method), DialTimeout(3*time.Second))
+	if assert.NoError(suite.T(), err, "Should be no error") {
+		assert.Equal(suite.T(), method, config.RPC, "Should be equal")
+		assert.Equal(suite.T(), "carol", config.User.Username(), "Should be equal")
+		assert.Equal(suite.T(), 3*time.Second, config.Timeout, "Should be equal")
+	}
+}
+
+func (suite *DialTestSuite) Test_Errors() {
+	_, err := Dial("ftp://node.example.com")
+	assert.EqualError(suite.T(), err, `Unsupported scheme "ftp"`, "Should be equal")
+
+	_, err = Dial("http://node.example.com?timeout=soon")
+	assert.EqualError(suite.T(), err, `Invalid timeout "soon"`, "Should be equal")
+
+	_, err = Dial("http://node.example.com?rpc=cbor")
+	assert.EqualError(suite.T(), err, `Unsupported rpc "cbor"`, "Should be equal")
+
+	_, err = Dial("http://node.example.com?header=X-Api-Key")
+	assert.EqualError(suite.T(), err, `Invalid header "X-Api-Key"`, "Should be equal")
+
+	_, err = Dial("ipc://")
+	assert.Error(suite.T(), err, "Should be error")
+}
+
+func (suite *DialTestSuite) SetupTest() {
+	suite.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.request = r
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x1"}`)
+	}))
+}
+
+func (suite *DialTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func Test_DialTestSuite(t *testing.T) {
+	suite.Run(t, new(DialTestSuite))
+}
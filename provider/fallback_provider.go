@@ -0,0 +1,424 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yangyuan6/web3go/rpc"
+)
+
+// ErrNoProvider is returned by a FallbackProvider without endpoints.
+var ErrNoProvider = errors.New("No provider available")
+
+// FallbackEndpoint is a provider taking part in a FallbackProvider.
+type FallbackEndpoint struct {
+	Provider Provider
+	// Priority orders endpoints: requests go to the healthy endpoints with
+	// the lowest value, the others are fallbacks.
+	Priority int
+	// Weight spreads requests among healthy endpoints of the same priority.
+	// Zero counts as one.
+	Weight int
+}
+
+// FallbackConfig tunes the health checks of a FallbackProvider.
+type FallbackConfig struct {
+	// ProbeInterval is how often all endpoints, including demoted ones, are
+	// probed. Zero disables probing, so demoted endpoints only come back
+	// when every other endpoint fails too.
+	ProbeInterval time.Duration
+	// ProbeTimeout bounds each probe.
+	ProbeTimeout time.Duration
+	// MaxBlockLag demotes endpoints whose eth_blockNumber trails the highest
+	// one seen by more than this many blocks. Zero disables the check.
+	MaxBlockLag uint64
+	// FailoverNonIdempotent allows sending methods such as
+	// eth_sendRawTransaction to another endpoint after a transport error,
+	// although the first one may have got them, see IsIdempotent.
+	FailoverNonIdempotent bool
+}
+
+// DefaultFallbackConfig is used by NewFallbackProvider.
+var DefaultFallbackConfig = FallbackConfig{
+	ProbeInterval: 15 * time.Second,
+	ProbeTimeout:  5 * time.Second,
+	MaxBlockLag:   5,
+}
+
+// FallbackProvider sends each request to the preferred healthy endpoint and
+// fails over to the next one on transport errors. Requests that are not
+// idempotent only fail over with FailoverNonIdempotent. Errors returned by the
+// node itself are passed through, since another node would answer the same.
+// Endpoints are demoted when they fail, report not being connected or lag
+// behind, and promoted again once a probe finds them healthy.
+//
+// Subscriptions stay on the endpoint that accepted them; they are not moved
+// when it fails.
+//
+// A FallbackProvider with probing enabled runs a goroutine until Close is
+// called.
+type FallbackProvider struct {
+	endpoints []*fallbackEndpoint
+	config    FallbackConfig
+
+	mu            sync.Mutex
+	random        *rand.Rand
+	subscriptions map[string]*fallbackEndpoint
+	stopCh        chan struct{}
+	once          sync.Once
+}
+
+type fallbackEndpoint struct {
+	FallbackEndpoint
+	healthy     bool
+	blockNumber uint64
+}
+
+// NewFallbackProvider creates a fallback provider that prefers providers in
+// the given order.
+func NewFallbackProvider(providers ...Provider) Provider {
+	endpoints := make([]FallbackEndpoint, 0, len(providers))
+	for i, p := range providers {
+		endpoints = append(endpoints, FallbackEndpoint{Provider: p, Priority: i})
+	}
+	return NewFallbackProviderWithConfig(DefaultFallbackConfig, endpoints...)
+}
+
+// NewFallbackProviderWithConfig creates a fallback provider with explicit
+// priorities, weights and health checks.
+func NewFallbackProviderWithConfig(config FallbackConfig, endpoints ...FallbackEndpoint) Provider {
+	provider := &FallbackProvider{
+		config:        config,
+		random:        rand.New(rand.NewSource(time.Now().UnixNano())),
+		subscriptions: make(map[string]*fallbackEndpoint),
+		stopCh:        make(chan struct{}),
+	}
+	for _, e := range endpoints {
+		if e.Weight <= 0 {
+			e.Weight = 1
+		}
+		provider.endpoints = append(provider.endpoints, &fallbackEndpoint{FallbackEndpoint: e, healthy: true})
+	}
+	sort.SliceStable(provider.endpoints, func(i, j int) bool {
+		return provider.endpoints[i].Priority < provider.endpoints[j].Priority
+	})
+
+	if config.ProbeInterval > 0 {
+		go provider.probeLoop()
+	}
+	return provider
+}
+
+// IsConnected reports whether any endpoint is connected.
+func (provider *FallbackProvider) IsConnected() bool {
+	for _, e := range provider.endpoints {
+		if e.Provider.IsConnected() {
+			return true
+		}
+	}
+	return false
+}
+
+// Send sends the request to the preferred healthy endpoint.
+func (provider *FallbackProvider) Send(request rpc.Request) (rpc.Response, error) {
+	return provider.SendContext(context.Background(), request)
+}
+
+// SendContext is like Send but takes a context.
+func (provider *FallbackProvider) SendContext(ctx context.Context, request rpc.Request) (rpc.Response, error) {
+	var resp rpc.Response
+	err := provider.failover(ctx, provider.canFailover(request), func(e *fallbackEndpoint) (err error) {
+		resp, err = e.Provider.SendContext(ctx, request)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// SendBatch sends the requests to the preferred healthy endpoint, in one round
+// trip if it supports it.
+func (provider *FallbackProvider) SendBatch(requests []rpc.Request) ([]rpc.Response, error) {
+	return provider.SendBatchContext(context.Background(), requests)
+}
+
+// SendBatchContext is like SendBatch but takes a context.
+func (provider *FallbackProvider) SendBatchContext(ctx context.Context, requests []rpc.Request) ([]rpc.Response, error) {
+	var responses []rpc.Response
+	err := provider.failover(ctx, provider.canFailover(requests...), func(e *fallbackEndpoint) (err error) {
+		responses, err = SendBatch(ctx, e.Provider, requests)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return responses, nil
+}
+
+// Subscribe subscribes through the preferred healthy endpoint that supports
+// subscriptions.
+func (provider *FallbackProvider) Subscribe(ctx context.Context, request rpc.Request, handler NotificationHandler) (rpc.Response, error) {
+	var resp rpc.Response
+	var subscriber *fallbackEndpoint
+	err := provider.failover(ctx, provider.canFailover(request), func(e *fallbackEndpoint) (err error) {
+		if _, ok := e.Provider.(Subscriber); !ok {
+			return ErrSubscriptionNotSupported
+		}
+		resp, err = Subscribe(ctx, e.Provider, request, handler)
+		subscriber = e
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if id, ok := resp.Get("result").(string); ok && resp.Error() == nil {
+		provider.mu.Lock()
+		provider.subscriptions[id] = subscriber
+		provider.mu.Unlock()
+	}
+	return resp, nil
+}
+
+// Unsubscribe stops the subscription on the endpoint that accepted it.
+func (provider *FallbackProvider) Unsubscribe(subscriptionID string) {
+	provider.mu.Lock()
+	e, ok := provider.subscriptions[subscriptionID]
+	delete(provider.subscriptions, subscriptionID)
+	provider.mu.Unlock()
+
+	if ok {
+		Unsubscribe(e.Provider, subscriptionID)
+	}
+}
+
+// GetRPCMethod returns the RPC of the first endpoint.
+func (provider *FallbackProvider) GetRPCMethod() rpc.RPC {
+	if len(provider.endpoints) == 0 {
+		return rpc.GetDefaultMethod()
+	}
+	return provider.endpoints[0].Provider.GetRPCMethod()
+}
+
+// Close stops probing. It does not close the endpoints.
+func (provider *FallbackProvider) Close() error {
+	provider.once.Do(func() {
+		close(provider.stopCh)
+	})
+	return nil
+}
+
+// canFailover tells whether requests may be sent to another endpoint after a
+// transport error.
+func (provider *FallbackProvider) canFailover(requests ...rpc.Request) bool {
+	if provider.config.FailoverNonIdempotent {
+		return true
+	}
+	for _, request := range requests {
+		if method, _ := request.Get("method").(string); !IsIdempotent(method) {
+			return false
+		}
+	}
+	return true
+}
+
+// failover calls send with each candidate endpoint until one succeeds,
+// demoting those that fail. Without retry, it gives up after the first
+// endpoint that fails. Endpoints failing with ErrSubscriptionNotSupported are
+// skipped without being demoted.
+func (provider *FallbackProvider) failover(ctx context.Context, retry bool, send func(e *fallbackEndpoint) error) error {
+	err := ErrNoProvider
+	for _, e := range provider.candidates() {
+		failure := send(e)
+		if failure == nil {
+			provider.setHealthy(e, true)
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if failure == ErrSubscriptionNotSupported {
+			if err == ErrNoProvider {
+				err = failure
+			}
+			continue
+		}
+		err = failure
+		provider.setHealthy(e, false)
+		if !retry {
+			break
+		}
+	}
+	return err
+}
+
+// candidates returns the endpoints in the order they should be tried: healthy
+// ones by priority, picking by weight within a priority, then the demoted
+// ones as a last resort.
+func (provider *FallbackProvider) candidates() []*fallbackEndpoint {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	var healthy, demoted []*fallbackEndpoint
+	for _, e := range provider.endpoints {
+		if e.healthy {
+			healthy = append(healthy, e)
+		} else {
+			demoted = append(demoted, e)
+		}
+	}
+
+	ordered := make([]*fallbackEndpoint, 0, len(provider.endpoints))
+	for start := 0; start < len(healthy); {
+		end := start
+		for end < len(healthy) && healthy[end].Priority == healthy[start].Priority {
+			end++
+		}
+		ordered = append(ordered, provider.shuffleByWeight(healthy[start:end])...)
+		start = end
+	}
+	return append(ordered, demoted...)
+}
+
+// shuffleByWeight orders endpoints randomly, each next one being picked with a
+// probability proportional to its weight.
+func (provider *FallbackProvider) shuffleByWeight(endpoints []*fallbackEndpoint) []*fallbackEndpoint {
+	remaining := append([]*fallbackEndpoint(nil), endpoints...)
+	ordered := make([]*fallbackEndpoint, 0, len(endpoints))
+	for len(remaining) > 0 {
+		total := 0
+		for _, e := range remaining {
+			total += e.Weight
+		}
+		pick := provider.random.Intn(total)
+		for i, e := range remaining {
+			if pick < e.Weight {
+				ordered = append(ordered, e)
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				break
+			}
+			pick -= e.Weight
+		}
+	}
+	return ordered
+}
+
+func (provider *FallbackProvider) setHealthy(e *fallbackEndpoint, healthy bool) {
+	provider.mu.Lock()
+	e.healthy = healthy
+	provider.mu.Unlock()
+}
+
+func (provider *FallbackProvider) probeLoop() {
+	ticker := time.NewTicker(provider.config.ProbeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-provider.stopCh:
+			return
+		case <-ticker.C:
+			provider.probe()
+		}
+	}
+}
+
+// probe checks every endpoint and updates which ones are healthy.
+func (provider *FallbackProvider) probe() {
+	type result struct {
+		connected   bool
+		blockNumber uint64
+	}
+	results := make([]result, len(provider.endpoints))
+
+	var wg sync.WaitGroup
+	for i, e := range provider.endpoints {
+		wg.Add(1)
+		go func(i int, e *fallbackEndpoint) {
+			defer wg.Done()
+			ctx := context.Background()
+			if provider.config.ProbeTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, provider.config.ProbeTimeout)
+				defer cancel()
+			}
+			results[i].connected = probeListening(ctx, e.Provider)
+			if results[i].connected && provider.config.MaxBlockLag > 0 {
+				results[i].blockNumber, results[i].connected = probeBlockNumber(ctx, e.Provider)
+			}
+		}(i, e)
+	}
+	wg.Wait()
+
+	var highest uint64
+	for _, r := range results {
+		if r.connected && r.blockNumber > highest {
+			highest = r.blockNumber
+		}
+	}
+
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	for i, e := range provider.endpoints {
+		e.blockNumber = results[i].blockNumber
+		e.healthy = results[i].connected
+		if provider.config.MaxBlockLag > 0 && e.blockNumber+provider.config.MaxBlockLag < highest {
+			e.healthy = false
+		}
+	}
+}
+
+// probeListening asks p whether it is listening, as IsConnected does but
+// within ctx.
+func probeListening(ctx context.Context, p Provider) bool {
+	resp, err := p.SendContext(ctx, p.GetRPCMethod().NewRequest("net_listening"))
+	if err != nil || resp.Error() != nil {
+		return false
+	}
+	result, ok := resp.Get("result").(bool)
+	return ok && result
+}
+
+// probeBlockNumber asks p for its latest block number.
+func probeBlockNumber(ctx context.Context, p Provider) (uint64, bool) {
+	resp, err := p.SendContext(ctx, p.GetRPCMethod().NewRequest("eth_blockNumber"))
+	if err != nil || resp.Error() != nil {
+		return 0, false
+	}
+	result, _ := resp.Get("result").(string)
+	return parseQuantity(result)
+}
@@ -0,0 +1,280 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/yangyuan6/web3go/rpc"
+)
+
+// fakeEndpoint answers every request with its own name, or fails when down.
+type fakeEndpoint struct {
+	name string
+	rpc  rpc.RPC
+
+	mu          sync.Mutex
+	down        bool
+	hung        bool
+	blockNumber uint64
+	calls       int
+}
+
+func newFakeEndpoint(name string) *fakeEndpoint {
+	return &fakeEndpoint{name: name, rpc: rpc.GetDefaultMethod(), blockNumber: 100}
+}
+
+func (e *fakeEndpoint) set(down bool, blockNumber uint64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.down = down
+	e.blockNumber = blockNumber
+}
+
+func (e *fakeEndpoint) callCount() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.calls
+}
+
+func (e *fakeEndpoint) IsConnected() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return !e.down
+}
+
+func (e *fakeEndpoint) Send(request rpc.Request) (rpc.Response, error) {
+	return e.SendContext(context.Background(), request)
+}
+
+func (e *fakeEndpoint) SendContext(ctx context.Context, request rpc.Request) (rpc.Response, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	e.mu.Lock()
+	hung := e.hung
+	e.mu.Unlock()
+	if hung {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.down {
+		return nil, errors.New(e.name + " is down")
+	}
+
+	result := `"` + e.name + `"`
+	switch request.Get("method") {
+	case "eth_blockNumber":
+		result = fmt.Sprintf(`"0x%x"`, e.blockNumber)
+	case "net_listening":
+		result = "true"
+	default:
+		e.calls++
+	}
+	return e.rpc.NewResponse([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":%s}`, request.ID(), result))), nil
+}
+
+func (e *fakeEndpoint) GetRPCMethod() rpc.RPC {
+	return e.rpc
+}
+
+type FallbackProviderTestSuite struct {
+	suite.Suite
+	primary   *fakeEndpoint
+	secondary *fakeEndpoint
+	provider  *FallbackProvider
+}
+
+func (suite *FallbackProviderTestSuite) send() string {
+	resp, err := suite.provider.Send(suite.provider.GetRPCMethod().NewRequest("test_method"))
+	assert.NoError(suite.T(), err, "Should be no error")
+	return resp.Get("result").(string)
+}
+
+func (suite *FallbackProviderTestSuite) Test_Priority() {
+	for i := 0; i < 5; i++ {
+		assert.Equal(suite.T(), "primary", suite.send(), "Should be equal")
+	}
+	assert.Equal(suite.T(), 0, suite.secondary.callCount(), "Should be equal")
+}
+
+func (suite *FallbackProviderTestSuite) Test_Failover() {
+	suite.primary.set(true, 100)
+	assert.Equal(suite.T(), "secondary", suite.send(), "Should be equal")
+
+	// The primary was demoted, so it is not tried again until probed.
+	suite.primary.set(false, 100)
+	assert.Equal(suite.T(), "secondary", suite.send(), "Should be equal")
+	assert.Equal(suite.T(), 0, suite.primary.callCount(), "Should be equal")
+
+	suite.provider.probe()
+	assert.Equal(suite.T(), "primary", suite.send(), "Should be equal")
+}
+
+func (suite *FallbackProviderTestSuite) Test_AllDown() {
+	suite.primary.set(true, 100)
+	suite.secondary.set(true, 100)
+	_, err := suite.provider.Send(suite.provider.GetRPCMethod().NewRequest("test_method"))
+	assert.EqualError(suite.T(), err, "secondary is down", "Should be equal")
+
+	// Demoted endpoints are still tried as a last resort.
+	suite.secondary.set(false, 100)
+	assert.Equal(suite.T(), "secondary", suite.send(), "Should be equal")
+}
+
+func (suite *FallbackProviderTestSuite) Test_NonIdempotent() {
+	suite.primary.set(true, 100)
+	_, err := suite.provider.Send(suite.provider.GetRPCMethod().NewRequest("eth_sendRawTransaction"))
+	assert.EqualError(suite.T(), err, "primary is down", "Should be equal")
+	assert.Equal(suite.T(), 0, suite.secondary.callCount(), "Should not send it twice")
+
+	// The primary was demoted, so the next transaction goes to the secondary.
+	_, err = suite.provider.Send(suite.provider.GetRPCMethod().NewRequest("eth_sendRawTransaction"))
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.Equal(suite.T(), 1, suite.secondary.callCount(), "Should be equal")
+
+	// Opting in sends it to the secondary once the primary fails.
+	suite.primary.set(false, 100)
+	suite.provider.probe()
+	suite.provider.config.FailoverNonIdempotent = true
+	suite.primary.set(true, 100)
+	_, err = suite.provider.Send(suite.provider.GetRPCMethod().NewRequest("eth_sendRawTransaction"))
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.Equal(suite.T(), 2, suite.secondary.callCount(), "Should be equal")
+}
+
+func (suite *FallbackProviderTestSuite) Test_ProbeNotConnected() {
+	suite.primary.set(true, 100)
+	suite.provider.probe()
+	assert.Equal(suite.T(), "secondary", suite.send(), "Should be equal")
+	assert.True(suite.T(), suite.provider.IsConnected(), "Should be connected")
+}
+
+func (suite *FallbackProviderTestSuite) Test_ProbeTimeout() {
+	suite.provider.config.ProbeTimeout = 20 * time.Millisecond
+	suite.primary.mu.Lock()
+	suite.primary.hung = true
+	suite.primary.mu.Unlock()
+
+	start := time.Now()
+	suite.provider.probe()
+	assert.True(suite.T(), time.Since(start) < time.Second, "Should not wait for the hung endpoint")
+
+	suite.primary.mu.Lock()
+	suite.primary.hung = false
+	suite.primary.mu.Unlock()
+	assert.Equal(suite.T(), "secondary", suite.send(), "Should be equal")
+}
+
+func (suite *FallbackProviderTestSuite) Test_ProbeBlockLag() {
+	suite.primary.set(false, 90)
+	suite.provider.probe()
+	assert.Equal(suite.T(), "secondary", suite.send(), "Should be equal")
+
+	suite.primary.set(false, 98)
+	suite.provider.probe()
+	assert.Equal(suite.T(), "primary", suite.send(), "Should be equal")
+}
+
+func (suite *FallbackProviderTestSuite) Test_ContextCanceled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := suite.provider.SendContext(ctx, suite.provider.GetRPCMethod().NewRequest("test_method"))
+	assert.Equal(suite.T(), context.Canceled, err, "Should be equal")
+
+	// A canceled request says nothing about the endpoint.
+	assert.Equal(suite.T(), "primary", suite.send(), "Should be equal")
+}
+
+func (suite *FallbackProviderTestSuite) Test_Weights() {
+	heavy, light := newFakeEndpoint("heavy"), newFakeEndpoint("light")
+	provider := NewFallbackProviderWithConfig(FallbackConfig{},
+		FallbackEndpoint{Provider: heavy, Weight: 3},
+		FallbackEndpoint{Provider: light, Weight: 1},
+	)
+
+	for i := 0; i < 400; i++ {
+		_, err := provider.Send(provider.GetRPCMethod().NewRequest("test_method"))
+		assert.NoError(suite.T(), err, "Should be no error")
+	}
+	assert.True(suite.T(), heavy.callCount() > light.callCount(), "Should prefer the heavier endpoint")
+	assert.True(suite.T(), light.callCount() > 0, "Should use the lighter endpoint too")
+}
+
+func (suite *FallbackProviderTestSuite) Test_ProbeLoop() {
+	primary, secondary := newFakeEndpoint("primary"), newFakeEndpoint("secondary")
+	provider := NewFallbackProviderWithConfig(FallbackConfig{ProbeInterval: 10 * time.Millisecond},
+		FallbackEndpoint{Provider: primary},
+		FallbackEndpoint{Provider: secondary, Priority: 1},
+	).(*FallbackProvider)
+	defer provider.Close()
+
+	primary.set(true, 100)
+	provider.Send(provider.GetRPCMethod().NewRequest("test_method"))
+	primary.set(false, 100)
+
+	promoted := false
+	for deadline := time.Now().Add(time.Second); !promoted && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+		resp, err := provider.Send(provider.GetRPCMethod().NewRequest("test_method"))
+		promoted = err == nil && resp.Get("result") == "primary"
+	}
+	assert.True(suite.T(), promoted, "Should promote the primary again")
+}
+
+func (suite *FallbackProviderTestSuite) SetupTest() {
+	suite.primary = newFakeEndpoint("primary")
+	suite.secondary = newFakeEndpoint("secondary")
+	config := DefaultFallbackConfig
+	config.ProbeInterval = 0
+	suite.provider = NewFallbackProviderWithConfig(config,
+		FallbackEndpoint{Provider: suite.primary},
+		FallbackEndpoint{Provider: suite.secondary, Priority: 1},
+	).(*FallbackProvider)
+}
+
+func (suite *FallbackProviderTestSuite) TearDownTest() {
+	suite.provider.Close()
+}
+
+func Test_FallbackProviderTestSuite(t *testing.T) {
+	suite.Run(t, new(FallbackProviderTestSuite))
+}
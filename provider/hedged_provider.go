@@ -0,0 +1,203 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/yangyuan6/web3go/rpc"
+)
+
+// HedgeConfig tunes a HedgedProvider.
+type HedgeConfig struct {
+	// Delay is how long to wait for a backend to answer before sending the
+	// request to the next one as well.
+	Delay time.Duration
+	// Methods are the methods that may be hedged. Nil means the read-only
+	// ones, see IsReadOnly.
+	Methods map[string]bool
+}
+
+// HedgedProvider cuts tail latency by sending requests to its next backend
+// when the previous ones are slow to answer. The first response wins and
+// the requests still in flight are canceled. Requests to methods that may
+// not be hedged only go to the first backend. A batch is hedged as a whole if
+// all its methods may be. Subscriptions go to the first backend.
+type HedgedProvider struct {
+	providers []Provider
+	config    HedgeConfig
+}
+
+type hedgeAnswer struct {
+	responses []rpc.Response
+	err       error
+}
+
+// NewHedgedProvider creates a hedged provider trying providers in order.
+func NewHedgedProvider(config HedgeConfig, providers ...Provider) Provider {
+	return &HedgedProvider{providers: providers, config: config}
+}
+
+// IsConnected reports whether the first backend is connected.
+func (provider *HedgedProvider) IsConnected() bool {
+	return len(provider.providers) > 0 && provider.providers[0].IsConnected()
+}
+
+// Send sends the request, hedging it if its method allows.
+func (provider *HedgedProvider) Send(request rpc.Request) (rpc.Response, error) {
+	return provider.SendContext(context.Background(), request)
+}
+
+// SendContext is like Send but takes a context.
+func (provider *HedgedProvider) SendContext(ctx context.Context, request rpc.Request) (rpc.Response, error) {
+	responses, err := provider.race(ctx, []rpc.Request{request}, func(ctx context.Context, p Provider) ([]rpc.Response, error) {
+		resp, err := p.SendContext(ctx, request)
+		return []rpc.Response{resp}, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return responses[0], nil
+}
+
+// SendBatch sends the requests in one round trip to the backends that support
+// it, hedging the batch if all its methods allow.
+func (provider *HedgedProvider) SendBatch(requests []rpc.Request) ([]rpc.Response, error) {
+	return provider.SendBatchContext(context.Background(), requests)
+}
+
+// SendBatchContext is like SendBatch but takes a context.
+func (provider *HedgedProvider) SendBatchContext(ctx context.Context, requests []rpc.Request) ([]rpc.Response, error) {
+	return provider.race(ctx, requests, func(ctx context.Context, p Provider) ([]rpc.Response, error) {
+		return SendBatch(ctx, p, requests)
+	})
+}
+
+// Subscribe subscribes through the first backend.
+func (provider *HedgedProvider) Subscribe(ctx context.Context, request rpc.Request, handler NotificationHandler) (rpc.Response, error) {
+	if len(provider.providers) == 0 {
+		return nil, ErrNoProvider
+	}
+	return Subscribe(ctx, provider.providers[0], request, handler)
+}
+
+// Unsubscribe ...
+func (provider *HedgedProvider) Unsubscribe(subscriptionID string) {
+	if len(provider.providers) > 0 {
+		Unsubscribe(provider.providers[0], subscriptionID)
+	}
+}
+
+// race sends requests with send, to the first backend only if they may not
+// be hedged, and returns the first answer.
+func (provider *HedgedProvider) race(ctx context.Context, requests []rpc.Request, send func(ctx context.Context, p Provider) ([]rpc.Response, error)) ([]rpc.Response, error) {
+	if len(provider.providers) == 0 {
+		return nil, ErrNoProvider
+	}
+	for _, request := range requests {
+		method, _ := request.Get("method").(string)
+		if !provider.hedged(method) {
+			return send(ctx, provider.providers[0])
+		}
+	}
+
+	hedgeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	answers := make(chan hedgeAnswer, len(provider.providers))
+	launched := 0
+	launch := func() {
+		p := provider.providers[launched]
+		launched++
+		go func() {
+			responses, err := send(hedgeCtx, p)
+			answers <- hedgeAnswer{responses, err}
+		}()
+	}
+
+	launch()
+	timer := time.NewTimer(provider.config.Delay)
+	defer timer.Stop()
+
+	var err error
+	for failed := 0; failed < len(provider.providers); {
+		select {
+		case answer := <-answers:
+			if answer.err == nil {
+				return answer.responses, nil
+			}
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			err = answer.err
+			failed++
+			// Nothing left in flight, so hedge right away.
+			if failed == launched && launched < len(provider.providers) {
+				launch()
+				resetTimer(timer, provider.config.Delay)
+			}
+		case <-timer.C:
+			if launched < len(provider.providers) {
+				launch()
+				timer.Reset(provider.config.Delay)
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return nil, err
+}
+
+// GetRPCMethod returns the RPC of the first backend.
+func (provider *HedgedProvider) GetRPCMethod() rpc.RPC {
+	if len(provider.providers) == 0 {
+		return rpc.GetDefaultMethod()
+	}
+	return provider.providers[0].GetRPCMethod()
+}
+
+func (provider *HedgedProvider) hedged(method string) bool {
+	if provider.config.Methods != nil {
+		return provider.config.Methods[method]
+	}
+	return IsReadOnly(method)
+}
+
+// resetTimer resets a timer that may have fired without being received from.
+func resetTimer(timer *time.Timer, d time.Duration) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+	timer.Reset(d)
+}
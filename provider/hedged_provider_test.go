@@ -0,0 +1,137 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/yangyuan6/web3go/rpc"
+)
+
+// stallingProvider never answers, and tells when its request is canceled.
+type stallingProvider struct {
+	scriptedProvider
+	canceled chan struct{}
+}
+
+func (p *stallingProvider) SendContext(ctx context.Context, request rpc.Request) (rpc.Response, error) {
+	p.mu.Lock()
+	p.attempts++
+	p.mu.Unlock()
+
+	<-ctx.Done()
+	close(p.canceled)
+	return nil, ctx.Err()
+}
+
+type HedgedProviderTestSuite struct {
+	suite.Suite
+	slow *stallingProvider
+	fast *countingProvider
+}
+
+func (suite *HedgedProviderTestSuite) send(provider Provider, method string) (rpc.Response, error) {
+	return provider.Send(provider.GetRPCMethod().NewRequest(method))
+}
+
+func (suite *HedgedProviderTestSuite) Test_Hedge() {
+	provider := NewHedgedProvider(HedgeConfig{Delay: 20 * time.Millisecond}, suite.slow, suite.fast)
+
+	start := time.Now()
+	resp, err := suite.send(provider, "eth_blockNumber")
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.EqualValues(suite.T(), "0x100", resp.Get("result"), "Should be equal")
+	assert.True(suite.T(), time.Since(start) >= 20*time.Millisecond, "Should wait before hedging")
+
+	select {
+	case <-suite.slow.canceled:
+	case <-time.After(time.Second):
+		suite.T().Error("Should cancel the slow request")
+	}
+}
+
+func (suite *HedgedProviderTestSuite) Test_NoHedgeNeeded() {
+	provider := NewHedgedProvider(HedgeConfig{Delay: 20 * time.Millisecond}, suite.fast, suite.slow)
+	_, err := suite.send(provider, "eth_blockNumber")
+	assert.NoError(suite.T(), err, "Should be no error")
+
+	time.Sleep(40 * time.Millisecond)
+	assert.Equal(suite.T(), 0, suite.slow.attemptCount(), "Should be equal")
+}
+
+func (suite *HedgedProviderTestSuite) Test_NotReadOnly() {
+	provider := NewHedgedProvider(HedgeConfig{Delay: time.Millisecond}, suite.slow, suite.fast)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	_, err := provider.SendContext(ctx, provider.GetRPCMethod().NewRequest("eth_sendRawTransaction"))
+	assert.Equal(suite.T(), context.DeadlineExceeded, err, "Should be equal")
+	assert.Equal(suite.T(), 0, suite.fast.count("eth_sendRawTransaction"), "Should be equal")
+}
+
+func (suite *HedgedProviderTestSuite) Test_Methods() {
+	provider := NewHedgedProvider(HedgeConfig{
+		Delay:   time.Millisecond,
+		Methods: map[string]bool{"eth_sendRawTransaction": true},
+	}, suite.slow, suite.fast)
+
+	resp, err := suite.send(provider, "eth_sendRawTransaction")
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.EqualError(suite.T(), resp.Error(), "method not found", "Should get the answer of the fast backend")
+}
+
+func (suite *HedgedProviderTestSuite) Test_Failure() {
+	failing := &countingProvider{failures: map[string]error{"eth_blockNumber": errors.New("connection refused")}, calls: make(map[string]int)}
+
+	// A failure hedges right away.
+	provider := NewHedgedProvider(HedgeConfig{Delay: time.Hour}, failing, suite.fast)
+	resp, err := suite.send(provider, "eth_blockNumber")
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.EqualValues(suite.T(), "0x100", resp.Get("result"), "Should be equal")
+
+	provider = NewHedgedProvider(HedgeConfig{Delay: time.Hour}, failing, failing)
+	_, err = suite.send(provider, "eth_blockNumber")
+	assert.EqualError(suite.T(), err, "connection refused", "Should be equal")
+	assert.Equal(suite.T(), 3, failing.count("eth_blockNumber"), "Should be equal")
+}
+
+func (suite *HedgedProviderTestSuite) SetupTest() {
+	suite.slow = &stallingProvider{canceled: make(chan struct{})}
+	suite.fast = &countingProvider{results: map[string]string{"eth_blockNumber": `"0x100"`}, calls: make(map[string]int)}
+}
+
+func Test_HedgedProviderTestSuite(t *testing.T) {
+	suite.Run(t, new(HedgedProviderTestSuite))
+}
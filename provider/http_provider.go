@@ -30,29 +30,125 @@
 package provider
 
 import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/yangyuan6/web3go/rpc"
 )
 
 // HTTPProvider provides basic web3 interface
 type HTTPProvider struct {
-	host string
-	rpc  rpc.RPC
+	host      string
+	rpc       rpc.RPC
+	client    *http.Client
+	header    http.Header
+	timeout   time.Duration
+	tlsConfig *tls.Config
 }
 
-// NewHTTPProvider creates a HTTP provider
-func NewHTTPProvider(host string, method rpc.RPC) Provider {
-	if !strings.HasPrefix(host, "http://") {
+// HTTPOption configures an HTTPProvider
+type HTTPOption func(*HTTPProvider)
+
+// HTTPError is returned when the node answers with a non-2xx status code.
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Body       []byte
+}
+
+func (err *HTTPError) Error() string {
+	if len(err.Body) == 0 {
+		return err.Status
+	}
+	return fmt.Sprintf("%s: %s", err.Status, string(err.Body))
+}
+
+// NewHTTPProvider creates a HTTP provider. Hosts without a scheme are reached
+// over plain http.
+func NewHTTPProvider(host string, method rpc.RPC, options ...HTTPOption) Provider {
+	if !strings.HasPrefix(host, "http://") && !strings.HasPrefix(host, "https://") {
 		host = "http://" + host
 	}
 	if method == nil {
 		method = rpc.GetDefaultMethod()
 	}
-	return &HTTPProvider{host: host, rpc: method}
+
+	provider := &HTTPProvider{host: host, rpc: method, client: http.DefaultClient, header: make(http.Header)}
+	for _, option := range options {
+		option(provider)
+	}
+	if provider.tlsConfig != nil {
+		provider.client = withTLSConfig(provider.client, provider.tlsConfig)
+	}
+	return provider
+}
+
+// WithHTTPClient sends requests through client instead of http.DefaultClient.
+func WithHTTPClient(client *http.Client) HTTPOption {
+	return func(provider *HTTPProvider) {
+		provider.client = client
+	}
+}
+
+// WithHeader adds a header to every request, e.g. an API key.
+func WithHeader(key, value string) HTTPOption {
+	return func(provider *HTTPProvider) {
+		provider.header.Add(key, value)
+	}
+}
+
+// WithBasicAuth authenticates every request with HTTP basic authentication.
+func WithBasicAuth(username, password string) HTTPOption {
+	return func(provider *HTTPProvider) {
+		auth := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+		provider.header.Set("Authorization", "Basic "+auth)
+	}
+}
+
+// WithBearerToken authenticates every request with a bearer token.
+func WithBearerToken(token string) HTTPOption {
+	return func(provider *HTTPProvider) {
+		provider.header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// WithTimeout bounds every request, including reading the response body. It
+// applies on top of any deadline of the context passed to SendContext.
+func WithTimeout(timeout time.Duration) HTTPOption {
+	return func(provider *HTTPProvider) {
+		provider.timeout = timeout
+	}
+}
+
+// WithTLSConfig uses config for https connections, e.g. to trust a private CA
+// or to present a client certificate for mutual TLS.
+func WithTLSConfig(config *tls.Config) HTTPOption {
+	return func(provider *HTTPProvider) {
+		provider.tlsConfig = config
+	}
+}
+
+// withTLSConfig returns a copy of client whose transport uses config. The
+// client passed in is left untouched since it may be shared.
+func withTLSConfig(client *http.Client, config *tls.Config) *http.Client {
+	var transport *http.Transport
+	if t, ok := client.Transport.(*http.Transport); ok {
+		transport = t.Clone()
+	} else {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	transport.TLSClientConfig = config
+
+	c := *client
+	c.Transport = transport
+	return &c
 }
 
 // IsConnected ...
@@ -62,20 +158,19 @@ func (provider *HTTPProvider) IsConnected() bool {
 	if err != nil {
 		return false
 	}
-	return resp.Get("result").(bool)
+	result, ok := resp.Get("result").(bool)
+	return ok && result
 }
 
 // Send JSON RPC request through http client
 func (provider *HTTPProvider) Send(request rpc.Request) (response rpc.Response, err error) {
-	contentType := provider.determineContentType()
-	resp, err := http.Post(provider.host, contentType, strings.NewReader(request.String()))
-	if err != nil {
-		return nil, err
-	}
+	return provider.SendContext(context.Background(), request)
+}
 
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
+// SendContext is like Send but aborts the HTTP request when ctx is done.
+func (provider *HTTPProvider) SendContext(ctx context.Context, request rpc.Request) (response rpc.Response, err error) {
+	body, err := provider.post(ctx, request.String())
+	if err != nil || request.IsNotification() {
 		return nil, err
 	}
 
@@ -86,15 +181,77 @@ func (provider *HTTPProvider) Send(request rpc.Request) (response rpc.Response,
 	return response, err
 }
 
+// SendBatch sends the requests as one JSON RPC batch
+func (provider *HTTPProvider) SendBatch(requests []rpc.Request) ([]rpc.Response, error) {
+	return provider.SendBatchContext(context.Background(), requests)
+}
+
+// SendBatchContext is like SendBatch but takes a context.
+func (provider *HTTPProvider) SendBatchContext(ctx context.Context, requests []rpc.Request) ([]rpc.Response, error) {
+	if len(requests) == 0 {
+		return nil, nil
+	}
+
+	batch := rpc.Batch(requests)
+	body, err := provider.post(ctx, batch.String())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(strings.TrimSpace(string(body))) == 0 {
+		// Nothing is sent back for a batch of notifications.
+		return batch.Match(nil)
+	}
+	responses := provider.rpc.NewBatchResponse(body)
+	if responses == nil {
+		// The node rejected the batch as a whole.
+		if response := provider.rpc.NewResponse(body); response != nil && response.Error() != nil {
+			return nil, response.Error()
+		}
+		return nil, fmt.Errorf("Malformed response body, %s", string(body))
+	}
+	return batch.Match(responses)
+}
+
 func (provider *HTTPProvider) GetRPCMethod() rpc.RPC {
 	return provider.rpc
 }
 
-func (provider *HTTPProvider) determineContentType() string {
-	switch provider.rpc.Name() {
-	case "jsonrpc":
-		return "application/json"
-	default:
-		return "application/json"
+func (provider *HTTPProvider) post(ctx context.Context, message string) ([]byte, error) {
+	if provider.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, provider.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequest("POST", provider.host, strings.NewReader(message))
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range provider.header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", provider.rpc.ContentType())
+	req.Header.Set("Accept", provider.rpc.ContentType())
+
+	resp, err := provider.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &HTTPError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Header:     resp.Header,
+			Body:       body,
+		}
 	}
+	return body, nil
 }
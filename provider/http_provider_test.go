@@ -30,10 +30,12 @@
 package provider
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/yangyuan6/web3go/rpc"
 	"github.com/stretchr/testify/assert"
@@ -44,6 +46,7 @@ type HTTPProviderTestSuite struct {
 	suite.Suite
 	server   *httptest.Server
 	provider Provider
+	notified chan string
 }
 
 func (suite *HTTPProviderTestSuite) Test_IsConnected() {
@@ -51,13 +54,23 @@ func (suite *HTTPProviderTestSuite) Test_IsConnected() {
 	assert.EqualValues(suite.T(), true, provider.IsConnected(), "should be equal")
 }
 
+func (suite *HTTPProviderTestSuite) Test_IsConnectedError() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"The method net_listening does not exist"}}`))
+	}))
+	defer server.Close()
+
+	provider := NewHTTPProvider(server.URL, nil)
+	assert.False(suite.T(), provider.IsConnected(), "should not be connected")
+}
+
 func (suite *HTTPProviderTestSuite) Test_Send() {
 	provider := suite.provider
 	req := &rpc.JSONRPCRequest{
 		Version:    "2.0",
 		Method:     "test_method",
 		Params:     nil,
-		Identifier: 10}
+		Identifier: rpc.NumberID(10)}
 	resp, err := provider.Send(req)
 
 	assert.NoError(suite.T(), err, "Should be no error")
@@ -71,23 +84,156 @@ func (suite *HTTPProviderTestSuite) Test_GetRPCMethod() {
 	assert.NotNil(suite.T(), provider.GetRPCMethod(), "should be equal")
 }
 
+func (suite *HTTPProviderTestSuite) Test_SendContext() {
+	provider := suite.provider
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := provider.SendContext(ctx, provider.GetRPCMethod().NewRequest("test_slow"))
+	assert.Error(suite.T(), err, "Should be an error")
+	assert.Equal(suite.T(), context.DeadlineExceeded, ctx.Err(), "should be equal")
+}
+
+func (suite *HTTPProviderTestSuite) Test_Options() {
+	provider := NewHTTPProvider(suite.server.URL, nil,
+		WithHeader("X-Api-Key", "secret"),
+		WithBearerToken("token"))
+	resp, err := provider.Send(provider.GetRPCMethod().NewRequest("test_header"))
+	if assert.NoError(suite.T(), err, "Should be no error") {
+		assert.EqualValues(suite.T(), "Bearer token|secret", resp.Get("result"), "should be equal")
+	}
+
+	provider = NewHTTPProvider(suite.server.URL, nil, WithBasicAuth("user", "pass"))
+	resp, err = provider.Send(provider.GetRPCMethod().NewRequest("test_header"))
+	if assert.NoError(suite.T(), err, "Should be no error") {
+		assert.EqualValues(suite.T(), "Basic dXNlcjpwYXNz|", resp.Get("result"), "should be equal")
+	}
+
+	provider = NewHTTPProvider(suite.server.URL, nil, WithTimeout(10*time.Millisecond))
+	_, err = provider.Send(provider.GetRPCMethod().NewRequest("test_slow"))
+	assert.Error(suite.T(), err, "Should be an error")
+}
+
+func (suite *HTTPProviderTestSuite) Test_HTTPError() {
+	provider := NewHTTPProvider(suite.server.URL+"/unavailable", nil)
+	_, err := provider.Send(provider.GetRPCMethod().NewRequest("test_method"))
+	if httpErr, ok := err.(*HTTPError); assert.True(suite.T(), ok, "should be an HTTPError") {
+		assert.EqualValues(suite.T(), http.StatusServiceUnavailable, httpErr.StatusCode, "should be equal")
+		assert.EqualValues(suite.T(), "1", httpErr.Header.Get("Retry-After"), "should be equal")
+	}
+}
+
+func (suite *HTTPProviderTestSuite) Test_HTTPS() {
+	server := httptest.NewTLSServer(suite.server.Config.Handler)
+	defer server.Close()
+
+	provider := NewHTTPProvider(server.URL, nil, WithHTTPClient(server.Client()))
+	assert.True(suite.T(), provider.IsConnected(), "should be connected")
+
+	// Without trusting the test certificate the handshake fails.
+	provider = NewHTTPProvider(server.URL, nil)
+	assert.False(suite.T(), provider.IsConnected(), "should not be connected")
+}
+
+func (suite *HTTPProviderTestSuite) Test_SendBatch() {
+	provider := suite.provider.(BatchSender)
+	method := suite.provider.GetRPCMethod()
+	requests := []rpc.Request{
+		method.NewRequest("test_method"),
+		method.NewRequest("test_error"),
+		method.NewRequest("net_listening"),
+	}
+	responses, err := provider.SendBatch(requests)
+
+	if assert.NoError(suite.T(), err, "Should be no error") && assert.Len(suite.T(), responses, 3) {
+		for i, resp := range responses {
+			assert.EqualValues(suite.T(), requests[i].ID(), resp.ID(), "should be equal")
+		}
+		assert.EqualValues(suite.T(), "ok", responses[0].Get("result"), "should be equal")
+		assert.Error(suite.T(), responses[1].Error(), "Should be an error")
+		assert.EqualValues(suite.T(), true, responses[2].Get("result"), "should be equal")
+	}
+}
+
+func (suite *HTTPProviderTestSuite) Test_Notification() {
+	method := suite.provider.GetRPCMethod()
+	resp, err := suite.provider.Send(method.NewNotificationRequest("test_notify", "arg1"))
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.Nil(suite.T(), resp, "Should not be answered")
+	assert.EqualValues(suite.T(), "test_notify", <-suite.notified, "should be equal")
+
+	requests := []rpc.Request{
+		method.NewRequest("test_method"),
+		method.NewNotificationRequest("test_notify"),
+	}
+	responses, err := suite.provider.(BatchSender).SendBatch(requests)
+	if assert.NoError(suite.T(), err, "Should be no error") && assert.Len(suite.T(), responses, 2) {
+		assert.EqualValues(suite.T(), "ok", responses[0].Get("result"), "should be equal")
+		assert.Nil(suite.T(), responses[1], "Should not be answered")
+	}
+	assert.EqualValues(suite.T(), "test_notify", <-suite.notified, "should be equal")
+
+	responses, err = suite.provider.(BatchSender).SendBatch(requests[1:])
+	if assert.NoError(suite.T(), err, "Should be no error") {
+		assert.EqualValues(suite.T(), []rpc.Response{nil}, responses, "should be equal")
+	}
+}
+
+func (suite *HTTPProviderTestSuite) Test_StringID() {
+	req := &rpc.JSONRPCRequest{Version: "2.0", Method: "test_method", Params: []interface{}{}}
+	req.Set("id", "abc-1")
+	assert.EqualValues(suite.T(), `{"jsonrpc":"2.0","method":"test_method","params":[],"id":"abc-1"}`, req.String(), "should be equal")
+
+	resp, err := suite.provider.Send(req)
+	if assert.NoError(suite.T(), err, "Should be no error") {
+		assert.EqualValues(suite.T(), rpc.StringID("abc-1"), resp.ID(), "should be equal")
+		assert.EqualValues(suite.T(), "ok", resp.Get("result"), "should be equal")
+	}
+}
+
 func (suite *HTTPProviderTestSuite) SetupTest() {
+	suite.notified = make(chan string, 10)
 	suite.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		decoder := json.NewDecoder(r.Body)
+		if r.URL.Path == "/unavailable" {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "try again later", http.StatusServiceUnavailable)
+			return
+		}
+
+		var raw json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&raw); err == nil && len(raw) > 0 && raw[0] == '[' {
+			// Answer batches in reverse order, as nodes are free to.
+			reqs := []rpc.JSONRPCRequest{}
+			json.Unmarshal(raw, &reqs)
+			resps := []rpc.JSONRPCResponse{}
+			for i := len(reqs) - 1; i >= 0; i-- {
+				if reqs[i].IsNotification() {
+					suite.notified <- reqs[i].Method
+					continue
+				}
+				resps = append(resps, handleTestRequest(reqs[i]))
+			}
+			if len(resps) > 0 {
+				jsonBlob, _ := json.Marshal(resps)
+				w.Write(jsonBlob)
+			}
+			return
+		}
+
 		req := rpc.JSONRPCRequest{}
 		resp := rpc.JSONRPCResponse{Version: "2.0"}
-		err := decoder.Decode(&req)
+		err := json.Unmarshal(raw, &req)
 		if err != nil {
-			resp.Identifier = 0
-			resp.Result = "error"
-		} else {
+			resp.Identifier = rpc.NullID
+			resp.Result = rawResult("error")
+		} else if req.IsNotification() {
+			suite.notified <- req.Method
+			return
+		} else if req.Method == "test_header" {
 			resp.Identifier = req.Identifier
-			switch req.Method {
-			case "net_listening":
-				resp.Result = true
-			default:
-				resp.Result = "ok"
-			}
+			resp.Result = rawResult(r.Header.Get("Authorization") + "|" + r.Header.Get("X-Api-Key"))
+		} else {
+			resp = handleTestRequest(req)
 		}
 		jsonBlob, _ := json.Marshal(resp)
 		w.Write(jsonBlob)
@@ -95,6 +241,22 @@ func (suite *HTTPProviderTestSuite) SetupTest() {
 	suite.provider = NewHTTPProvider(suite.server.URL, rpc.GetDefaultMethod())
 }
 
+func handleTestRequest(req rpc.JSONRPCRequest) rpc.JSONRPCResponse {
+	resp := rpc.JSONRPCResponse{Version: "2.0", Identifier: req.Identifier}
+	switch req.Method {
+	case "net_listening":
+		resp.Result = rawResult(true)
+	case "test_slow":
+		time.Sleep(100 * time.Millisecond)
+		resp.Result = rawResult("ok")
+	case "test_error":
+		resp.Err = &rpc.JSONRPCError{Code: -32000, Message: "test error"}
+	default:
+		resp.Result = rawResult("ok")
+	}
+	return resp
+}
+
 func (suite *HTTPProviderTestSuite) TearDownTest() {
 	suite.server.Close()
 }
@@ -102,3 +264,9 @@ func (suite *HTTPProviderTestSuite) TearDownTest() {
 func Test_HTTPProviderTestSuite(t *testing.T) {
 	suite.Run(t, new(HTTPProviderTestSuite))
 }
+
+// rawResult encodes the result of a test response.
+func rawResult(result interface{}) json.RawMessage {
+	data, _ := json.Marshal(result)
+	return data
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package provider
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"time"
+
+	"github.com/yangyuan6/web3go/rpc"
+)
+
+// IPCProvider talks to a local node through its Unix domain socket (e.g.
+// geth.ipc), multiplexing concurrent requests over one connection.
+type IPCProvider struct {
+	streamProvider
+	path string
+	// timeout bounds connecting, if not zero.
+	timeout time.Duration
+}
+
+// ipcConn frames messages as a stream of JSON values. Requests are written
+// newline delimited; responses are split by decoding, since nodes do not
+// always terminate them with a newline.
+type ipcConn struct {
+	net.Conn
+	decoder *json.Decoder
+}
+
+// NewIPCProvider creates an IPC provider
+func NewIPCProvider(path string, method rpc.RPC) Provider {
+	if method == nil {
+		method = rpc.GetDefaultMethod()
+	}
+	provider := &IPCProvider{path: path}
+	provider.rpc = method
+	provider.dial = provider.dialIPC
+	return provider
+}
+
+func (provider *IPCProvider) dialIPC(ctx context.Context) (messageConn, error) {
+	dialer := net.Dialer{Timeout: provider.timeout}
+	conn, err := dialer.DialContext(ctx, "unix", provider.path)
+	if err != nil {
+		return nil, err
+	}
+	return &ipcConn{Conn: conn, decoder: json.NewDecoder(bufio.NewReader(conn))}, nil
+}
+
+func (c *ipcConn) ReadMessage() ([]byte, error) {
+	var msg json.RawMessage
+	if err := c.decoder.Decode(&msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func (c *ipcConn) WriteMessage(data []byte) error {
+	_, err := c.Conn.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package provider
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/yangyuan6/web3go/rpc"
+)
+
+type IPCProviderTestSuite struct {
+	suite.Suite
+	dir      string
+	listener net.Listener
+	provider Provider
+}
+
+func (suite *IPCProviderTestSuite) Test_IsConnected() {
+	provider := suite.provider
+	assert.EqualValues(suite.T(), true, provider.IsConnected(), "should be equal")
+}
+
+func (suite *IPCProviderTestSuite) Test_Send() {
+	provider := suite.provider
+	req := &rpc.JSONRPCRequest{
+		Version:    "2.0",
+		Method:     "test_method",
+		Params:     nil,
+		Identifier: rpc.NumberID(10)}
+	resp, err := provider.Send(req)
+
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.EqualValues(suite.T(), req.Identifier, resp.ID(), "should be equal")
+	assert.EqualValues(suite.T(), "ok", resp.Get("result").(string), "should be equal")
+}
+
+func (suite *IPCProviderTestSuite) Test_SendConcurrently() {
+	provider := suite.provider
+	method := provider.GetRPCMethod()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := method.NewRequest("test_echoID")
+			resp, err := provider.Send(req)
+			if assert.NoError(suite.T(), err, "Should be no error") {
+				assert.EqualValues(suite.T(), req.ID().String(), resp.Get("result"), "should be equal")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (suite *IPCProviderTestSuite) Test_Reconnect() {
+	provider := suite.provider
+	method := provider.GetRPCMethod()
+
+	_, err := provider.Send(method.NewRequest("test_disconnect"))
+	assert.Equal(suite.T(), ErrConnectionLost, err, "should be equal")
+	assert.True(suite.T(), provider.IsConnected(), "should reconnect")
+}
+
+func (suite *IPCProviderTestSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "web3go-ipc")
+	suite.Require().NoError(err)
+	suite.dir = dir
+
+	path := filepath.Join(dir, "test.ipc")
+	suite.listener, err = net.Listen("unix", path)
+	suite.Require().NoError(err)
+
+	go func() {
+		for {
+			conn, err := suite.listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveIPCConn(conn)
+		}
+	}()
+	suite.provider = NewIPCProvider(path, rpc.GetDefaultMethod())
+}
+
+func (suite *IPCProviderTestSuite) TearDownTest() {
+	suite.provider.(*IPCProvider).Close()
+	suite.listener.Close()
+	os.RemoveAll(suite.dir)
+}
+
+// serveIPCConn answers every request on conn, writing responses back to back
+// without delimiters like some nodes do.
+func serveIPCConn(conn net.Conn) {
+	defer conn.Close()
+
+	decoder := json.NewDecoder(conn)
+	for {
+		req := rpc.JSONRPCRequest{}
+		if err := decoder.Decode(&req); err != nil {
+			return
+		}
+
+		resp := rpc.JSONRPCResponse{Version: "2.0", Identifier: req.Identifier}
+		switch req.Method {
+		case "test_disconnect":
+			return
+		case "net_listening":
+			resp.Result = rawResult(true)
+		case "test_echoID":
+			resp.Result = rawResult(req.Identifier.String())
+		default:
+			resp.Result = rawResult("ok")
+		}
+		jsonBlob, _ := json.Marshal(resp)
+		conn.Write(jsonBlob)
+	}
+}
+
+func Test_IPCProviderTestSuite(t *testing.T) {
+	suite.Run(t, new(IPCProviderTestSuite))
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package provider
+
+// nonIdempotentMethods may have an effect every time they are sent, so a
+// request that might already have reached the node must not be sent again.
+var nonIdempotentMethods = map[string]bool{
+	"eth_sendTransaction":      true,
+	"eth_sendRawTransaction":   true,
+	"eth_submitWork":           true,
+	"eth_submitHashrate":       true,
+	"personal_sendTransaction": true,
+}
+
+// IsIdempotent reports whether sending method more than once has the same
+// effect as sending it once.
+func IsIdempotent(method string) bool {
+	return !nonIdempotentMethods[method]
+}
+
+// readOnlyMethods only read chain data, the same on every node, so they can be
+// sent to several nodes at once.
+var readOnlyMethods = map[string]bool{
+	"web3_clientVersion":                      true,
+	"web3_sha3":                               true,
+	"net_version":                             true,
+	"net_listening":                           true,
+	"net_peerCount":                           true,
+	"eth_protocolVersion":                     true,
+	"eth_syncing":                             true,
+	"eth_chainId":                             true,
+	"eth_blockNumber":                         true,
+	"eth_gasPrice":                            true,
+	"eth_maxPriorityFeePerGas":                true,
+	"eth_feeHistory":                          true,
+	"eth_getBalance":                          true,
+	"eth_getStorageAt":                        true,
+	"eth_getTransactionCount":                 true,
+	"eth_getCode":                             true,
+	"eth_getProof":                            true,
+	"eth_call":                                true,
+	"eth_estimateGas":                         true,
+	"eth_getBlockByHash":                      true,
+	"eth_getBlockByNumber":                    true,
+	"eth_getBlockTransactionCountByHash":      true,
+	"eth_getBlockTransactionCountByNumber":    true,
+	"eth_getUncleCountByBlockHash":            true,
+	"eth_getUncleCountByBlockNumber":          true,
+	"eth_getTransactionByHash":                true,
+	"eth_getTransactionByBlockHashAndIndex":   true,
+	"eth_getTransactionByBlockNumberAndIndex": true,
+	"eth_getTransactionReceipt":               true,
+	"eth_getUncleByBlockHashAndIndex":         true,
+	"eth_getUncleByBlockNumberAndIndex":       true,
+	"eth_getLogs":                             true,
+}
+
+// IsReadOnly reports whether method only reads chain data. Methods depending
+// on the state of a node, such as filters, are not read-only.
+func IsReadOnly(method string) bool {
+	return readOnlyMethods[method]
+}
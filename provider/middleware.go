@@ -0,0 +1,227 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/yangyuan6/web3go/rpc"
+)
+
+// Request outcomes reported to Metrics.
+const (
+	// OutcomeOK is a request answered with a result.
+	OutcomeOK = "ok"
+	// OutcomeError is a request answered with a JSON-RPC error.
+	OutcomeError = "error"
+	// OutcomeFailure is a request that got no response, e.g. because of a
+	// transport error.
+	OutcomeFailure = "failure"
+)
+
+// Middleware wraps a provider to observe or alter the requests sent through
+// it. Batches sent through a middleware that is not a BatchSender go through
+// it one request after another.
+type Middleware func(next Provider) Provider
+
+// Chain wraps provider with middlewares, the first one being the outermost,
+// i.e. the first to see a request.
+func Chain(provider Provider, middlewares ...Middleware) Provider {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		provider = middlewares[i](provider)
+	}
+	return provider
+}
+
+// interceptor is a provider whose SendContext is replaced by send, and
+// SendBatchContext by sendBatch. Batches are sent through send one request
+// after another if sendBatch is nil. Subscriptions are passed through.
+type interceptor struct {
+	Provider
+	send      func(ctx context.Context, request rpc.Request) (rpc.Response, error)
+	sendBatch func(ctx context.Context, requests []rpc.Request) ([]rpc.Response, error)
+}
+
+func (i *interceptor) Send(request rpc.Request) (rpc.Response, error) {
+	return i.send(context.Background(), request)
+}
+
+func (i *interceptor) SendContext(ctx context.Context, request rpc.Request) (rpc.Response, error) {
+	return i.send(ctx, request)
+}
+
+func (i *interceptor) SendBatch(requests []rpc.Request) ([]rpc.Response, error) {
+	return i.SendBatchContext(context.Background(), requests)
+}
+
+func (i *interceptor) SendBatchContext(ctx context.Context, requests []rpc.Request) ([]rpc.Response, error) {
+	if i.sendBatch != nil {
+		return i.sendBatch(ctx, requests)
+	}
+	return sendEach(ctx, i.send, requests)
+}
+
+func (i *interceptor) Subscribe(ctx context.Context, request rpc.Request, handler NotificationHandler) (rpc.Response, error) {
+	return Subscribe(ctx, i.Provider, request, handler)
+}
+
+func (i *interceptor) Unsubscribe(subscriptionID string) {
+	Unsubscribe(i.Provider, subscriptionID)
+}
+
+// observe returns a middleware calling done after every request with its
+// outcome and latency. The requests of a batch share the latency of the batch.
+func observe(done func(request rpc.Request, resp rpc.Response, err error, latency time.Duration)) Middleware {
+	return func(next Provider) Provider {
+		return &interceptor{
+			Provider: next,
+			send: func(ctx context.Context, request rpc.Request) (rpc.Response, error) {
+				start := time.Now()
+				resp, err := next.SendContext(ctx, request)
+				done(request, resp, err, time.Since(start))
+				return resp, err
+			},
+			sendBatch: func(ctx context.Context, requests []rpc.Request) ([]rpc.Response, error) {
+				start := time.Now()
+				responses, err := SendBatch(ctx, next, requests)
+				latency := time.Since(start)
+				for i, request := range requests {
+					var resp rpc.Response
+					if err == nil && i < len(responses) {
+						resp = responses[i]
+					}
+					done(request, resp, err, latency)
+				}
+				return responses, err
+			},
+		}
+	}
+}
+
+// outcome classifies the result of a request.
+func outcome(resp rpc.Response, err error) (string, error) {
+	switch {
+	case err != nil:
+		return OutcomeFailure, err
+	case resp != nil && resp.Error() != nil:
+		return OutcomeError, resp.Error()
+	}
+	return OutcomeOK, nil
+}
+
+func methodOf(request rpc.Request) string {
+	method, _ := request.Get("method").(string)
+	return method
+}
+
+// Logger is what LoggingMiddleware writes to. *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// LoggingMiddleware logs every request as key=value pairs: the method, id,
+// latency, outcome and error, if any.
+func LoggingMiddleware(logger Logger) Middleware {
+	return observe(func(request rpc.Request, resp rpc.Response, err error, latency time.Duration) {
+		result, err := outcome(resp, err)
+		if err != nil {
+			logger.Printf("method=%s id=%s duration=%s outcome=%s error=%q", methodOf(request), request.ID(), latency, result, err.Error())
+		} else {
+			logger.Printf("method=%s id=%s duration=%s outcome=%s", methodOf(request), request.ID(), latency, result)
+		}
+	})
+}
+
+// Metrics receives measurements about requests, e.g. to feed Prometheus
+// counters and histograms.
+type Metrics interface {
+	// CountRequest counts a request to method with the given outcome, one of
+	// OutcomeOK, OutcomeError and OutcomeFailure.
+	CountRequest(method, outcome string)
+	// ObserveLatency records how long a request to method took.
+	ObserveLatency(method string, latency time.Duration)
+}
+
+// MetricsMiddleware reports every request to metrics.
+func MetricsMiddleware(metrics Metrics) Middleware {
+	return observe(func(request rpc.Request, resp rpc.Response, err error, latency time.Duration) {
+		result, _ := outcome(resp, err)
+		metrics.CountRequest(methodOf(request), result)
+		metrics.ObserveLatency(methodOf(request), latency)
+	})
+}
+
+// Tracer starts a span for every request, e.g. backed by OpenTelemetry.
+type Tracer interface {
+	// StartSpan starts a span named after method, child of any span in ctx.
+	// The returned context carries the new span down to the provider.
+	StartSpan(ctx context.Context, method string) (context.Context, Span)
+}
+
+// Span is the trace of a single request.
+type Span interface {
+	SetAttribute(key string, value interface{})
+	// End ends the span, which failed if err is not nil.
+	End(err error)
+}
+
+// TracingMiddleware traces every request with tracer. Spans carry the
+// rpc.method, rpc.id and rpc.outcome attributes. A batch is traced as a single
+// span named "batch", with the rpc.batch_size attribute instead of the method
+// and id; it fails only if the batch got no response.
+func TracingMiddleware(tracer Tracer) Middleware {
+	return func(next Provider) Provider {
+		return &interceptor{
+			Provider: next,
+			send: func(ctx context.Context, request rpc.Request) (rpc.Response, error) {
+				ctx, span := tracer.StartSpan(ctx, methodOf(request))
+				span.SetAttribute("rpc.method", methodOf(request))
+				span.SetAttribute("rpc.id", request.ID().String())
+
+				resp, err := next.SendContext(ctx, request)
+				result, failure := outcome(resp, err)
+				span.SetAttribute("rpc.outcome", result)
+				span.End(failure)
+				return resp, err
+			},
+			sendBatch: func(ctx context.Context, requests []rpc.Request) ([]rpc.Response, error) {
+				ctx, span := tracer.StartSpan(ctx, "batch")
+				span.SetAttribute("rpc.batch_size", len(requests))
+
+				responses, err := SendBatch(ctx, next, requests)
+				result, failure := outcome(nil, err)
+				span.SetAttribute("rpc.outcome", result)
+				span.End(failure)
+				return responses, err
+			},
+		}
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/yangyuan6/web3go/rpc"
+)
+
+type bufferLogger struct {
+	lines []string
+}
+
+func (l *bufferLogger) Printf(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+type recordingMetrics struct {
+	mu        sync.Mutex
+	counts    map[string]int
+	latencies map[string][]time.Duration
+}
+
+func (m *recordingMetrics) CountRequest(method, outcome string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[method+" "+outcome]++
+}
+
+func (m *recordingMetrics) ObserveLatency(method string, latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.latencies[method] = append(m.latencies[method], latency)
+}
+
+type recordingSpan struct {
+	name       string
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+func (s *recordingSpan) SetAttribute(key string, value interface{}) {
+	s.attributes[key] = value
+}
+
+func (s *recordingSpan) End(err error) {
+	s.err = err
+	s.ended = true
+}
+
+type spanKey struct{}
+
+type recordingTracer struct {
+	spans []*recordingSpan
+}
+
+func (t *recordingTracer) StartSpan(ctx context.Context, method string) (context.Context, Span) {
+	span := &recordingSpan{name: method, attributes: make(map[string]interface{})}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+type MiddlewareTestSuite struct {
+	suite.Suite
+	inner *countingProvider
+}
+
+func (suite *MiddlewareTestSuite) Test_Chain() {
+	var order []string
+	tag := func(name string) Middleware {
+		return func(next Provider) Provider {
+			return &interceptor{Provider: next, send: func(ctx context.Context, request rpc.Request) (rpc.Response, error) {
+				order = append(order, name)
+				return next.SendContext(ctx, request)
+			}}
+		}
+	}
+
+	provider := Chain(suite.inner, tag("outer"), tag("inner"))
+	_, err := provider.Send(provider.GetRPCMethod().NewRequest("eth_chainId"))
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.Equal(suite.T(), []string{"outer", "inner"}, order, "Should be equal")
+	assert.Equal(suite.T(), 1, suite.inner.count("eth_chainId"), "Should be equal")
+
+	assert.Equal(suite.T(), suite.inner, Chain(suite.inner), "Should be equal")
+}
+
+func (suite *MiddlewareTestSuite) Test_Logging() {
+	logger := &bufferLogger{}
+	provider := Chain(suite.inner, LoggingMiddleware(logger))
+
+	provider.Send(provider.GetRPCMethod().NewRequest("eth_chainId"))
+	provider.Send(provider.GetRPCMethod().NewRequest("eth_mining"))
+	provider.Send(provider.GetRPCMethod().NewRequest("eth_syncing"))
+
+	if assert.Len(suite.T(), logger.lines, 3) {
+		assert.True(suite.T(), strings.HasPrefix(logger.lines[0], "method=eth_chainId id=1 duration="), logger.lines[0])
+		assert.True(suite.T(), strings.HasSuffix(logger.lines[0], " outcome=ok"), logger.lines[0])
+		assert.True(suite.T(), strings.HasSuffix(logger.lines[1], ` outcome=error error="method not found"`), logger.lines[1])
+		assert.True(suite.T(), strings.HasSuffix(logger.lines[2], ` outcome=failure error="connection refused"`), logger.lines[2])
+	}
+}
+
+func (suite *MiddlewareTestSuite) Test_Metrics() {
+	metrics := &recordingMetrics{counts: make(map[string]int), latencies: make(map[string][]time.Duration)}
+	provider := Chain(suite.inner, MetricsMiddleware(metrics))
+
+	provider.Send(provider.GetRPCMethod().NewRequest("eth_chainId"))
+	provider.Send(provider.GetRPCMethod().NewRequest("eth_chainId"))
+	provider.Send(provider.GetRPCMethod().NewRequest("eth_mining"))
+	provider.Send(provider.GetRPCMethod().NewRequest("eth_syncing"))
+
+	assert.Equal(suite.T(), map[string]int{
+		"eth_chainId ok":      2,
+		"eth_mining error":    1,
+		"eth_syncing failure": 1,
+	}, metrics.counts, "Should be equal")
+	assert.Len(suite.T(), metrics.latencies["eth_chainId"], 2)
+}
+
+func (suite *MiddlewareTestSuite) Test_Tracing() {
+	tracer := &recordingTracer{}
+	var seen interface{}
+	spy := func(next Provider) Provider {
+		return &interceptor{Provider: next, send: func(ctx context.Context, request rpc.Request) (rpc.Response, error) {
+			seen = ctx.Value(spanKey{})
+			return next.SendContext(ctx, request)
+		}}
+	}
+	provider := Chain(suite.inner, TracingMiddleware(tracer), spy)
+
+	req := provider.GetRPCMethod().NewRequest("eth_chainId")
+	provider.Send(req)
+	provider.Send(provider.GetRPCMethod().NewRequest("eth_syncing"))
+
+	if assert.Len(suite.T(), tracer.spans, 2) {
+		span := tracer.spans[0]
+		assert.Equal(suite.T(), "eth_chainId", span.name, "Should be equal")
+		assert.True(suite.T(), span.ended, "Should be ended")
+		assert.NoError(suite.T(), span.err, "Should be no error")
+		assert.Equal(suite.T(), req.ID().String(), span.attributes["rpc.id"], "Should be equal")
+		assert.Equal(suite.T(), OutcomeOK, span.attributes["rpc.outcome"], "Should be equal")
+
+		assert.EqualError(suite.T(), tracer.spans[1].err, "connection refused", "Should be equal")
+		assert.Equal(suite.T(), tracer.spans[1], seen, "Should pass the span down")
+	}
+}
+
+func (suite *MiddlewareTestSuite) Test_Batch() {
+	logger := &bufferLogger{}
+	tracer := &recordingTracer{}
+	provider := Chain(suite.inner, LoggingMiddleware(logger), TracingMiddleware(tracer))
+
+	requests := []rpc.Request{provider.GetRPCMethod().NewRequest("eth_chainId"), provider.GetRPCMethod().NewRequest("eth_mining")}
+	responses, err := SendBatch(context.Background(), provider, requests)
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.Len(suite.T(), responses, 2)
+
+	if assert.Len(suite.T(), logger.lines, 2) {
+		assert.True(suite.T(), strings.HasSuffix(logger.lines[0], " outcome=ok"), logger.lines[0])
+		assert.True(suite.T(), strings.HasSuffix(logger.lines[1], ` outcome=error error="method not found"`), logger.lines[1])
+	}
+	if assert.Len(suite.T(), tracer.spans, 1) {
+		assert.Equal(suite.T(), "batch", tracer.spans[0].name, "Should be equal")
+		assert.Equal(suite.T(), 2, tracer.spans[0].attributes["rpc.batch_size"], "Should be equal")
+		assert.Equal(suite.T(), OutcomeOK, tracer.spans[0].attributes["rpc.outcome"], "Should be equal")
+	}
+}
+
+func (suite *MiddlewareTestSuite) SetupTest() {
+	suite.inner = &countingProvider{
+		results:  map[string]string{"eth_chainId": `"0x1"`},
+		failures: map[string]error{"eth_syncing": errors.New("connection refused")},
+		calls:    make(map[string]int),
+	}
+}
+
+func Test_MiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(MiddlewareTestSuite))
+}
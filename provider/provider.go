@@ -30,12 +30,88 @@
 package provider
 
 import (
+	"context"
+	"errors"
+
 	"github.com/yangyuan6/web3go/rpc"
 )
 
+// ErrSubscriptionNotSupported is returned when subscribing through a provider
+// that cannot deliver notifications, e.g. the HTTP provider.
+var ErrSubscriptionNotSupported = errors.New("Provider does not support subscriptions")
+
 // Provider provides basic web3 interface
 type Provider interface {
 	IsConnected() bool
 	Send(rpc.Request) (rpc.Response, error)
+	SendContext(context.Context, rpc.Request) (rpc.Response, error)
 	GetRPCMethod() rpc.RPC
 }
+
+// BatchSender is implemented by providers that can send several requests in
+// one round trip. The i-th response answers the i-th request.
+type BatchSender interface {
+	SendBatch(requests []rpc.Request) ([]rpc.Response, error)
+	SendBatchContext(ctx context.Context, requests []rpc.Request) ([]rpc.Response, error)
+}
+
+// Subscriber is implemented by providers that can deliver server initiated
+// notifications, such as those keeping a persistent connection to the node.
+type Subscriber interface {
+	// Subscribe sends request, a subscription call like eth_subscribe, and
+	// routes the notifications of the resulting subscription to handler. The
+	// handler is registered before any later message is read, so no
+	// notification is lost between the response and the registration. ctx
+	// only bounds the subscription call itself.
+	Subscribe(ctx context.Context, request rpc.Request, handler NotificationHandler) (rpc.Response, error)
+	// Unsubscribe stops routing notifications for the subscription. It does
+	// not tell the node; that is up to the caller.
+	Unsubscribe(subscriptionID string)
+}
+
+// NotificationHandler receives the notifications of one subscription. If the
+// subscription is torn down by the provider, e.g. because the connection was
+// lost, it is called once more with a nil notification and the cause. Handlers
+// run on the provider's read loop and must not block.
+type NotificationHandler func(notification rpc.Notification, err error)
+
+// SendBatch sends requests through p in one round trip if p is a BatchSender,
+// one after another otherwise.
+func SendBatch(ctx context.Context, p Provider, requests []rpc.Request) ([]rpc.Response, error) {
+	if sender, ok := p.(BatchSender); ok {
+		return sender.SendBatchContext(ctx, requests)
+	}
+	return sendEach(ctx, p.SendContext, requests)
+}
+
+// sendEach sends requests one after another with send, stopping at the first
+// error.
+func sendEach(ctx context.Context, send func(context.Context, rpc.Request) (rpc.Response, error), requests []rpc.Request) ([]rpc.Response, error) {
+	responses := make([]rpc.Response, 0, len(requests))
+	for _, request := range requests {
+		resp, err := send(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, resp)
+	}
+	return responses, nil
+}
+
+// Subscribe subscribes through p if it is a Subscriber, see
+// Subscriber.Subscribe, and fails with ErrSubscriptionNotSupported otherwise.
+func Subscribe(ctx context.Context, p Provider, request rpc.Request, handler NotificationHandler) (rpc.Response, error) {
+	subscriber, ok := p.(Subscriber)
+	if !ok {
+		return nil, ErrSubscriptionNotSupported
+	}
+	return subscriber.Subscribe(ctx, request, handler)
+}
+
+// Unsubscribe stops routing notifications for the subscription if p is a
+// Subscriber.
+func Unsubscribe(p Provider, subscriptionID string) {
+	if subscriber, ok := p.(Subscriber); ok {
+		subscriber.Unsubscribe(subscriptionID)
+	}
+}
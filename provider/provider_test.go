@@ -0,0 +1,151 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/yangyuan6/web3go/rpc"
+)
+
+// capableProvider adds batches and subscriptions to a countingProvider and
+// counts them.
+type capableProvider struct {
+	*countingProvider
+	batches       int
+	subscriptions []string
+}
+
+func (p *capableProvider) SendBatch(requests []rpc.Request) ([]rpc.Response, error) {
+	return p.SendBatchContext(context.Background(), requests)
+}
+
+func (p *capableProvider) SendBatchContext(ctx context.Context, requests []rpc.Request) ([]rpc.Response, error) {
+	p.mu.Lock()
+	p.batches++
+	p.mu.Unlock()
+	return sendEach(ctx, p.SendContext, requests)
+}
+
+func (p *capableProvider) Subscribe(ctx context.Context, request rpc.Request, handler NotificationHandler) (rpc.Response, error) {
+	p.mu.Lock()
+	p.subscriptions = append(p.subscriptions, "0xcd0c3e8af590364c09d0fa6a1210faf5")
+	p.mu.Unlock()
+	return p.GetRPCMethod().NewResponse([]byte(`{"jsonrpc":"2.0","id":` + request.ID().String() + `,"result":"0xcd0c3e8af590364c09d0fa6a1210faf5"}`)), nil
+}
+
+func (p *capableProvider) Unsubscribe(subscriptionID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, id := range p.subscriptions {
+		if id == subscriptionID {
+			p.subscriptions = append(p.subscriptions[:i], p.subscriptions[i+1:]...)
+			break
+		}
+	}
+}
+
+type ProviderTestSuite struct {
+	suite.Suite
+	inner *capableProvider
+}
+
+// wrappers returns every wrapping provider around inner.
+func (suite *ProviderTestSuite) wrappers(inner Provider) map[string]Provider {
+	recording, err := NewRecordingProvider(inner, suite.T().TempDir()+"/cassette.jsonl")
+	assert.NoError(suite.T(), err, "Should be no error")
+
+	return map[string]Provider{
+		"retry":     NewRetryProvider(inner, DefaultRetryConfig),
+		"ratelimit": NewRateLimitProvider(inner, RateLimitConfig{RequestsPerSecond: 1000, MaxInFlight: 1}),
+		"cache":     NewCacheProvider(inner, DefaultCacheConfig),
+		"fallback":  NewFallbackProviderWithConfig(FallbackConfig{}, FallbackEndpoint{Provider: inner}),
+		"recording": recording,
+		"quorum":    NewQuorumProvider(QuorumConfig{}, inner),
+		"hedged":    NewHedgedProvider(HedgeConfig{}, inner),
+		"logging":   Chain(inner, LoggingMiddleware(&bufferLogger{})),
+	}
+}
+
+func (suite *ProviderTestSuite) Test_ForwardBatches() {
+	for name, p := range suite.wrappers(suite.inner) {
+		suite.inner.batches = 0
+		requests := []rpc.Request{p.GetRPCMethod().NewRequest("eth_chainId"), p.GetRPCMethod().NewRequest("eth_mining")}
+
+		responses, err := SendBatch(context.Background(), p, requests)
+		assert.NoError(suite.T(), err, name)
+		if assert.Len(suite.T(), responses, 2, name) {
+			assert.Equal(suite.T(), "0x1", responses[0].Get("result"), name)
+			assert.EqualError(suite.T(), responses[1].Error(), "method not found", name)
+		}
+		assert.Equal(suite.T(), 1, suite.inner.batches, name)
+	}
+}
+
+func (suite *ProviderTestSuite) Test_ForwardSubscriptions() {
+	for name, p := range suite.wrappers(suite.inner) {
+		resp, err := Subscribe(context.Background(), p, p.GetRPCMethod().NewRequest("eth_subscribe", "newHeads"), func(rpc.Notification, error) {})
+		assert.NoError(suite.T(), err, name)
+		assert.Equal(suite.T(), "0xcd0c3e8af590364c09d0fa6a1210faf5", resp.Get("result"), name)
+		assert.Len(suite.T(), suite.inner.subscriptions, 1, name)
+
+		Unsubscribe(p, "0xcd0c3e8af590364c09d0fa6a1210faf5")
+		assert.Len(suite.T(), suite.inner.subscriptions, 0, name)
+	}
+}
+
+func (suite *ProviderTestSuite) Test_NotSupported() {
+	for name, p := range suite.wrappers(suite.inner.countingProvider) {
+		_, err := Subscribe(context.Background(), p, p.GetRPCMethod().NewRequest("eth_subscribe", "newHeads"), func(rpc.Notification, error) {})
+		assert.Equal(suite.T(), ErrSubscriptionNotSupported, err, name)
+
+		// Batches are sent one request after another.
+		suite.inner.calls = make(map[string]int)
+		requests := []rpc.Request{p.GetRPCMethod().NewRequest("eth_mining"), p.GetRPCMethod().NewRequest("eth_mining")}
+		responses, err := SendBatch(context.Background(), p, requests)
+		assert.NoError(suite.T(), err, name)
+		assert.Len(suite.T(), responses, 2, name)
+		assert.Equal(suite.T(), 2, suite.inner.count("eth_mining"), name)
+	}
+}
+
+func (suite *ProviderTestSuite) SetupTest() {
+	suite.inner = &capableProvider{countingProvider: &countingProvider{
+		results: map[string]string{"eth_chainId": `"0x1"`},
+		calls:   make(map[string]int),
+	}}
+}
+
+func Test_ProviderTestSuite(t *testing.T) {
+	suite.Run(t, new(ProviderTestSuite))
+}
@@ -0,0 +1,294 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/yangyuan6/web3go/rpc"
+)
+
+// ErrNoQuorum is returned by a QuorumProvider when too few backends agree.
+type ErrNoQuorum struct {
+	Method string
+	// Quorum is the number of backends required to agree.
+	Quorum int
+	// Agreeing is the size of the largest group of backends that agreed.
+	Agreeing int
+	// Errors holds the errors of the backends that failed.
+	Errors []error
+}
+
+func (err *ErrNoQuorum) Error() string {
+	return fmt.Sprintf("No quorum for %s: %d of %d required backends agree, %d failed", err.Method, err.Agreeing, err.Quorum, len(err.Errors))
+}
+
+// QuorumConfig tunes a QuorumProvider.
+type QuorumConfig struct {
+	// Quorum is the number of backends that must give the same answer. Zero
+	// means a majority.
+	Quorum int
+	// OnDisagreement, if not nil, is called for each backend whose answer
+	// differs from the one agreed on, or that failed. backend is its index.
+	// It may be called after Send returned, from another goroutine, as the
+	// slower backends answer.
+	OnDisagreement func(request rpc.Request, backend int, resp rpc.Response, err error)
+}
+
+// QuorumProvider sends each request to all its backends and only returns a
+// response once a quorum of them agree on it. Results are compared as
+// canonical JSON; JSON-RPC errors are answers too, compared on code and
+// message. A batch is sent as a whole to every backend and each of its
+// requests needs a quorum. Subscriptions go to the first backend only, since
+// notifications cannot be compared.
+type QuorumProvider struct {
+	providers []Provider
+	config    QuorumConfig
+}
+
+// quorumAnswer is what a backend answered to the requests sent together.
+type quorumAnswer struct {
+	backend   int
+	responses []rpc.Response
+	err       error
+	keys      []string
+}
+
+// NewQuorumProvider creates a quorum provider over providers.
+func NewQuorumProvider(config QuorumConfig, providers ...Provider) Provider {
+	if config.Quorum <= 0 {
+		config.Quorum = len(providers)/2 + 1
+	}
+	return &QuorumProvider{providers: providers, config: config}
+}
+
+// IsConnected reports whether enough backends are connected to reach a quorum.
+func (provider *QuorumProvider) IsConnected() bool {
+	connected := 0
+	for _, p := range provider.providers {
+		if p.IsConnected() {
+			connected++
+		}
+	}
+	return connected >= provider.config.Quorum
+}
+
+// Send sends the request to all backends and returns the response a quorum
+// of them agree on.
+func (provider *QuorumProvider) Send(request rpc.Request) (rpc.Response, error) {
+	return provider.SendContext(context.Background(), request)
+}
+
+// SendContext is like Send but takes a context.
+func (provider *QuorumProvider) SendContext(ctx context.Context, request rpc.Request) (rpc.Response, error) {
+	responses, err := provider.vote(ctx, []rpc.Request{request}, func(p Provider) ([]rpc.Response, error) {
+		resp, err := p.SendContext(ctx, request)
+		return []rpc.Response{resp}, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return responses[0], nil
+}
+
+// SendBatch sends the requests to all backends, in one round trip to those
+// that support it, and returns for each request the response a quorum of
+// them agree on.
+func (provider *QuorumProvider) SendBatch(requests []rpc.Request) ([]rpc.Response, error) {
+	return provider.SendBatchContext(context.Background(), requests)
+}
+
+// SendBatchContext is like SendBatch but takes a context.
+func (provider *QuorumProvider) SendBatchContext(ctx context.Context, requests []rpc.Request) ([]rpc.Response, error) {
+	return provider.vote(ctx, requests, func(p Provider) ([]rpc.Response, error) {
+		return SendBatch(ctx, p, requests)
+	})
+}
+
+// Subscribe subscribes through the first backend.
+func (provider *QuorumProvider) Subscribe(ctx context.Context, request rpc.Request, handler NotificationHandler) (rpc.Response, error) {
+	if len(provider.providers) == 0 {
+		return nil, ErrNoProvider
+	}
+	return Subscribe(ctx, provider.providers[0], request, handler)
+}
+
+// Unsubscribe ...
+func (provider *QuorumProvider) Unsubscribe(subscriptionID string) {
+	if len(provider.providers) > 0 {
+		Unsubscribe(provider.providers[0], subscriptionID)
+	}
+}
+
+// vote sends requests to every backend with send and returns, for each
+// request, the response a quorum of backends agree on.
+func (provider *QuorumProvider) vote(ctx context.Context, requests []rpc.Request, send func(p Provider) ([]rpc.Response, error)) ([]rpc.Response, error) {
+	answers := make(chan quorumAnswer, len(provider.providers))
+	for i, p := range provider.providers {
+		go func(i int, p Provider) {
+			answer := quorumAnswer{backend: i}
+			if answer.responses, answer.err = send(p); answer.err == nil {
+				answer.keys, answer.err = answerKeys(answer.responses, len(requests))
+			}
+			answers <- answer
+		}(i, p)
+	}
+
+	votes := make([]map[string]int, len(requests))
+	agreeing := make([]int, len(requests))
+	for i := range votes {
+		votes[i] = make(map[string]int)
+	}
+	agreed := make([]string, len(requests))
+	responses := make([]rpc.Response, len(requests))
+	settled := make([]bool, len(requests))
+	unsettled := len(requests)
+
+	var errs []error
+	var received []quorumAnswer
+	for pending := len(provider.providers); pending > 0; pending-- {
+		var answer quorumAnswer
+		select {
+		case answer = <-answers:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		received = append(received, answer)
+
+		if answer.err != nil {
+			errs = append(errs, answer.err)
+		} else {
+			for i, key := range answer.keys {
+				votes[i][key]++
+				if votes[i][key] > agreeing[i] {
+					agreeing[i] = votes[i][key]
+				}
+				if !settled[i] && votes[i][key] >= provider.config.Quorum {
+					settled[i] = true
+					agreed[i] = key
+					responses[i] = answer.responses[i]
+					unsettled--
+				}
+			}
+			if unsettled == 0 {
+				go provider.report(requests, agreed, received, answers, pending-1)
+				return responses, nil
+			}
+		}
+
+		for i := range requests {
+			if !settled[i] && agreeing[i]+pending-1 < provider.config.Quorum {
+				return nil, provider.noQuorum(requests[i], agreeing[i], errs)
+			}
+		}
+	}
+
+	for i := range requests {
+		if !settled[i] {
+			return nil, provider.noQuorum(requests[i], agreeing[i], errs)
+		}
+	}
+	return responses, nil
+}
+
+func (provider *QuorumProvider) noQuorum(request rpc.Request, agreeing int, errs []error) error {
+	noQuorum := &ErrNoQuorum{Quorum: provider.config.Quorum, Agreeing: agreeing, Errors: errs}
+	noQuorum.Method, _ = request.Get("method").(string)
+	return noQuorum
+}
+
+// GetRPCMethod returns the RPC of the first backend.
+func (provider *QuorumProvider) GetRPCMethod() rpc.RPC {
+	if len(provider.providers) == 0 {
+		return rpc.GetDefaultMethod()
+	}
+	return provider.providers[0].GetRPCMethod()
+}
+
+// report calls OnDisagreement for the answers received so far and the pending
+// ones that differ from the agreed answers.
+func (provider *QuorumProvider) report(requests []rpc.Request, agreed []string, received []quorumAnswer, answers <-chan quorumAnswer, pending int) {
+	if provider.config.OnDisagreement == nil {
+		return
+	}
+
+	check := func(answer quorumAnswer) {
+		for i, request := range requests {
+			if answer.err != nil {
+				provider.config.OnDisagreement(request, answer.backend, nil, answer.err)
+			} else if answer.keys[i] != agreed[i] {
+				provider.config.OnDisagreement(request, answer.backend, answer.responses[i], nil)
+			}
+		}
+	}
+	for _, answer := range received {
+		check(answer)
+	}
+	for ; pending > 0; pending-- {
+		check(<-answers)
+	}
+}
+
+// answerKeys returns the canonical JSON form of the answers in responses,
+// which should hold one response per request.
+func answerKeys(responses []rpc.Response, requests int) ([]string, error) {
+	if len(responses) != requests {
+		return nil, fmt.Errorf("Got %d responses to %d requests", len(responses), requests)
+	}
+	keys := make([]string, len(responses))
+	for i, resp := range responses {
+		key, err := answerKey(resp)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+	}
+	return keys, nil
+}
+
+// answerKey returns the canonical JSON form of the answer in resp.
+func answerKey(resp rpc.Response) (string, error) {
+	if resp == nil {
+		// Notifications are not answered.
+		return "", nil
+	}
+	answer := map[string]interface{}{"result": resp.Get("result")}
+	if err := resp.Error(); err != nil {
+		if rpcErr, ok := err.(*rpc.JSONRPCError); ok {
+			answer = map[string]interface{}{"error": map[string]interface{}{"code": rpcErr.Code, "message": rpcErr.Message}}
+		} else {
+			answer = map[string]interface{}{"error": err.Error()}
+		}
+	}
+	key, err := json.Marshal(answer)
+	return string(key), err
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/yangyuan6/web3go/rpc"
+)
+
+type disagreement struct {
+	backend int
+	err     error
+}
+
+// revertingProvider answers every request with a JSON-RPC error carrying data.
+type revertingProvider struct {
+	countingProvider
+	data string
+}
+
+func (p *revertingProvider) Send(request rpc.Request) (rpc.Response, error) {
+	return p.SendContext(context.Background(), request)
+}
+
+func (p *revertingProvider) SendContext(ctx context.Context, request rpc.Request) (rpc.Response, error) {
+	body := fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"error":{"code":3,"message":"execution reverted","data":%q}}`, request.ID(), p.data)
+	return p.GetRPCMethod().NewResponse([]byte(body)), nil
+}
+
+type QuorumProviderTestSuite struct {
+	suite.Suite
+	mu            sync.Mutex
+	disagreements []disagreement
+	reported      chan struct{}
+}
+
+func (suite *QuorumProviderTestSuite) backend(result string) *countingProvider {
+	return &countingProvider{results: map[string]string{"eth_getBalance": result}, calls: make(map[string]int)}
+}
+
+func (suite *QuorumProviderTestSuite) failing() *countingProvider {
+	return &countingProvider{failures: map[string]error{"eth_getBalance": errors.New("connection refused")}, calls: make(map[string]int)}
+}
+
+func (suite *QuorumProviderTestSuite) provider(quorum int, providers ...Provider) Provider {
+	return NewQuorumProvider(QuorumConfig{
+		Quorum: quorum,
+		OnDisagreement: func(request rpc.Request, backend int, resp rpc.Response, err error) {
+			suite.mu.Lock()
+			suite.disagreements = append(suite.disagreements, disagreement{backend, err})
+			suite.mu.Unlock()
+			suite.reported <- struct{}{}
+		},
+	}, providers...)
+}
+
+func (suite *QuorumProviderTestSuite) send(provider Provider) (rpc.Response, error) {
+	return provider.Send(provider.GetRPCMethod().NewRequest("eth_getBalance"))
+}
+
+func (suite *QuorumProviderTestSuite) Test_Agree() {
+	provider := suite.provider(0, suite.backend(`{"a":1,"b":2}`), suite.backend(`{"b":2,"a":1}`), suite.backend(`{"a":1,"b":2}`))
+	resp, err := suite.send(provider)
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.EqualValues(suite.T(), 2, resp.Get("result").(map[string]interface{})["b"], "Should be equal")
+	assert.Empty(suite.T(), suite.disagreements, "Should be empty")
+}
+
+func (suite *QuorumProviderTestSuite) Test_Disagreement() {
+	provider := suite.provider(2, suite.backend(`"0x1"`), suite.backend(`"0x2"`), suite.backend(`"0x1"`))
+	resp, err := suite.send(provider)
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.EqualValues(suite.T(), "0x1", resp.Get("result"), "Should be equal")
+
+	<-suite.reported
+	assert.Equal(suite.T(), []disagreement{{backend: 1}}, suite.disagreements, "Should be equal")
+}
+
+func (suite *QuorumProviderTestSuite) Test_FailedBackend() {
+	provider := suite.provider(2, suite.failing(), suite.backend(`"0x1"`), suite.backend(`"0x1"`))
+	resp, err := suite.send(provider)
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.EqualValues(suite.T(), "0x1", resp.Get("result"), "Should be equal")
+
+	<-suite.reported
+	assert.Equal(suite.T(), 0, suite.disagreements[0].backend, "Should be equal")
+	assert.EqualError(suite.T(), suite.disagreements[0].err, "connection refused", "Should be equal")
+}
+
+func (suite *QuorumProviderTestSuite) Test_NoQuorum() {
+	provider := suite.provider(2, suite.backend(`"0x1"`), suite.backend(`"0x2"`), suite.failing())
+	_, err := suite.send(provider)
+	if assert.IsType(suite.T(), &ErrNoQuorum{}, err, "Should be typed") {
+		noQuorum := err.(*ErrNoQuorum)
+		assert.Equal(suite.T(), "eth_getBalance", noQuorum.Method, "Should be equal")
+		assert.Equal(suite.T(), 2, noQuorum.Quorum, "Should be equal")
+		assert.Equal(suite.T(), 1, noQuorum.Agreeing, "Should be equal")
+		assert.Len(suite.T(), noQuorum.Errors, 1)
+		assert.EqualError(suite.T(), err, "No quorum for eth_getBalance: 1 of 2 required backends agree, 1 failed", "Should be equal")
+	}
+}
+
+func (suite *QuorumProviderTestSuite) Test_JSONRPCErrors() {
+	provider := suite.provider(2, &countingProvider{calls: make(map[string]int)}, &countingProvider{calls: make(map[string]int)})
+	resp, err := suite.send(provider)
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.EqualError(suite.T(), resp.Error(), "method not found", "Should be equal")
+}
+
+func (suite *QuorumProviderTestSuite) Test_JSONRPCErrorData() {
+	provider := suite.provider(2, &revertingProvider{data: "0x01"}, &revertingProvider{data: "0x02"})
+	resp, err := suite.send(provider)
+	assert.NoError(suite.T(), err, "Should agree on code and message")
+	assert.EqualError(suite.T(), resp.Error(), "execution reverted", "Should be equal")
+}
+
+func (suite *QuorumProviderTestSuite) Test_SlowBackend() {
+	slow := &gatedProvider{gate: make(chan struct{})}
+	slow.scriptedProvider.failures = []error{errors.New("late")}
+	provider := suite.provider(2, suite.backend(`"0x1"`), slow, suite.backend(`"0x1"`))
+
+	start := time.Now()
+	resp, err := suite.send(provider)
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.EqualValues(suite.T(), "0x1", resp.Get("result"), "Should be equal")
+	assert.True(suite.T(), time.Since(start) < time.Second, "Should not wait for the slow backend")
+
+	close(slow.gate)
+	<-suite.reported
+	assert.Equal(suite.T(), 1, suite.disagreements[0].backend, "Should be equal")
+}
+
+func (suite *QuorumProviderTestSuite) Test_ContextCanceled() {
+	slow := &gatedProvider{gate: make(chan struct{})}
+	provider := suite.provider(2, suite.backend(`"0x1"`), slow)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := provider.SendContext(ctx, provider.GetRPCMethod().NewRequest("eth_getBalance"))
+	assert.Equal(suite.T(), context.DeadlineExceeded, err, "Should be equal")
+}
+
+func (suite *QuorumProviderTestSuite) SetupTest() {
+	suite.disagreements = nil
+	suite.reported = make(chan struct{}, 10)
+}
+
+func Test_QuorumProviderTestSuite(t *testing.T) {
+	suite.Run(t, new(QuorumProviderTestSuite))
+}
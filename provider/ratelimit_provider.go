@@ -0,0 +1,239 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/yangyuan6/web3go/rpc"
+)
+
+// ErrRateLimited is returned by a RateLimitProvider set to fail fast when a
+// request would have to wait.
+var ErrRateLimited = errors.New("Rate limit exceeded")
+
+// RateLimitConfig tunes a RateLimitProvider.
+type RateLimitConfig struct {
+	// RequestsPerSecond is the rate at which the token bucket refills. Zero
+	// disables the rate limit.
+	RequestsPerSecond float64
+	// Burst is the size of the token bucket. Zero means one second worth of
+	// requests.
+	Burst int
+	// MaxInFlight caps the number of requests awaiting a response. Zero
+	// disables the cap.
+	MaxInFlight int
+	// MethodWeights is the number of tokens a request costs by method, e.g.
+	// to make eth_getLogs count more than eth_blockNumber. Other methods cost
+	// one token. A cost is never more than Burst, and negative weights
+	// count as zero.
+	MethodWeights map[string]int
+	// FailFast returns ErrRateLimited instead of waiting for a token or for
+	// a request in flight to complete.
+	FailFast bool
+}
+
+// RateLimitProvider keeps the requests sent through the provider it wraps
+// within a rate limit and a concurrency limit.
+type RateLimitProvider struct {
+	provider Provider
+	config   RateLimitConfig
+	inFlight chan struct{}
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimitProvider wraps provider so that requests are throttled.
+func NewRateLimitProvider(provider Provider, config RateLimitConfig) Provider {
+	if config.Burst <= 0 {
+		config.Burst = int(math.Max(1, math.Ceil(config.RequestsPerSecond)))
+	}
+
+	limiter := &RateLimitProvider{
+		provider: provider,
+		config:   config,
+		tokens:   float64(config.Burst),
+		last:     time.Now(),
+	}
+	if config.MaxInFlight > 0 {
+		limiter.inFlight = make(chan struct{}, config.MaxInFlight)
+	}
+	return limiter
+}
+
+// IsConnected ...
+func (provider *RateLimitProvider) IsConnected() bool {
+	return provider.provider.IsConnected()
+}
+
+// Send sends the request once the limits allow it.
+func (provider *RateLimitProvider) Send(request rpc.Request) (rpc.Response, error) {
+	return provider.SendContext(context.Background(), request)
+}
+
+// SendContext is like Send but takes a context, which also bounds the wait.
+func (provider *RateLimitProvider) SendContext(ctx context.Context, request rpc.Request) (rpc.Response, error) {
+	method, _ := request.Get("method").(string)
+	release, err := provider.acquire(ctx, provider.cost(method))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return provider.provider.SendContext(ctx, request)
+}
+
+// SendBatch sends the requests in one round trip if the wrapped provider
+// supports it. The batch costs the tokens of all its requests, up to Burst,
+// and counts as a single request in flight.
+func (provider *RateLimitProvider) SendBatch(requests []rpc.Request) ([]rpc.Response, error) {
+	return provider.SendBatchContext(context.Background(), requests)
+}
+
+// SendBatchContext is like SendBatch but takes a context, which also bounds
+// the wait.
+func (provider *RateLimitProvider) SendBatchContext(ctx context.Context, requests []rpc.Request) ([]rpc.Response, error) {
+	cost := 0.0
+	for _, request := range requests {
+		method, _ := request.Get("method").(string)
+		cost += provider.cost(method)
+	}
+	release, err := provider.acquire(ctx, math.Min(cost, float64(provider.config.Burst)))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return SendBatch(ctx, provider.provider, requests)
+}
+
+// Subscribe subscribes through the wrapped provider once the limits allow the
+// subscription call. Notifications are not limited.
+func (provider *RateLimitProvider) Subscribe(ctx context.Context, request rpc.Request, handler NotificationHandler) (rpc.Response, error) {
+	method, _ := request.Get("method").(string)
+	release, err := provider.acquire(ctx, provider.cost(method))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return Subscribe(ctx, provider.provider, request, handler)
+}
+
+// Unsubscribe ...
+func (provider *RateLimitProvider) Unsubscribe(subscriptionID string) {
+	Unsubscribe(provider.provider, subscriptionID)
+}
+
+// GetRPCMethod ...
+func (provider *RateLimitProvider) GetRPCMethod() rpc.RPC {
+	return provider.provider.GetRPCMethod()
+}
+
+func (provider *RateLimitProvider) cost(method string) float64 {
+	cost := 1
+	if weight, ok := provider.config.MethodWeights[method]; ok {
+		cost = weight
+	}
+	if cost > provider.config.Burst {
+		cost = provider.config.Burst
+	}
+	if cost < 0 {
+		cost = 0
+	}
+	return float64(cost)
+}
+
+// acquire waits for a slot for a request in flight, then for cost tokens, so
+// that requests queued behind MaxInFlight do not drain the bucket. release
+// frees the slot once the request is answered.
+func (provider *RateLimitProvider) acquire(ctx context.Context, cost float64) (release func(), err error) {
+	release = func() {}
+	if provider.inFlight != nil {
+		if provider.config.FailFast {
+			select {
+			case provider.inFlight <- struct{}{}:
+			default:
+				return nil, ErrRateLimited
+			}
+		} else {
+			select {
+			case provider.inFlight <- struct{}{}:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		release = func() { <-provider.inFlight }
+	}
+
+	if err := provider.take(ctx, cost); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+// take removes cost tokens from the bucket, waiting for it to refill if
+// needed.
+func (provider *RateLimitProvider) take(ctx context.Context, cost float64) error {
+	if provider.config.RequestsPerSecond <= 0 {
+		return nil
+	}
+
+	for {
+		provider.mu.Lock()
+		now := time.Now()
+		provider.tokens += now.Sub(provider.last).Seconds() * provider.config.RequestsPerSecond
+		provider.tokens = math.Min(provider.tokens, float64(provider.config.Burst))
+		provider.last = now
+
+		if provider.tokens >= cost {
+			provider.tokens -= cost
+			provider.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((cost - provider.tokens) / provider.config.RequestsPerSecond * float64(time.Second))
+		provider.mu.Unlock()
+
+		if provider.config.FailFast {
+			return ErrRateLimited
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
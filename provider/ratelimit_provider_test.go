@@ -0,0 +1,207 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package provider
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/yangyuan6/web3go/rpc"
+)
+
+// gatedProvider holds every request until the gate is closed.
+type gatedProvider struct {
+	scriptedProvider
+	gate chan struct{}
+}
+
+func (p *gatedProvider) SendContext(ctx context.Context, request rpc.Request) (rpc.Response, error) {
+	select {
+	case <-p.gate:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return p.scriptedProvider.SendContext(ctx, request)
+}
+
+type RateLimitProviderTestSuite struct {
+	suite.Suite
+}
+
+func (suite *RateLimitProviderTestSuite) send(provider Provider, method string) error {
+	_, err := provider.Send(provider.GetRPCMethod().NewRequest(method))
+	return err
+}
+
+func (suite *RateLimitProviderTestSuite) Test_FailFast() {
+	provider := NewRateLimitProvider(&scriptedProvider{}, RateLimitConfig{
+		RequestsPerSecond: 10,
+		Burst:             3,
+		FailFast:          true,
+	})
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(suite.T(), suite.send(provider, "eth_blockNumber"), "Should be no error")
+	}
+	assert.Equal(suite.T(), ErrRateLimited, suite.send(provider, "eth_blockNumber"), "Should be equal")
+
+	time.Sleep(150 * time.Millisecond)
+	assert.NoError(suite.T(), suite.send(provider, "eth_blockNumber"), "Should be no error")
+}
+
+func (suite *RateLimitProviderTestSuite) Test_MethodWeights() {
+	provider := NewRateLimitProvider(&scriptedProvider{}, RateLimitConfig{
+		RequestsPerSecond: 1,
+		Burst:             5,
+		MethodWeights:     map[string]int{"eth_getLogs": 4, "debug_traceTransaction": 100},
+		FailFast:          true,
+	})
+
+	assert.NoError(suite.T(), suite.send(provider, "eth_getLogs"), "Should be no error")
+	assert.Equal(suite.T(), ErrRateLimited, suite.send(provider, "eth_getLogs"), "Should be equal")
+	assert.NoError(suite.T(), suite.send(provider, "eth_blockNumber"), "Should be no error")
+
+	// Weights above the burst are capped, so such methods are not starved.
+	limited := NewRateLimitProvider(&scriptedProvider{}, RateLimitConfig{
+		RequestsPerSecond: 1,
+		Burst:             5,
+		MethodWeights:     map[string]int{"debug_traceTransaction": 100},
+		FailFast:          true,
+	})
+	assert.NoError(suite.T(), suite.send(limited, "debug_traceTransaction"), "Should be no error")
+}
+
+func (suite *RateLimitProviderTestSuite) Test_Wait() {
+	provider := NewRateLimitProvider(&scriptedProvider{}, RateLimitConfig{RequestsPerSecond: 20, Burst: 1})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		assert.NoError(suite.T(), suite.send(provider, "eth_blockNumber"), "Should be no error")
+	}
+	assert.True(suite.T(), time.Since(start) >= 90*time.Millisecond, "Should wait for the bucket to refill")
+}
+
+func (suite *RateLimitProviderTestSuite) Test_WaitContextCanceled() {
+	provider := NewRateLimitProvider(&scriptedProvider{}, RateLimitConfig{RequestsPerSecond: 0.1, Burst: 1})
+	assert.NoError(suite.T(), suite.send(provider, "eth_blockNumber"), "Should be no error")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := provider.SendContext(ctx, provider.GetRPCMethod().NewRequest("eth_blockNumber"))
+	assert.Equal(suite.T(), context.DeadlineExceeded, err, "Should be equal")
+}
+
+func (suite *RateLimitProviderTestSuite) Test_MaxInFlight() {
+	inner := &gatedProvider{gate: make(chan struct{})}
+	provider := NewRateLimitProvider(inner, RateLimitConfig{MaxInFlight: 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(suite.T(), suite.send(provider, "eth_blockNumber"), "Should be no error")
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(suite.T(), 2, len(provider.(*RateLimitProvider).inFlight), "Should be equal")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := provider.SendContext(ctx, provider.GetRPCMethod().NewRequest("eth_blockNumber"))
+	assert.Equal(suite.T(), context.DeadlineExceeded, err, "Should be equal")
+
+	close(inner.gate)
+	wg.Wait()
+	assert.Equal(suite.T(), 4, inner.attemptCount(), "Should be equal")
+}
+
+func (suite *RateLimitProviderTestSuite) Test_MaxInFlightFailFast() {
+	inner := &gatedProvider{gate: make(chan struct{})}
+	provider := NewRateLimitProvider(inner, RateLimitConfig{MaxInFlight: 1, FailFast: true})
+
+	done := make(chan error)
+	go func() {
+		done <- suite.send(provider, "eth_blockNumber")
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(suite.T(), ErrRateLimited, suite.send(provider, "eth_blockNumber"), "Should be equal")
+
+	close(inner.gate)
+	assert.NoError(suite.T(), <-done, "Should be no error")
+}
+
+func (suite *RateLimitProviderTestSuite) Test_MaxInFlightKeepsTokens() {
+	inner := &gatedProvider{gate: make(chan struct{})}
+	provider := NewRateLimitProvider(inner, RateLimitConfig{
+		RequestsPerSecond: 0.1,
+		Burst:             2,
+		MaxInFlight:       1,
+		FailFast:          true,
+	})
+
+	done := make(chan error)
+	go func() {
+		done <- suite.send(provider, "eth_blockNumber")
+	}()
+
+	// A request turned away for want of a slot takes no token.
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(suite.T(), ErrRateLimited, suite.send(provider, "eth_blockNumber"), "Should be equal")
+	close(inner.gate)
+	assert.NoError(suite.T(), <-done, "Should be no error")
+	assert.NoError(suite.T(), suite.send(provider, "eth_blockNumber"), "Should be no error")
+}
+
+func (suite *RateLimitProviderTestSuite) Test_NegativeWeight() {
+	provider := NewRateLimitProvider(&scriptedProvider{}, RateLimitConfig{
+		RequestsPerSecond: 1,
+		Burst:             1,
+		MethodWeights:     map[string]int{"eth_chainId": -1},
+		FailFast:          true,
+	})
+
+	// Negative weights count as zero, so they neither cost nor refill tokens.
+	for i := 0; i < 3; i++ {
+		assert.NoError(suite.T(), suite.send(provider, "eth_chainId"), "Should be no error")
+	}
+	assert.NoError(suite.T(), suite.send(provider, "eth_blockNumber"), "Should be no error")
+	assert.Equal(suite.T(), ErrRateLimited, suite.send(provider, "eth_blockNumber"), "Should be equal")
+}
+
+func Test_RateLimitProviderTestSuite(t *testing.T) {
+	suite.Run(t, new(RateLimitProviderTestSuite))
+}
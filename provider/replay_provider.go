@@ -0,0 +1,364 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/yangyuan6/web3go/rpc"
+)
+
+// ErrNoRecording is returned by a ReplayProvider for requests that were not
+// recorded.
+var ErrNoRecording = errors.New("No recorded response matches the request")
+
+// interaction is a line of a cassette, i.e. a request and what it got.
+type interaction struct {
+	Method string            `json:"method"`
+	Params json.RawMessage   `json:"params"`
+	Result json.RawMessage   `json:"result,omitempty"`
+	Error  *rpc.JSONRPCError `json:"error,omitempty"`
+	// Failure is the error returned by the provider, if any.
+	Failure string `json:"failure,omitempty"`
+}
+
+// RecordingProvider writes every request sent through the provider it wraps
+// and its outcome to a cassette, a file holding one JSON object per line, to
+// be served back by a ReplayProvider.
+type RecordingProvider struct {
+	provider Provider
+
+	mu      sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+}
+
+// NewRecordingProvider wraps provider so that interactions are recorded to the
+// file at path, which is truncated. Close the provider when done.
+func NewRecordingProvider(provider Provider, path string) (Provider, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &RecordingProvider{provider: provider, file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+// IsConnected ...
+func (provider *RecordingProvider) IsConnected() bool {
+	return provider.provider.IsConnected()
+}
+
+// Send sends the request and records it along with the response.
+func (provider *RecordingProvider) Send(request rpc.Request) (rpc.Response, error) {
+	return provider.SendContext(context.Background(), request)
+}
+
+// SendContext is like Send but takes a context. Requests aborted by the
+// context and notifications are not recorded.
+func (provider *RecordingProvider) SendContext(ctx context.Context, request rpc.Request) (rpc.Response, error) {
+	resp, err := provider.provider.SendContext(ctx, request)
+	if ctx.Err() == nil {
+		provider.record(request, resp, err)
+	}
+	return resp, err
+}
+
+// SendBatch sends the requests in one round trip if the wrapped provider
+// supports it and records each of them along with its response, to be
+// replayed one by one.
+func (provider *RecordingProvider) SendBatch(requests []rpc.Request) ([]rpc.Response, error) {
+	return provider.SendBatchContext(context.Background(), requests)
+}
+
+// SendBatchContext is like SendBatch but takes a context.
+func (provider *RecordingProvider) SendBatchContext(ctx context.Context, requests []rpc.Request) ([]rpc.Response, error) {
+	responses, err := SendBatch(ctx, provider.provider, requests)
+	if ctx.Err() == nil {
+		for i, request := range requests {
+			var resp rpc.Response
+			if err == nil && i < len(responses) {
+				resp = responses[i]
+			}
+			provider.record(request, resp, err)
+		}
+	}
+	return responses, err
+}
+
+// Subscribe subscribes through the wrapped provider. Neither the subscription
+// nor its notifications are recorded.
+func (provider *RecordingProvider) Subscribe(ctx context.Context, request rpc.Request, handler NotificationHandler) (rpc.Response, error) {
+	return Subscribe(ctx, provider.provider, request, handler)
+}
+
+// Unsubscribe ...
+func (provider *RecordingProvider) Unsubscribe(subscriptionID string) {
+	Unsubscribe(provider.provider, subscriptionID)
+}
+
+// record writes request and its outcome to the cassette. Notifications are
+// not recorded.
+func (provider *RecordingProvider) record(request rpc.Request, resp rpc.Response, err error) {
+	if request.IsNotification() {
+		return
+	}
+
+	params, err2 := json.Marshal(request.Get("params"))
+	if err2 != nil {
+		return
+	}
+	record := interaction{Method: request.Get("method").(string), Params: params}
+	switch {
+	case err != nil:
+		record.Failure = err.Error()
+	case resp == nil:
+		return
+	case resp.Error() != nil:
+		if rpcErr, ok := resp.Error().(*rpc.JSONRPCError); ok {
+			record.Error = rpcErr
+		} else {
+			record.Error = &rpc.JSONRPCError{Code: rpc.CodeInternalError, Message: resp.Error().Error()}
+		}
+	default:
+		if err2 = resp.DecodeResult(&record.Result); err2 != nil {
+			return
+		}
+	}
+
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	if provider.file != nil {
+		provider.encoder.Encode(record)
+	}
+}
+
+// GetRPCMethod ...
+func (provider *RecordingProvider) GetRPCMethod() rpc.RPC {
+	return provider.provider.GetRPCMethod()
+}
+
+// Close closes the cassette. It does not close the wrapped provider.
+func (provider *RecordingProvider) Close() error {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	if provider.file == nil {
+		return nil
+	}
+	err := provider.file.Close()
+	provider.file = nil
+	return err
+}
+
+// ReplayStrictness tells how a ReplayProvider matches requests with the
+// recorded ones.
+type ReplayStrictness int
+
+const (
+	// ReplayUnordered matches requests on method and params, in any order.
+	// Each recorded response is served once, in the order recorded.
+	ReplayUnordered ReplayStrictness = iota
+	// ReplayStrict also requires requests to come in the order recorded.
+	ReplayStrict
+	// ReplayLoose is like ReplayUnordered but serves the last matching
+	// response again once all of them were used, e.g. for polling loops.
+	ReplayLoose
+)
+
+// ReplayOption configures a ReplayProvider
+type ReplayOption func(*ReplayProvider)
+
+// WithStrictness sets how requests are matched, ReplayUnordered by default.
+func WithStrictness(strictness ReplayStrictness) ReplayOption {
+	return func(provider *ReplayProvider) {
+		provider.strictness = strictness
+	}
+}
+
+// WithReplayRPC sets the RPC of the provider, the default RPC otherwise.
+func WithReplayRPC(method rpc.RPC) ReplayOption {
+	return func(provider *ReplayProvider) {
+		provider.rpc = method
+	}
+}
+
+// ReplayProvider answers requests from a cassette written by a
+// RecordingProvider, without any network access.
+type ReplayProvider struct {
+	rpc        rpc.RPC
+	strictness ReplayStrictness
+
+	mu           sync.Mutex
+	interactions []*interaction
+	keys         []string
+	used         []bool
+	next         int
+}
+
+// NewReplayProvider loads the cassette at path.
+func NewReplayProvider(path string, options ...ReplayOption) (Provider, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	provider := &ReplayProvider{rpc: rpc.GetDefaultMethod()}
+	for _, option := range options {
+		option(provider)
+	}
+
+	decoder := json.NewDecoder(file)
+	for {
+		record := &interaction{}
+		if err := decoder.Decode(record); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		var params interface{}
+		if err := json.Unmarshal(record.Params, &params); err != nil {
+			return nil, err
+		}
+		_, key, ok := normalizeParams(record.Method, params)
+		if !ok {
+			return nil, errors.New("Malformed params in cassette")
+		}
+		provider.interactions = append(provider.interactions, record)
+		provider.keys = append(provider.keys, key)
+	}
+	provider.used = make([]bool, len(provider.interactions))
+	return provider, nil
+}
+
+// IsConnected ...
+func (provider *ReplayProvider) IsConnected() bool {
+	return true
+}
+
+// Send answers the request with the matching recorded response.
+func (provider *ReplayProvider) Send(request rpc.Request) (rpc.Response, error) {
+	return provider.SendContext(context.Background(), request)
+}
+
+// SendContext is like Send but takes a context.
+func (provider *ReplayProvider) SendContext(ctx context.Context, request rpc.Request) (rpc.Response, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if request.IsNotification() {
+		return nil, nil
+	}
+
+	method, _ := request.Get("method").(string)
+	_, key, ok := normalizeParams(method, request.Get("params"))
+	if !ok {
+		return nil, ErrNoRecording
+	}
+
+	record := provider.match(key)
+	if record == nil {
+		return nil, ErrNoRecording
+	}
+	if record.Failure != "" {
+		return nil, errors.New(record.Failure)
+	}
+	if record.Error != nil {
+		return provider.rpc.NewReply(request, nil, record.Error), nil
+	}
+
+	return provider.rpc.NewReply(request, record.Result, nil), nil
+}
+
+// SendBatch answers each request with the matching recorded response, as
+// Send does.
+func (provider *ReplayProvider) SendBatch(requests []rpc.Request) ([]rpc.Response, error) {
+	return provider.SendBatchContext(context.Background(), requests)
+}
+
+// SendBatchContext is like SendBatch but takes a context.
+func (provider *ReplayProvider) SendBatchContext(ctx context.Context, requests []rpc.Request) ([]rpc.Response, error) {
+	return sendEach(ctx, provider.SendContext, requests)
+}
+
+// GetRPCMethod ...
+func (provider *ReplayProvider) GetRPCMethod() rpc.RPC {
+	return provider.rpc
+}
+
+// Remaining returns the number of recorded responses not served yet.
+func (provider *ReplayProvider) Remaining() int {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	remaining := 0
+	for _, used := range provider.used {
+		if !used {
+			remaining++
+		}
+	}
+	return remaining
+}
+
+func (provider *ReplayProvider) match(key string) *interaction {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	if provider.strictness == ReplayStrict {
+		if provider.next >= len(provider.keys) || provider.keys[provider.next] != key {
+			return nil
+		}
+		provider.used[provider.next] = true
+		provider.next++
+		return provider.interactions[provider.next-1]
+	}
+
+	last := -1
+	for i, k := range provider.keys {
+		if k != key {
+			continue
+		}
+		if !provider.used[i] {
+			provider.used[i] = true
+			return provider.interactions[i]
+		}
+		last = i
+	}
+	if provider.strictness == ReplayLoose && last >= 0 {
+		return provider.interactions[last]
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/yangyuan6/web3go/rpc"
+)
+
+type ReplayProviderTestSuite struct {
+	suite.Suite
+	dir      string
+	cassette string
+	id       uint64
+}
+
+func (suite *ReplayProviderTestSuite) send(provider Provider, method string, params ...interface{}) (rpc.Response, error) {
+	suite.id++
+	return provider.Send(&rpc.JSONRPCRequest{Version: "2.0", Method: method, Params: params, Identifier: rpc.NumberID(suite.id)})
+}
+
+func (suite *ReplayProviderTestSuite) replay(options ...ReplayOption) *ReplayProvider {
+	provider, err := NewReplayProvider(suite.cassette, options...)
+	assert.NoError(suite.T(), err, "Should be no error")
+	return provider.(*ReplayProvider)
+}
+
+func (suite *ReplayProviderTestSuite) Test_Replay() {
+	provider := suite.replay()
+	assert.Equal(suite.T(), 5, provider.Remaining(), "Should be equal")
+
+	// Out of order, with differently cased hex.
+	resp, err := suite.send(provider, "eth_getBalance", "0xABCD", "latest")
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.EqualValues(suite.T(), rpc.NumberID(suite.id), resp.ID(), "Should answer the request")
+	assert.EqualValues(suite.T(), "0x64", resp.Get("result"), "Should be equal")
+
+	resp, err = suite.send(provider, "eth_getBlockByHash", "0x1", true)
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.EqualValues(suite.T(), "0x10", resp.Get("result").(map[string]interface{})["number"], "Should be equal")
+
+	resp, err = suite.send(provider, "eth_chainId")
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.EqualValues(suite.T(), "0x1", resp.Get("result"), "Should be equal")
+
+	resp, err = suite.send(provider, "eth_mining")
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.EqualError(suite.T(), resp.Error(), "method not found", "Should be equal")
+	assert.EqualValues(suite.T(), -32601, resp.Error().(*rpc.JSONRPCError).Code, "Should be equal")
+
+	_, err = suite.send(provider, "eth_syncing")
+	assert.EqualError(suite.T(), err, "connection refused", "Should be equal")
+
+	// Every response is served once.
+	_, err = suite.send(provider, "eth_chainId")
+	assert.Equal(suite.T(), ErrNoRecording, err, "Should be equal")
+	_, err = suite.send(provider, "eth_getBalance", "0xabcd", "0x1")
+	assert.Equal(suite.T(), ErrNoRecording, err, "Should be equal")
+	assert.Equal(suite.T(), 0, provider.Remaining(), "Should be equal")
+}
+
+func (suite *ReplayProviderTestSuite) Test_Strict() {
+	provider := suite.replay(WithStrictness(ReplayStrict))
+
+	_, err := suite.send(provider, "eth_getBalance", "0xabcd", "latest")
+	assert.Equal(suite.T(), ErrNoRecording, err, "Should be equal")
+
+	_, err = suite.send(provider, "eth_chainId")
+	assert.NoError(suite.T(), err, "Should be no error")
+	_, err = suite.send(provider, "eth_getBalance", "0xabcd", "latest")
+	assert.NoError(suite.T(), err, "Should be no error")
+}
+
+func (suite *ReplayProviderTestSuite) Test_Loose() {
+	provider := suite.replay(WithStrictness(ReplayLoose))
+	for i := 0; i < 3; i++ {
+		resp, err := suite.send(provider, "eth_chainId")
+		assert.NoError(suite.T(), err, "Should be no error")
+		assert.EqualValues(suite.T(), "0x1", resp.Get("result"), "Should be equal")
+	}
+}
+
+func (suite *ReplayProviderTestSuite) Test_ContextCanceled() {
+	recorder, err := NewRecordingProvider(&countingProvider{calls: make(map[string]int)}, suite.cassette)
+	assert.NoError(suite.T(), err, "Should be no error")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	recorder.SendContext(ctx, recorder.GetRPCMethod().NewRequest("eth_chainId"))
+	recorder.(*RecordingProvider).Close()
+
+	assert.Equal(suite.T(), 0, suite.replay().Remaining(), "Should be equal")
+}
+
+func (suite *ReplayProviderTestSuite) SetupTest() {
+	var err error
+	suite.dir, err = ioutil.TempDir("", "web3go")
+	assert.NoError(suite.T(), err, "Should be no error")
+	suite.cassette = filepath.Join(suite.dir, "cassette.jsonl")
+
+	inner := &countingProvider{
+		results: map[string]string{
+			"eth_chainId":        `"0x1"`,
+			"eth_getBalance":     `"0x64"`,
+			"eth_getBlockByHash": `{"number":"0x10","hash":"0x1"}`,
+		},
+		failures: map[string]error{"eth_syncing": errors.New("connection refused")},
+		calls:    make(map[string]int),
+	}
+	recorder, err := NewRecordingProvider(inner, suite.cassette)
+	assert.NoError(suite.T(), err, "Should be no error")
+
+	suite.send(recorder, "eth_chainId")
+	suite.send(recorder, "eth_getBalance", "0xabcd", "latest")
+	suite.send(recorder, "eth_getBlockByHash", "0x1", true)
+	suite.send(recorder, "eth_mining")
+	suite.send(recorder, "eth_syncing")
+	assert.NoError(suite.T(), recorder.(*RecordingProvider).Close(), "Should be no error")
+}
+
+func (suite *ReplayProviderTestSuite) TearDownTest() {
+	os.RemoveAll(suite.dir)
+}
+
+func Test_ReplayProviderTestSuite(t *testing.T) {
+	suite.Run(t, new(ReplayProviderTestSuite))
+}
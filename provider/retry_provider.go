@@ -0,0 +1,255 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/yangyuan6/web3go/rpc"
+)
+
+// retryableErrorCodes are JSON-RPC error codes for failures that may go away
+// by themselves.
+var retryableErrorCodes = map[int64]bool{
+	rpc.CodeLimitExceeded: true,
+}
+
+// RetryConfig tunes a RetryProvider.
+type RetryConfig struct {
+	// MaxAttempts is the number of times a request is sent at most,
+	// including the first one.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry. It is multiplied by
+	// Multiplier after every retry, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// Jitter randomizes each wait by up to this fraction in either direction.
+	Jitter float64
+	// RetryNonIdempotent allows retrying methods such as
+	// eth_sendRawTransaction, see IsIdempotent.
+	RetryNonIdempotent bool
+	// Retryable classifies failures, either a transport error or the error
+	// of a JSON-RPC response. Nil means IsRetryable.
+	Retryable func(err error) bool
+}
+
+// DefaultRetryConfig is a sensible RetryConfig for public nodes.
+var DefaultRetryConfig = RetryConfig{
+	MaxAttempts:    5,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// RetryProvider retries failed requests of the provider it wraps with
+// exponential backoff. A Retry-After header sent along with an HTTPError takes
+// precedence over the computed backoff, still capped at MaxBackoff.
+type RetryProvider struct {
+	provider Provider
+	config   RetryConfig
+}
+
+// NewRetryProvider wraps provider so that failed requests are retried.
+func NewRetryProvider(provider Provider, config RetryConfig) Provider {
+	if config.Retryable == nil {
+		config.Retryable = IsRetryable
+	}
+	return &RetryProvider{provider: provider, config: config}
+}
+
+// IsRetryable reports whether err, returned by a provider or by a JSON-RPC
+// response, is worth retrying: network errors, connections lost or closed
+// early, 408, 429 and 5xx statuses, and JSON-RPC errors telling that a limit
+// was exceeded. Any other error is not.
+func IsRetryable(err error) bool {
+	if err == nil || err == context.Canceled || err == context.DeadlineExceeded || err == ErrProviderClosed {
+		return false
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		switch {
+		case httpErr.StatusCode == http.StatusRequestTimeout, httpErr.StatusCode == http.StatusTooManyRequests:
+			return true
+		case httpErr.StatusCode == http.StatusNotImplemented:
+			return false
+		default:
+			return httpErr.StatusCode >= 500
+		}
+	}
+
+	if code, ok := rpc.ErrorCode(err); ok {
+		return retryableErrorCodes[code]
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, ErrConnectionLost) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// IsConnected ...
+func (provider *RetryProvider) IsConnected() bool {
+	return provider.provider.IsConnected()
+}
+
+// Send sends the request, retrying it while it fails with a retryable error.
+func (provider *RetryProvider) Send(request rpc.Request) (rpc.Response, error) {
+	return provider.SendContext(context.Background(), request)
+}
+
+// SendContext is like Send but takes a context, which also bounds the waits
+// between attempts.
+func (provider *RetryProvider) SendContext(ctx context.Context, request rpc.Request) (rpc.Response, error) {
+	method, _ := request.Get("method").(string)
+	retry := provider.config.RetryNonIdempotent || IsIdempotent(method)
+
+	for attempt := 1; ; attempt++ {
+		resp, err := provider.provider.SendContext(ctx, request)
+		failure := err
+		if failure == nil && resp != nil {
+			failure = resp.Error()
+		}
+		if failure == nil || !retry || attempt >= provider.config.MaxAttempts || !provider.config.Retryable(failure) {
+			return resp, err
+		}
+
+		if err := provider.wait(ctx, attempt, failure); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// SendBatch sends the requests in one round trip if the wrapped provider
+// supports it, retrying the whole batch while it gets no response because of
+// a retryable error. Batches holding a method that is not idempotent are only
+// retried with RetryNonIdempotent. Errors of single responses are not retried.
+func (provider *RetryProvider) SendBatch(requests []rpc.Request) ([]rpc.Response, error) {
+	return provider.SendBatchContext(context.Background(), requests)
+}
+
+// SendBatchContext is like SendBatch but takes a context, which also bounds
+// the waits between attempts.
+func (provider *RetryProvider) SendBatchContext(ctx context.Context, requests []rpc.Request) ([]rpc.Response, error) {
+	retry := provider.config.RetryNonIdempotent
+	if !retry {
+		retry = true
+		for _, request := range requests {
+			method, _ := request.Get("method").(string)
+			retry = retry && IsIdempotent(method)
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		responses, err := SendBatch(ctx, provider.provider, requests)
+		if err == nil || !retry || attempt >= provider.config.MaxAttempts || !provider.config.Retryable(err) {
+			return responses, err
+		}
+		if err := provider.wait(ctx, attempt, err); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Subscribe subscribes through the wrapped provider, without retrying.
+func (provider *RetryProvider) Subscribe(ctx context.Context, request rpc.Request, handler NotificationHandler) (rpc.Response, error) {
+	return Subscribe(ctx, provider.provider, request, handler)
+}
+
+// Unsubscribe ...
+func (provider *RetryProvider) Unsubscribe(subscriptionID string) {
+	Unsubscribe(provider.provider, subscriptionID)
+}
+
+// GetRPCMethod ...
+func (provider *RetryProvider) GetRPCMethod() rpc.RPC {
+	return provider.provider.GetRPCMethod()
+}
+
+// wait sleeps for the backoff after the given failed attempt, or until ctx is
+// done.
+func (provider *RetryProvider) wait(ctx context.Context, attempt int, err error) error {
+	timer := time.NewTimer(provider.backoff(attempt, err))
+	select {
+	case <-ctx.Done():
+		timer.Stop()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// backoff returns how long to wait after the given failed attempt.
+func (provider *RetryProvider) backoff(attempt int, err error) time.Duration {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		if wait, ok := retryAfter(httpErr.Header.Get("Retry-After")); ok {
+			if max := provider.config.MaxBackoff; max > 0 && wait > max {
+				wait = max
+			}
+			return wait
+		}
+	}
+
+	wait := float64(provider.config.InitialBackoff) * math.Pow(provider.config.Multiplier, float64(attempt-1))
+	if max := float64(provider.config.MaxBackoff); max > 0 && wait > max {
+		wait = max
+	}
+	wait *= 1 + provider.config.Jitter*(2*rand.Float64()-1)
+	return time.Duration(wait)
+}
+
+// retryAfter parses a Retry-After header, given either in seconds or as a date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
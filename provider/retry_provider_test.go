@@ -0,0 +1,221 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/yangyuan6/web3go/rpc"
+)
+
+// Transport errors as returned by net/http.
+var (
+	errConnectionRefused = &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	errConnectionReset   = &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+)
+
+// scriptedProvider fails with the given errors, in order, then succeeds. A
+// *rpc.JSONRPCError is answered as a JSON-RPC error response.
+type scriptedProvider struct {
+	mu       sync.Mutex
+	failures []error
+	attempts int
+}
+
+func (p *scriptedProvider) attemptCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.attempts
+}
+
+func (p *scriptedProvider) IsConnected() bool {
+	return true
+}
+
+func (p *scriptedProvider) Send(request rpc.Request) (rpc.Response, error) {
+	return p.SendContext(context.Background(), request)
+}
+
+func (p *scriptedProvider) SendContext(ctx context.Context, request rpc.Request) (rpc.Response, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.attempts++
+	if len(p.failures) == 0 {
+		return p.GetRPCMethod().NewResponse([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":"ok"}`, request.ID()))), nil
+	}
+
+	err := p.failures[0]
+	p.failures = p.failures[1:]
+	if rpcErr, ok := err.(*rpc.JSONRPCError); ok {
+		body := fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"error":{"code":%d,"message":%q}}`, request.ID(), rpcErr.Code, rpcErr.Message)
+		return p.GetRPCMethod().NewResponse([]byte(body)), nil
+	}
+	return nil, err
+}
+
+func (p *scriptedProvider) GetRPCMethod() rpc.RPC {
+	return rpc.GetDefaultMethod()
+}
+
+type RetryProviderTestSuite struct {
+	suite.Suite
+	config RetryConfig
+}
+
+func (suite *RetryProviderTestSuite) send(failures []error, method string) (rpc.Response, error, int) {
+	inner := &scriptedProvider{failures: failures}
+	provider := NewRetryProvider(inner, suite.config)
+	resp, err := provider.Send(provider.GetRPCMethod().NewRequest(method))
+	return resp, err, inner.attemptCount()
+}
+
+func (suite *RetryProviderTestSuite) Test_RetryTransportErrors() {
+	suite.config.MaxAttempts = 4
+	resp, err, attempts := suite.send([]error{
+		errConnectionReset,
+		&HTTPError{StatusCode: http.StatusServiceUnavailable, Status: "503 Service Unavailable"},
+		&HTTPError{StatusCode: http.StatusTooManyRequests, Status: "429 Too Many Requests"},
+	}, "eth_blockNumber")
+
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.Equal(suite.T(), "ok", resp.Get("result"), "Should be equal")
+	assert.Equal(suite.T(), 4, attempts, "Should be equal")
+}
+
+func (suite *RetryProviderTestSuite) Test_GiveUp() {
+	_, err, attempts := suite.send([]error{
+		errConnectionRefused,
+		errConnectionRefused,
+		errConnectionRefused,
+		errConnectionRefused,
+	}, "eth_blockNumber")
+
+	assert.Equal(suite.T(), errConnectionRefused, err, "Should be equal")
+	assert.Equal(suite.T(), 3, attempts, "Should be equal")
+}
+
+func (suite *RetryProviderTestSuite) Test_JSONRPCErrors() {
+	resp, err, attempts := suite.send([]error{&rpc.JSONRPCError{Code: -32005, Message: "limit exceeded"}}, "eth_getLogs")
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.Equal(suite.T(), "ok", resp.Get("result"), "Should be equal")
+	assert.Equal(suite.T(), 2, attempts, "Should be equal")
+
+	resp, err, attempts = suite.send([]error{&rpc.JSONRPCError{Code: -32602, Message: "invalid argument"}}, "eth_getLogs")
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.EqualError(suite.T(), resp.Error(), "invalid argument", "Should be equal")
+	assert.Equal(suite.T(), 1, attempts, "Should be equal")
+}
+
+func (suite *RetryProviderTestSuite) Test_NonIdempotent() {
+	_, err, attempts := suite.send([]error{errConnectionReset}, "eth_sendRawTransaction")
+	assert.Error(suite.T(), err, "Should be error")
+	assert.Equal(suite.T(), 1, attempts, "Should be equal")
+
+	suite.config.RetryNonIdempotent = true
+	_, err, attempts = suite.send([]error{errConnectionReset}, "eth_sendRawTransaction")
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.Equal(suite.T(), 2, attempts, "Should be equal")
+}
+
+func (suite *RetryProviderTestSuite) Test_ContextCanceled() {
+	suite.config.InitialBackoff = time.Hour
+	suite.config.MaxBackoff = time.Hour
+	inner := &scriptedProvider{failures: []error{errConnectionRefused}}
+	provider := NewRetryProvider(inner, suite.config)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := provider.SendContext(ctx, provider.GetRPCMethod().NewRequest("eth_blockNumber"))
+	assert.Equal(suite.T(), context.DeadlineExceeded, err, "Should be equal")
+	assert.Equal(suite.T(), 1, inner.attemptCount(), "Should be equal")
+}
+
+func (suite *RetryProviderTestSuite) Test_Backoff() {
+	provider := NewRetryProvider(nil, RetryConfig{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+	}).(*RetryProvider)
+
+	err := errConnectionRefused
+	assert.Equal(suite.T(), 100*time.Millisecond, provider.backoff(1, err), "Should be equal")
+	assert.Equal(suite.T(), 400*time.Millisecond, provider.backoff(3, err), "Should be equal")
+	assert.Equal(suite.T(), time.Second, provider.backoff(10, err), "Should be equal")
+
+	limited := &HTTPError{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"7"}}}
+	assert.Equal(suite.T(), time.Second, provider.backoff(1, limited), "Should be capped")
+	provider.config.MaxBackoff = time.Minute
+	assert.Equal(suite.T(), 7*time.Second, provider.backoff(1, limited), "Should be equal")
+	provider.config.MaxBackoff = time.Second
+
+	provider.config.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		wait := provider.backoff(1, err)
+		assert.True(suite.T(), wait >= 50*time.Millisecond && wait <= 150*time.Millisecond, "Should be within jitter")
+	}
+}
+
+func (suite *RetryProviderTestSuite) Test_IsRetryable() {
+	assert.True(suite.T(), IsRetryable(errConnectionReset))
+	assert.True(suite.T(), IsRetryable(io.ErrUnexpectedEOF))
+	assert.True(suite.T(), IsRetryable(ErrConnectionLost))
+	assert.True(suite.T(), IsRetryable(&HTTPError{StatusCode: http.StatusBadGateway}))
+	assert.True(suite.T(), IsRetryable(&rpc.JSONRPCError{Code: -32005}))
+	assert.False(suite.T(), IsRetryable(&HTTPError{StatusCode: http.StatusUnauthorized}))
+	assert.False(suite.T(), IsRetryable(&rpc.JSONRPCError{Code: -32601}))
+	assert.False(suite.T(), IsRetryable(context.Canceled))
+	assert.False(suite.T(), IsRetryable(ErrProviderClosed))
+	assert.False(suite.T(), IsRetryable(errors.New("Unknown error")))
+}
+
+func (suite *RetryProviderTestSuite) SetupTest() {
+	suite.config = RetryConfig{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		Multiplier:     2,
+	}
+}
+
+func Test_RetryProviderTestSuite(t *testing.T) {
+	suite.Run(t, new(RetryProviderTestSuite))
+}
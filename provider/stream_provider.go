@@ -0,0 +1,348 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/yangyuan6/web3go/rpc"
+)
+
+var (
+	// ErrProviderClosed is returned when sending through a provider that has
+	// been closed.
+	ErrProviderClosed = errors.New("Provider is closed")
+	// ErrConnectionLost is returned to pending requests when the underlying
+	// connection is dropped before their responses arrive.
+	ErrConnectionLost = errors.New("Connection lost")
+
+	errDuplicateID = errors.New("Duplicate request id")
+)
+
+// messageConn is a connection that carries whole RPC messages, one per read or
+// write.
+type messageConn interface {
+	ReadMessage() ([]byte, error)
+	WriteMessage(data []byte) error
+	Close() error
+}
+
+// streamProvider multiplexes concurrent requests over one persistent
+// connection, matching responses to requests by their identifiers. A dropped
+// connection is re-established on the next Send. It backs the WebSocket and
+// IPC providers, which only differ in how they dial.
+type streamProvider struct {
+	rpc  rpc.RPC
+	dial func(ctx context.Context) (messageConn, error)
+
+	mu     sync.Mutex
+	conn   *streamConnection
+	closed bool
+}
+
+// streamConnection is a single dialed connection together with the requests
+// that are waiting for a response on it.
+type streamConnection struct {
+	conn    messageConn
+	writeMu sync.Mutex
+
+	mu            sync.Mutex
+	pending       map[rpc.ID]*pendingCall
+	subscriptions map[string]NotificationHandler
+	err           error
+}
+
+type pendingCall struct {
+	done     chan struct{}
+	handler  NotificationHandler
+	response rpc.Response
+	err      error
+}
+
+// IsConnected ...
+func (provider *streamProvider) IsConnected() bool {
+	req := provider.rpc.NewRequest("net_listening")
+	resp, err := provider.Send(req)
+	if err != nil {
+		return false
+	}
+	result, ok := resp.Get("result").(bool)
+	return ok && result
+}
+
+// Send JSON RPC request through the persistent connection
+func (provider *streamProvider) Send(request rpc.Request) (response rpc.Response, err error) {
+	return provider.roundTrip(context.Background(), request, nil)
+}
+
+// SendContext is like Send but stops waiting for the response when ctx is
+// done. A response arriving later is discarded.
+func (provider *streamProvider) SendContext(ctx context.Context, request rpc.Request) (response rpc.Response, err error) {
+	return provider.roundTrip(ctx, request, nil)
+}
+
+// Subscribe sends a subscription request and routes the notifications of the
+// subscription it creates to handler. Subscriptions do not survive a lost
+// connection.
+func (provider *streamProvider) Subscribe(ctx context.Context, request rpc.Request, handler NotificationHandler) (rpc.Response, error) {
+	return provider.roundTrip(ctx, request, handler)
+}
+
+// Unsubscribe stops routing notifications for subscriptionID.
+func (provider *streamProvider) Unsubscribe(subscriptionID string) {
+	provider.mu.Lock()
+	conn := provider.conn
+	provider.mu.Unlock()
+
+	if conn != nil {
+		conn.mu.Lock()
+		delete(conn.subscriptions, subscriptionID)
+		conn.mu.Unlock()
+	}
+}
+
+func (provider *streamProvider) roundTrip(ctx context.Context, request rpc.Request, handler NotificationHandler) (rpc.Response, error) {
+	conn, err := provider.connection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.IsNotification() {
+		if err = conn.write(request); err != nil {
+			provider.drop(conn, err)
+		}
+		return nil, err
+	}
+
+	call, written, err := conn.call(request, handler)
+	if err == errDuplicateID {
+		return nil, err
+	}
+	if err != nil {
+		provider.drop(conn, err)
+		// A failed write may still have reached the node, so only redial and
+		// try once more if the connection was known to be dead before the
+		// write, or if sending the request twice is harmless.
+		method, _ := request.Get("method").(string)
+		if written && !IsIdempotent(method) {
+			return nil, err
+		}
+		if conn, err = provider.connection(ctx); err != nil {
+			return nil, err
+		}
+		if call, _, err = conn.call(request, handler); err != nil {
+			provider.drop(conn, err)
+			return nil, err
+		}
+	}
+
+	select {
+	case <-call.done:
+		return call.response, call.err
+	case <-ctx.Done():
+		conn.abandon(request.ID())
+		return nil, ctx.Err()
+	}
+}
+
+func (provider *streamProvider) GetRPCMethod() rpc.RPC {
+	return provider.rpc
+}
+
+// Close closes the underlying connection. Pending requests fail with
+// ErrConnectionLost and further requests with ErrProviderClosed.
+func (provider *streamProvider) Close() error {
+	provider.mu.Lock()
+	conn := provider.conn
+	provider.conn = nil
+	provider.closed = true
+	provider.mu.Unlock()
+
+	if conn != nil {
+		return conn.conn.Close()
+	}
+	return nil
+}
+
+// connection returns the current connection, dialing a new one if necessary.
+func (provider *streamProvider) connection(ctx context.Context) (*streamConnection, error) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	if provider.closed {
+		return nil, ErrProviderClosed
+	}
+	if provider.conn != nil {
+		return provider.conn, nil
+	}
+
+	c, err := provider.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conn := &streamConnection{
+		conn:          c,
+		pending:       make(map[rpc.ID]*pendingCall),
+		subscriptions: make(map[string]NotificationHandler),
+	}
+	provider.conn = conn
+	go provider.readLoop(conn)
+	return conn, nil
+}
+
+// drop forgets conn so that the next Send dials again, and fails everything
+// still waiting on it.
+func (provider *streamProvider) drop(conn *streamConnection, err error) {
+	provider.mu.Lock()
+	if provider.conn == conn {
+		provider.conn = nil
+	}
+	provider.mu.Unlock()
+
+	conn.conn.Close()
+	conn.fail(err)
+}
+
+func (provider *streamProvider) readLoop(conn *streamConnection) {
+	for {
+		data, err := conn.conn.ReadMessage()
+		if err != nil {
+			provider.drop(conn, err)
+			return
+		}
+
+		if notification := provider.rpc.NewNotification(data); notification != nil {
+			conn.notify(notification)
+			continue
+		}
+
+		response := provider.rpc.NewResponse(data)
+		if response == nil {
+			continue
+		}
+		conn.deliver(response)
+	}
+}
+
+// call registers a pending call for request and writes it to the connection.
+// written tells whether the write was attempted, so that on error the request
+// may have reached the node.
+func (conn *streamConnection) call(request rpc.Request, handler NotificationHandler) (call *pendingCall, written bool, err error) {
+	call = &pendingCall{done: make(chan struct{}), handler: handler}
+
+	conn.mu.Lock()
+	if conn.err != nil {
+		conn.mu.Unlock()
+		return nil, false, conn.err
+	}
+	if _, ok := conn.pending[request.ID()]; ok {
+		conn.mu.Unlock()
+		return nil, false, errDuplicateID
+	}
+	conn.pending[request.ID()] = call
+	conn.mu.Unlock()
+
+	if err := conn.write(request); err != nil {
+		conn.mu.Lock()
+		delete(conn.pending, request.ID())
+		conn.mu.Unlock()
+		return nil, true, err
+	}
+	return call, true, nil
+}
+
+// write writes request to the connection.
+func (conn *streamConnection) write(request rpc.Request) error {
+	conn.writeMu.Lock()
+	defer conn.writeMu.Unlock()
+	return conn.conn.WriteMessage([]byte(request.String()))
+}
+
+// abandon forgets the pending call for id, so that a late response is dropped.
+func (conn *streamConnection) abandon(id rpc.ID) {
+	conn.mu.Lock()
+	delete(conn.pending, id)
+	conn.mu.Unlock()
+}
+
+func (conn *streamConnection) deliver(response rpc.Response) {
+	conn.mu.Lock()
+	call, ok := conn.pending[response.ID()]
+	delete(conn.pending, response.ID())
+	if ok && call.handler != nil && response.Error() == nil {
+		// Register before returning to the read loop, since notifications
+		// may follow right behind the response.
+		if id, isString := response.Get("result").(string); isString {
+			conn.subscriptions[id] = call.handler
+		}
+	}
+	conn.mu.Unlock()
+
+	if ok {
+		call.response = response
+		close(call.done)
+	}
+}
+
+func (conn *streamConnection) notify(notification rpc.Notification) {
+	conn.mu.Lock()
+	handler, ok := conn.subscriptions[notification.Subscription()]
+	conn.mu.Unlock()
+
+	if ok {
+		handler(notification, nil)
+	}
+}
+
+func (conn *streamConnection) fail(err error) {
+	conn.mu.Lock()
+	if conn.err != nil {
+		conn.mu.Unlock()
+		return
+	}
+	conn.err = err
+	for id, call := range conn.pending {
+		call.err = ErrConnectionLost
+		close(call.done)
+		delete(conn.pending, id)
+	}
+	handlers := make([]NotificationHandler, 0, len(conn.subscriptions))
+	for id, handler := range conn.subscriptions {
+		handlers = append(handlers, handler)
+		delete(conn.subscriptions, id)
+	}
+	conn.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(nil, ErrConnectionLost)
+	}
+}
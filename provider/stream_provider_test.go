@@ -0,0 +1,155 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/yangyuan6/web3go/rpc"
+)
+
+// fakeStreamConn answers every request with "ok", or fails every write when
+// broken.
+type fakeStreamConn struct {
+	broken  bool
+	writes  *int
+	mu      *sync.Mutex
+	replies chan []byte
+	closed  chan struct{}
+	once    sync.Once
+}
+
+func (c *fakeStreamConn) ReadMessage() ([]byte, error) {
+	select {
+	case data := <-c.replies:
+		return data, nil
+	case <-c.closed:
+		return nil, io.EOF
+	}
+}
+
+func (c *fakeStreamConn) WriteMessage(data []byte) error {
+	c.mu.Lock()
+	*c.writes++
+	c.mu.Unlock()
+	if c.broken {
+		return errors.New("broken pipe")
+	}
+
+	var request struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(data, &request); err != nil {
+		return err
+	}
+	c.replies <- []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":"ok"}`, request.ID))
+	return nil
+}
+
+func (c *fakeStreamConn) Close() error {
+	c.once.Do(func() { close(c.closed) })
+	return nil
+}
+
+type StreamProviderTestSuite struct {
+	suite.Suite
+	mu       sync.Mutex
+	broken   bool
+	writes   int
+	provider *streamProvider
+}
+
+func (suite *StreamProviderTestSuite) send(method string) error {
+	_, err := suite.provider.Send(suite.provider.GetRPCMethod().NewRequest(method))
+	return err
+}
+
+func (suite *StreamProviderTestSuite) writeCount() int {
+	suite.mu.Lock()
+	defer suite.mu.Unlock()
+	return suite.writes
+}
+
+func (suite *StreamProviderTestSuite) Test_WriteFailed() {
+	suite.broken = true
+
+	// The node may have got part of the transaction, so it is not sent again.
+	assert.EqualError(suite.T(), suite.send("eth_sendRawTransaction"), "broken pipe", "Should be equal")
+	assert.Equal(suite.T(), 1, suite.writeCount(), "Should be equal")
+
+	assert.EqualError(suite.T(), suite.send("eth_blockNumber"), "broken pipe", "Should be equal")
+	assert.Equal(suite.T(), 3, suite.writeCount(), "Should be sent again")
+}
+
+func (suite *StreamProviderTestSuite) Test_ConnectionDead() {
+	assert.NoError(suite.T(), suite.send("eth_blockNumber"), "Should be no error")
+
+	// A connection known to be dead before the write is replaced, whatever
+	// the method.
+	conn, err := suite.provider.connection(context.Background())
+	suite.Require().NoError(err)
+	defer conn.conn.Close()
+	conn.fail(ErrConnectionLost)
+	assert.NoError(suite.T(), suite.send("eth_sendRawTransaction"), "Should be no error")
+	assert.Equal(suite.T(), 2, suite.writeCount(), "Should be equal")
+}
+
+func (suite *StreamProviderTestSuite) SetupTest() {
+	suite.broken = false
+	suite.writes = 0
+	suite.provider = &streamProvider{
+		rpc: rpc.GetDefaultMethod(),
+		dial: func(ctx context.Context) (messageConn, error) {
+			return &fakeStreamConn{
+				broken:  suite.broken,
+				writes:  &suite.writes,
+				mu:      &suite.mu,
+				replies: make(chan []byte, 1),
+				closed:  make(chan struct{}),
+			}, nil
+		},
+	}
+}
+
+func (suite *StreamProviderTestSuite) TearDownTest() {
+	suite.provider.Close()
+}
+
+func Test_StreamProviderTestSuite(t *testing.T) {
+	suite.Run(t, new(StreamProviderTestSuite))
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package provider
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/websocket"
+	"github.com/yangyuan6/web3go/rpc"
+)
+
+// WebSocketProvider keeps one persistent WebSocket connection to the node and
+// multiplexes concurrent requests over it.
+type WebSocketProvider struct {
+	streamProvider
+	url    string
+	dialer *websocket.Dialer
+	header http.Header
+}
+
+// wsConn frames messages as WebSocket text messages.
+type wsConn struct {
+	*websocket.Conn
+}
+
+// NewWebSocketProvider creates a WebSocket provider
+func NewWebSocketProvider(url string, method rpc.RPC) Provider {
+	if !strings.HasPrefix(url, "ws://") && !strings.HasPrefix(url, "wss://") {
+		url = "ws://" + url
+	}
+	if method == nil {
+		method = rpc.GetDefaultMethod()
+	}
+	provider := &WebSocketProvider{url: url, dialer: websocket.DefaultDialer}
+	provider.rpc = method
+	provider.dial = provider.dialWebSocket
+	return provider
+}
+
+func (provider *WebSocketProvider) dialWebSocket(ctx context.Context) (messageConn, error) {
+	conn, _, err := provider.dialer.DialContext(ctx, provider.url, provider.header)
+	if err != nil {
+		return nil, err
+	}
+	return wsConn{conn}, nil
+}
+
+func (c wsConn) ReadMessage() ([]byte, error) {
+	_, data, err := c.Conn.ReadMessage()
+	return data, err
+}
+
+func (c wsConn) WriteMessage(data []byte) error {
+	return c.Conn.WriteMessage(websocket.TextMessage, data)
+}
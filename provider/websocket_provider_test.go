@@ -0,0 +1,220 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/yangyuan6/web3go/rpc"
+)
+
+type WebSocketProviderTestSuite struct {
+	suite.Suite
+	server   *httptest.Server
+	provider Provider
+}
+
+func (suite *WebSocketProviderTestSuite) Test_IsConnected() {
+	provider := suite.provider
+	assert.EqualValues(suite.T(), true, provider.IsConnected(), "should be equal")
+}
+
+func (suite *WebSocketProviderTestSuite) Test_Send() {
+	provider := suite.provider
+	req := &rpc.JSONRPCRequest{
+		Version:    "2.0",
+		Method:     "test_method",
+		Params:     nil,
+		Identifier: rpc.NumberID(10)}
+	resp, err := provider.Send(req)
+
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.EqualValues(suite.T(), req.Identifier, resp.ID(), "should be equal")
+	assert.EqualValues(suite.T(), "ok", resp.Get("result").(string), "should be equal")
+}
+
+func (suite *WebSocketProviderTestSuite) Test_SendConcurrently() {
+	provider := suite.provider
+	method := provider.GetRPCMethod()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := method.NewRequest("test_echoID")
+			resp, err := provider.Send(req)
+			if assert.NoError(suite.T(), err, "Should be no error") {
+				assert.EqualValues(suite.T(), req.ID(), resp.ID(), "should be equal")
+				assert.EqualValues(suite.T(), req.ID().String(), resp.Get("result"), "should be equal")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (suite *WebSocketProviderTestSuite) Test_SendContext() {
+	provider := suite.provider
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := provider.SendContext(ctx, provider.GetRPCMethod().NewRequest("test_slow"))
+	assert.Equal(suite.T(), context.DeadlineExceeded, err, "should be equal")
+	assert.True(suite.T(), provider.IsConnected(), "should stay connected")
+}
+
+func (suite *WebSocketProviderTestSuite) Test_Reconnect() {
+	provider := suite.provider
+	method := provider.GetRPCMethod()
+
+	_, err := provider.Send(method.NewRequest("test_disconnect"))
+	assert.Equal(suite.T(), ErrConnectionLost, err, "should be equal")
+
+	resp, err := provider.Send(method.NewRequest("test_method"))
+	if assert.NoError(suite.T(), err, "Should be no error") {
+		assert.EqualValues(suite.T(), "ok", resp.Get("result").(string), "should be equal")
+	}
+}
+
+func (suite *WebSocketProviderTestSuite) Test_Subscribe() {
+	subscriber := suite.provider.(Subscriber)
+	method := suite.provider.GetRPCMethod()
+
+	results := make(chan interface{}, 3)
+	errs := make(chan error, 1)
+	resp, err := subscriber.Subscribe(context.Background(), method.NewRequest("eth_subscribe", "newHeads"), func(n rpc.Notification, err error) {
+		if err != nil {
+			errs <- err
+			return
+		}
+		results <- n.Get("result")
+	})
+	if assert.NoError(suite.T(), err, "Should be no error") {
+		assert.EqualValues(suite.T(), "0xcd0c3e8af590364c09d0fa6a1210faf5", resp.Get("result"), "should be equal")
+	}
+
+	// The node pushes notifications right behind the response; none of them
+	// may be lost.
+	for i := 1; i <= 3; i++ {
+		assert.EqualValues(suite.T(), i, <-results, "should be equal")
+	}
+
+	suite.provider.Send(method.NewRequest("test_disconnect"))
+	assert.Equal(suite.T(), ErrConnectionLost, <-errs, "should be equal")
+}
+
+func (suite *WebSocketProviderTestSuite) Test_Close() {
+	provider := suite.provider.(*WebSocketProvider)
+	assert.True(suite.T(), provider.IsConnected(), "should be connected")
+	assert.NoError(suite.T(), provider.Close(), "Should be no error")
+
+	_, err := provider.Send(provider.GetRPCMethod().NewRequest("test_method"))
+	assert.Equal(suite.T(), ErrProviderClosed, err, "should be equal")
+}
+
+func (suite *WebSocketProviderTestSuite) SetupTest() {
+	upgrader := websocket.Upgrader{}
+	suite.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var writeMu sync.Mutex
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			req := rpc.JSONRPCRequest{}
+			if err := json.Unmarshal(data, &req); err != nil {
+				return
+			}
+
+			resp := rpc.JSONRPCResponse{Version: "2.0", Identifier: req.Identifier}
+			switch req.Method {
+			case "test_disconnect":
+				return
+			case "eth_subscribe":
+				resp.Result = rawResult("0xcd0c3e8af590364c09d0fa6a1210faf5")
+				jsonBlob, _ := json.Marshal(resp)
+				writeMu.Lock()
+				conn.WriteMessage(websocket.TextMessage, jsonBlob)
+				for i := 1; i <= 3; i++ {
+					conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(
+						`{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0xcd0c3e8af590364c09d0fa6a1210faf5","result":%d}}`, i)))
+				}
+				writeMu.Unlock()
+				continue
+			case "net_listening":
+				resp.Result = rawResult(true)
+			case "test_echoID":
+				resp.Result = rawResult(req.Identifier.String())
+			case "test_slow":
+				continue
+			default:
+				resp.Result = rawResult("ok")
+			}
+
+			// Answer out of order so that the provider has to match by id.
+			go func() {
+				id, _ := strconv.Atoi(req.Identifier.String())
+				time.Sleep(time.Duration(id%5) * time.Millisecond)
+				jsonBlob, _ := json.Marshal(resp)
+				writeMu.Lock()
+				defer writeMu.Unlock()
+				conn.WriteMessage(websocket.TextMessage, jsonBlob)
+			}()
+		}
+	}))
+	suite.provider = NewWebSocketProvider(strings.TrimPrefix(suite.server.URL, "http://"), rpc.GetDefaultMethod())
+}
+
+func (suite *WebSocketProviderTestSuite) TearDownTest() {
+	suite.provider.(*WebSocketProvider).Close()
+	suite.server.Close()
+}
+
+func Test_WebSocketProviderTestSuite(t *testing.T) {
+	suite.Run(t, new(WebSocketProviderTestSuite))
+}
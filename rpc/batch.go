@@ -0,0 +1,71 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package rpc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Batch is a list of requests sent to the node in one message
+type Batch []Request
+
+// String encodes the batch as an array of its requests
+func (batch Batch) String() string {
+	requests := make([]string, 0, len(batch))
+	for _, req := range batch {
+		requests = append(requests, req.String())
+	}
+	return "[" + strings.Join(requests, ",") + "]"
+}
+
+// Match re-associates the responses to a batch with its requests. Nodes may
+// answer in any order, so the i-th returned response is the one whose id
+// matches the i-th request. Notifications get no response, so theirs is nil.
+// It fails if any other request is left unanswered.
+func (batch Batch) Match(responses []Response) ([]Response, error) {
+	byID := make(map[ID]Response, len(responses))
+	for _, resp := range responses {
+		byID[resp.ID()] = resp
+	}
+
+	matched := make([]Response, len(batch))
+	for i, req := range batch {
+		if req.IsNotification() {
+			continue
+		}
+		resp, ok := byID[req.ID()]
+		if !ok {
+			return nil, fmt.Errorf("Missing response for request %v", req.ID())
+		}
+		matched[i] = resp
+	}
+	return matched, nil
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// Error codes defined by the JSON-RPC 2.0 specification, along with those
+// commonly used by Ethereum nodes.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+
+	// CodeServerError is the generic error of Ethereum nodes. The range from
+	// -32099 to -32000 is reserved for implementation defined errors.
+	CodeServerError    = -32000
+	CodeServerErrorMin = -32099
+	CodeServerErrorMax = -32000
+
+	// CodeLimitExceeded is returned by nodes throttling their clients.
+	CodeLimitExceeded = -32005
+	// CodeExecutionReverted is returned by eth_call and eth_estimateGas when
+	// the execution reverts, the revert data being in the data member.
+	CodeExecutionReverted = 3
+)
+
+// JSONRPCError is the error member of a JSON-RPC response.
+type JSONRPCError struct {
+	Code    int64           `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (err *JSONRPCError) Error() string {
+	return err.Message
+}
+
+// DecodeData decodes the data member of the error into v. It decodes null
+// when there is none.
+func (err *JSONRPCError) DecodeData(v interface{}) error {
+	if len(err.Data) == 0 {
+		return json.Unmarshal([]byte("null"), v)
+	}
+	return json.Unmarshal(err.Data, v)
+}
+
+// ErrorCode returns the code of err if it is, or wraps, a JSONRPCError.
+func ErrorCode(err error) (int64, bool) {
+	var rpcErr *JSONRPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Code, true
+	}
+	return 0, false
+}
+
+func hasCode(err error, code int64) bool {
+	c, ok := ErrorCode(err)
+	return ok && c == code
+}
+
+// IsParseError tells whether err is a JSON-RPC parse error.
+func IsParseError(err error) bool {
+	return hasCode(err, CodeParseError)
+}
+
+// IsInvalidRequest tells whether err is a JSON-RPC invalid request error.
+func IsInvalidRequest(err error) bool {
+	return hasCode(err, CodeInvalidRequest)
+}
+
+// IsMethodNotFound tells whether err is a JSON-RPC method not found error.
+func IsMethodNotFound(err error) bool {
+	return hasCode(err, CodeMethodNotFound)
+}
+
+// IsInvalidParams tells whether err is a JSON-RPC invalid params error.
+func IsInvalidParams(err error) bool {
+	return hasCode(err, CodeInvalidParams)
+}
+
+// IsInternalError tells whether err is a JSON-RPC internal error.
+func IsInternalError(err error) bool {
+	return hasCode(err, CodeInternalError)
+}
+
+// IsServerError tells whether err is an implementation defined JSON-RPC error.
+func IsServerError(err error) bool {
+	code, ok := ErrorCode(err)
+	return ok && code >= CodeServerErrorMin && code <= CodeServerErrorMax
+}
+
+// IsLimitExceeded tells whether err reports that the node throttles us.
+func IsLimitExceeded(err error) bool {
+	return hasCode(err, CodeLimitExceeded)
+}
+
+// IsExecutionReverted tells whether err reports a reverted execution.
+func IsExecutionReverted(err error) bool {
+	return hasCode(err, CodeExecutionReverted)
+}
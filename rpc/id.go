@@ -0,0 +1,121 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+// ID identifies a request and the response answering it. It holds the JSON
+// encoding of the id, such as 1 or "abc", so that ids of any type compare and
+// serve as map keys. The zero ID means the message has no id, as is the case
+// for notifications; NullID is the null id of responses to unreadable
+// requests.
+type ID string
+
+// NullID is the id of a response to a request whose id could not be read.
+const NullID ID = "null"
+
+// NumberID returns the ID for a number.
+func NumberID(n uint64) ID {
+	return ID(strconv.FormatUint(n, 10))
+}
+
+// StringID returns the ID for a string.
+func StringID(s string) ID {
+	data, _ := json.Marshal(s)
+	return ID(data)
+}
+
+// NewID returns the ID for value, which is an ID, a string, an integer or nil
+// for no id.
+func NewID(value interface{}) (ID, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case ID:
+		return v, nil
+	case string:
+		return StringID(v), nil
+	case int:
+		return ID(strconv.FormatInt(int64(v), 10)), nil
+	case int64:
+		return ID(strconv.FormatInt(v, 10)), nil
+	case uint:
+		return NumberID(uint64(v)), nil
+	case uint64:
+		return NumberID(v), nil
+	}
+	return "", errors.New("Invalid id, must be a string, a number or null")
+}
+
+// String returns the JSON encoding of the id.
+func (id ID) String() string {
+	return string(id)
+}
+
+// IsZero tells whether there is no id at all.
+func (id ID) IsZero() bool {
+	return id == ""
+}
+
+// MarshalJSON encodes the id, the zero ID as null.
+func (id ID) MarshalJSON() ([]byte, error) {
+	if id == "" {
+		return []byte("null"), nil
+	}
+	return []byte(id), nil
+}
+
+// UnmarshalJSON decodes a string, number or null id. Strings are put in
+// canonical form, so that "\u0061" and "a" are the same id.
+func (id *ID) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case nil:
+		*id = NullID
+	case string:
+		*id = StringID(v)
+	case json.Number:
+		*id = ID(v)
+	default:
+		return errors.New("Invalid id, must be a string, a number or null")
+	}
+	return nil
+}
@@ -30,7 +30,9 @@
 package rpc
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"reflect"
@@ -48,7 +50,7 @@ type JSONRPCRequest struct {
 	Version    string        `json:"jsonrpc,omitempty"`
 	Method     string        `json:"method"`
 	Params     []interface{} `json:"params"`
-	Identifier uint64        `json:"id"`
+	Identifier ID            `json:"id,omitempty"`
 }
 
 // Set ...
@@ -57,20 +59,40 @@ func (req *JSONRPCRequest) Set(key string, value interface{}) {
 	switch k {
 	case "method":
 		req.Method = fmt.Sprintf("%v", value)
+	case "id":
+		if id, err := NewID(value); err == nil {
+			req.Identifier = id
+		}
 	case "params":
-		req.Params = req.Params[:0]
+		req.Params = make([]interface{}, 0)
+		if value == nil {
+			return
+		}
+		switch value.(type) {
+		case ParamMarshaler, json.Marshaler, []byte:
+			req.Params = append(req.Params, paramValue(value))
+			return
+		}
 		switch reflect.TypeOf(value).Kind() {
 		case reflect.Slice, reflect.Array:
 			v := reflect.ValueOf(value)
 			for i := 0; i < v.Len(); i++ {
-				req.Params = append(req.Params, v.Index(i).String())
+				req.Params = append(req.Params, paramValue(v.Index(i).Interface()))
 			}
 		default:
-			req.Params = append(req.Params, value)
+			req.Params = append(req.Params, paramValue(value))
 		}
 	}
 }
 
+// paramValue returns what to encode for a param.
+func paramValue(value interface{}) interface{} {
+	if marshaler, ok := value.(ParamMarshaler); ok {
+		return marshaler.MarshalParam()
+	}
+	return value
+}
+
 // Get ...
 func (req *JSONRPCRequest) Get(key string) interface{} {
 	k := strings.ToLower(key)
@@ -95,28 +117,24 @@ func (req *JSONRPCRequest) String() string {
 }
 
 // ID ...
-func (req *JSONRPCRequest) ID() uint64 {
+func (req *JSONRPCRequest) ID() ID {
 	return req.Identifier
 }
 
-// -----------------------------------------------------------------------------
-
-// JSONRPCError ...
-type JSONRPCError struct {
-	Code    int64  `json:"code"`
-	Message string `json:"message"`
+// IsNotification tells whether the request has no id, so that no response is
+// expected.
+func (req *JSONRPCRequest) IsNotification() bool {
+	return req.Identifier.IsZero()
 }
 
-func (err *JSONRPCError) Error() string {
-	return err.Message
-}
+// -----------------------------------------------------------------------------
 
 // JSONRPCResponse ...
 type JSONRPCResponse struct {
-	Version    string        `json:"jsonrpc"`
-	Identifier uint64        `json:"id"`
-	Result     interface{}   `json:"result"`
-	Err        *JSONRPCError `json:"error,omitempty"`
+	Version    string          `json:"jsonrpc"`
+	Identifier ID              `json:"id"`
+	Result     json.RawMessage `json:"result,omitempty"`
+	Err        *JSONRPCError   `json:"error,omitempty"`
 }
 
 // Get ...
@@ -128,7 +146,11 @@ func (resp *JSONRPCResponse) Get(key string) interface{} {
 	case "id":
 		return resp.Identifier
 	case "result":
-		return resp.Result
+		var result interface{}
+		if len(resp.Result) > 0 {
+			json.Unmarshal(resp.Result, &result)
+		}
+		return result
 	case "error":
 		return resp.Err
 	}
@@ -136,6 +158,18 @@ func (resp *JSONRPCResponse) Get(key string) interface{} {
 	return nil
 }
 
+// DecodeResult decodes the result into v with encoding/json. A missing result
+// decodes as null.
+func (resp *JSONRPCResponse) DecodeResult(v interface{}) error {
+	if err := resp.Error(); err != nil {
+		return err
+	}
+	if len(resp.Result) == 0 {
+		return json.Unmarshal([]byte("null"), v)
+	}
+	return json.Unmarshal(resp.Result, v)
+}
+
 // String ...
 func (resp *JSONRPCResponse) String() string {
 	jsonBytes, _ := json.Marshal(resp)
@@ -143,7 +177,7 @@ func (resp *JSONRPCResponse) String() string {
 }
 
 // ID ...
-func (resp *JSONRPCResponse) ID() uint64 {
+func (resp *JSONRPCResponse) ID() ID {
 	return resp.Identifier
 }
 
@@ -156,6 +190,50 @@ func (resp *JSONRPCResponse) Error() error {
 
 // -----------------------------------------------------------------------------
 
+// JSONRPCNotificationParams ...
+type JSONRPCNotificationParams struct {
+	Subscription string      `json:"subscription"`
+	Result       interface{} `json:"result"`
+}
+
+// JSONRPCNotification is a message sent by the server without being asked
+// for, e.g. an eth_subscription event. It carries a method but no id.
+type JSONRPCNotification struct {
+	Version string                    `json:"jsonrpc"`
+	Method  string                    `json:"method"`
+	Params  JSONRPCNotificationParams `json:"params"`
+}
+
+// Get ...
+func (n *JSONRPCNotification) Get(key string) interface{} {
+	k := strings.ToLower(key)
+	switch k {
+	case "version":
+		return n.Version
+	case "method":
+		return n.Method
+	case "subscription":
+		return n.Params.Subscription
+	case "result":
+		return n.Params.Result
+	}
+
+	return nil
+}
+
+// String ...
+func (n *JSONRPCNotification) String() string {
+	jsonBytes, _ := json.Marshal(n)
+	return string(jsonBytes)
+}
+
+// Subscription returns the id of the subscription the notification belongs to.
+func (n *JSONRPCNotification) Subscription() string {
+	return n.Params.Subscription
+}
+
+// -----------------------------------------------------------------------------
+
 // JSONRPC ...
 type JSONRPC struct {
 	messageID uint64
@@ -173,10 +251,18 @@ func (rpc *JSONRPC) Name() string {
 
 // NewRequest ...
 func (rpc *JSONRPC) NewRequest(method string, args ...interface{}) Request {
-	request := &JSONRPCRequest{Version: version, Method: method, Identifier: rpc.newID()}
+	request := rpc.NewNotificationRequest(method, args...).(*JSONRPCRequest)
+	request.Identifier = NumberID(rpc.newID())
+	return request
+}
+
+// NewNotificationRequest returns a request without id, which the node does not
+// answer.
+func (rpc *JSONRPC) NewNotificationRequest(method string, args ...interface{}) Request {
+	request := &JSONRPCRequest{Version: version, Method: method}
 	request.Params = make([]interface{}, 0)
 	for _, arg := range args {
-		request.Params = append(request.Params, fmt.Sprintf("%v", arg))
+		request.Params = append(request.Params, paramValue(arg))
 	}
 	return request
 }
@@ -191,6 +277,128 @@ func (rpc *JSONRPC) NewResponse(data []byte) Response {
 	return nil
 }
 
+// NewBatchResponse decodes the responses to a batch. It returns nil unless
+// data is an array of responses.
+func (rpc *JSONRPC) NewBatchResponse(data []byte) []Response {
+	return newBatchResponse(data, rpc.NewResponse)
+}
+
+// newBatchResponse decodes the array of responses in data with newResponse.
+func newBatchResponse(data []byte, newResponse func([]byte) Response) []Response {
+	var messages []json.RawMessage
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return nil
+	}
+
+	responses := make([]Response, 0, len(messages))
+	for _, message := range messages {
+		resp := newResponse(message)
+		if resp == nil {
+			return nil
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+// NewNotification returns nil unless data is a notification, i.e. a message
+// with a method and without an id.
+func (rpc *JSONRPC) NewNotification(data []byte) Notification {
+	probe := struct {
+		ID     *json.RawMessage `json:"id"`
+		Method string           `json:"method"`
+	}{}
+	if err := json.Unmarshal(data, &probe); err != nil || probe.ID != nil || probe.Method == "" {
+		return nil
+	}
+
+	n := &JSONRPCNotification{}
+	if err := json.Unmarshal(data, n); err == nil {
+		return n
+	}
+
+	return nil
+}
+
+// NewReply returns the response answering request. result is encoded with
+// encoding/json unless it already is a json.RawMessage. Errors other than
+// JSONRPCError are reported as internal errors.
+func (rpc *JSONRPC) NewReply(request Request, result interface{}, err error) Response {
+	return newReply(request, result, err)
+}
+
+// ContentType ...
+func (rpc *JSONRPC) ContentType() string {
+	return "application/json"
+}
+
+// DecodeRequest decodes a request, which must have the jsonrpc member set to
+// 2.0.
+func (rpc *JSONRPC) DecodeRequest(data []byte) (Request, error) {
+	request, err := decodeRequest(data)
+	if err == nil && request.Version != version {
+		err = &JSONRPCError{Code: CodeInvalidRequest, Message: "Invalid request"}
+	}
+	if request == nil {
+		return nil, err
+	}
+	return request, err
+}
+
+// decodeRequest decodes the members of a request, keeping its params as raw
+// JSON values.
+func decodeRequest(data []byte) (*JSONRPCRequest, error) {
+	var message struct {
+		Version string          `json:"jsonrpc"`
+		Method  string          `json:"method"`
+		Params  json.RawMessage `json:"params"`
+		ID      ID              `json:"id"`
+	}
+	if err := json.Unmarshal(data, &message); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) || len(bytes.TrimSpace(data)) == 0 {
+			return nil, &JSONRPCError{Code: CodeParseError, Message: "Parse error"}
+		}
+		return nil, &JSONRPCError{Code: CodeInvalidRequest, Message: "Invalid request"}
+	}
+
+	request := &JSONRPCRequest{Version: message.Version, Method: message.Method, Identifier: message.ID}
+	if message.Method == "" {
+		return request, &JSONRPCError{Code: CodeInvalidRequest, Message: "Invalid request"}
+	}
+	if len(message.Params) > 0 && string(message.Params) != "null" {
+		var params []json.RawMessage
+		if err := json.Unmarshal(message.Params, &params); err != nil {
+			return request, &JSONRPCError{Code: CodeInvalidParams, Message: "Params must be an array"}
+		}
+		request.Params = make([]interface{}, 0, len(params))
+		for _, param := range params {
+			request.Params = append(request.Params, param)
+		}
+	}
+	return request, nil
+}
+
+func newReply(request Request, result interface{}, err error) *JSONRPCResponse {
+	resp := &JSONRPCResponse{Version: version, Identifier: request.ID()}
+	if err == nil {
+		if raw, ok := result.(json.RawMessage); ok {
+			resp.Result = raw
+			return resp
+		}
+		if resp.Result, err = json.Marshal(result); err == nil {
+			return resp
+		}
+	}
+
+	if rpcErr, ok := err.(*JSONRPCError); ok {
+		resp.Err = rpcErr
+	} else {
+		resp.Err = &JSONRPCError{Code: CodeInternalError, Message: err.Error()}
+	}
+	return resp
+}
+
 func (rpc *JSONRPC) newID() uint64 {
 	return atomic.AddUint64(&rpc.messageID, 1)
 }
@@ -0,0 +1,219 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package rpc
+
+import (
+	"encoding/json"
+	"sync/atomic"
+)
+
+// JSONRPC1Request is a JSON-RPC 1.0 request. It has no version member, and
+// notifications carry a null id.
+type JSONRPC1Request struct {
+	JSONRPCRequest
+}
+
+// MarshalJSON ...
+func (req *JSONRPC1Request) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Method string        `json:"method"`
+		Params []interface{} `json:"params"`
+		ID     ID            `json:"id"`
+	}{req.Method, req.Params, req.Identifier})
+}
+
+// UnmarshalJSON ...
+func (req *JSONRPC1Request) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &req.JSONRPCRequest); err != nil {
+		return err
+	}
+	if req.Identifier == NullID {
+		req.Identifier = ""
+	}
+	return nil
+}
+
+// String ...
+func (req *JSONRPC1Request) String() string {
+	jsonBytes, _ := json.Marshal(req)
+	return string(jsonBytes)
+}
+
+// JSONRPC1Response is a JSON-RPC 1.0 response, whose error is null on success
+// and result null on failure. Errors without a code, such as plain strings,
+// are reported as server errors holding the error in Data.
+type JSONRPC1Response struct {
+	JSONRPCResponse
+}
+
+// MarshalJSON ...
+func (resp *JSONRPC1Response) MarshalJSON() ([]byte, error) {
+	message := struct {
+		Result json.RawMessage `json:"result"`
+		Error  *JSONRPCError   `json:"error"`
+		ID     ID              `json:"id"`
+	}{resp.Result, resp.Err, resp.Identifier}
+	if message.Result == nil {
+		message.Result = json.RawMessage("null")
+	}
+	return json.Marshal(&message)
+}
+
+// UnmarshalJSON ...
+func (resp *JSONRPC1Response) UnmarshalJSON(data []byte) error {
+	var message struct {
+		Result json.RawMessage `json:"result"`
+		Error  json.RawMessage `json:"error"`
+		ID     ID              `json:"id"`
+	}
+	if err := json.Unmarshal(data, &message); err != nil {
+		return err
+	}
+
+	resp.Identifier = message.ID
+	resp.Result = message.Result
+	resp.Err = nil
+	if len(message.Error) == 0 || string(message.Error) == "null" {
+		return nil
+	}
+
+	rpcErr := &JSONRPCError{}
+	if err := json.Unmarshal(message.Error, rpcErr); err != nil || rpcErr.Code == 0 {
+		text := rpcErr.Message
+		if err := json.Unmarshal(message.Error, &text); err != nil && text == "" {
+			text = string(message.Error)
+		}
+		rpcErr = &JSONRPCError{Code: CodeServerError, Message: text, Data: message.Error}
+	}
+	resp.Err = rpcErr
+	return nil
+}
+
+// String ...
+func (resp *JSONRPC1Response) String() string {
+	jsonBytes, _ := json.Marshal(resp)
+	return string(jsonBytes)
+}
+
+// -----------------------------------------------------------------------------
+
+// JSONRPC1 speaks JSON-RPC 1.0, for the servers predating 2.0. Its messages
+// lack the jsonrpc member, and it has no batches, though arrays of responses
+// are decoded should a server send them.
+type JSONRPC1 struct {
+	messageID uint64
+}
+
+// NewJSONRPC1 ...
+func NewJSONRPC1() RPC {
+	return &JSONRPC1{messageID: 0}
+}
+
+// Name ...
+func (rpc *JSONRPC1) Name() string {
+	return "jsonrpc1"
+}
+
+// ContentType ...
+func (rpc *JSONRPC1) ContentType() string {
+	return "application/json"
+}
+
+// NewRequest ...
+func (rpc *JSONRPC1) NewRequest(method string, args ...interface{}) Request {
+	request := rpc.NewNotificationRequest(method, args...).(*JSONRPC1Request)
+	request.Identifier = NumberID(atomic.AddUint64(&rpc.messageID, 1))
+	return request
+}
+
+// NewNotificationRequest returns a request with a null id, which the server
+// does not answer.
+func (rpc *JSONRPC1) NewNotificationRequest(method string, args ...interface{}) Request {
+	request := &JSONRPC1Request{JSONRPCRequest{Method: method}}
+	request.Params = make([]interface{}, 0)
+	for _, arg := range args {
+		request.Params = append(request.Params, paramValue(arg))
+	}
+	return request
+}
+
+// NewResponse ...
+func (rpc *JSONRPC1) NewResponse(data []byte) Response {
+	resp := &JSONRPC1Response{}
+	if err := json.Unmarshal(data, resp); err == nil {
+		return resp
+	}
+
+	return nil
+}
+
+// NewBatchResponse decodes an array of responses.
+func (rpc *JSONRPC1) NewBatchResponse(data []byte) []Response {
+	return newBatchResponse(data, rpc.NewResponse)
+}
+
+// NewNotification returns nil unless data is a notification, i.e. a message
+// with a method and a null or missing id.
+func (rpc *JSONRPC1) NewNotification(data []byte) Notification {
+	probe := struct {
+		ID     ID     `json:"id"`
+		Method string `json:"method"`
+	}{}
+	if err := json.Unmarshal(data, &probe); err != nil || (probe.ID != "" && probe.ID != NullID) || probe.Method == "" {
+		return nil
+	}
+
+	n := &JSONRPCNotification{}
+	if err := json.Unmarshal(data, n); err == nil {
+		return n
+	}
+
+	return nil
+}
+
+// DecodeRequest decodes a request. A null id makes it a notification.
+func (rpc *JSONRPC1) DecodeRequest(data []byte) (Request, error) {
+	request, err := decodeRequest(data)
+	if request == nil {
+		return nil, err
+	}
+	if request.Identifier == NullID {
+		request.Identifier = ""
+	}
+	return &JSONRPC1Request{*request}, err
+}
+
+// NewReply returns the response answering request, mapping errors as
+// JSONRPC.NewReply does.
+func (rpc *JSONRPC1) NewReply(request Request, result interface{}, err error) Response {
+	resp := newReply(request, result, err)
+	resp.Version = ""
+	return &JSONRPC1Response{*resp}
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package rpc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type JSONRPC1TestSuite struct {
+	suite.Suite
+	rpc RPC
+}
+
+func (suite *JSONRPC1TestSuite) Test_NewRequest() {
+	rpc := suite.rpc
+	req := rpc.NewRequest("test", "arg1", hexParam(10))
+	assert.EqualValues(suite.T(), `{"method":"test","params":["arg1","0xa"],"id":1}`, req.String(), "Should be equal")
+	assert.False(suite.T(), req.IsNotification(), "Should not be a notification")
+
+	req = rpc.NewNotificationRequest("test")
+	assert.EqualValues(suite.T(), `{"method":"test","params":[],"id":null}`, req.String(), "Should be equal")
+	assert.True(suite.T(), req.IsNotification(), "Should be a notification")
+
+	decoded := &JSONRPC1Request{}
+	assert.NoError(suite.T(), json.Unmarshal([]byte(req.String()), decoded), "Should be no error")
+	assert.True(suite.T(), decoded.IsNotification(), "Should be a notification")
+}
+
+func (suite *JSONRPC1TestSuite) Test_NewResponse() {
+	rpc := suite.rpc
+	resp := rpc.NewResponse([]byte(`{"result":"0x1","error":null,"id":1}`))
+	if assert.NotNil(suite.T(), resp) {
+		assert.EqualValues(suite.T(), NumberID(1), resp.ID(), "Should be equal")
+		assert.NoError(suite.T(), resp.Error(), "Should be no error")
+		assert.EqualValues(suite.T(), "0x1", resp.Get("result"), "Should be equal")
+		assert.EqualValues(suite.T(), `{"result":"0x1","error":null,"id":1}`, resp.String(), "Should be equal")
+	}
+
+	resp = rpc.NewResponse([]byte(`{"result":null,"error":{"code":-32601,"message":"method not found"},"id":"a"}`))
+	if assert.NotNil(suite.T(), resp) {
+		assert.EqualValues(suite.T(), StringID("a"), resp.ID(), "Should be equal")
+		assert.True(suite.T(), IsMethodNotFound(resp.Error()), "Should be method not found")
+	}
+
+	resp = rpc.NewResponse([]byte(`{"result":null,"error":"out of gas","id":2}`))
+	if assert.NotNil(suite.T(), resp) {
+		assert.EqualError(suite.T(), resp.Error(), "out of gas", "Should be equal")
+		assert.EqualValues(suite.T(), CodeServerError, resp.Error().(*JSONRPCError).Code, "Should be equal")
+		assert.EqualValues(suite.T(), `"out of gas"`, string(resp.Error().(*JSONRPCError).Data), "Should be equal")
+	}
+
+	resp = rpc.NewResponse([]byte(`{"result":null,"error":{"reason":"busy"},"id":3}`))
+	if assert.NotNil(suite.T(), resp) {
+		assert.EqualError(suite.T(), resp.Error(), `{"reason":"busy"}`, "Should be equal")
+	}
+
+	responses := rpc.NewBatchResponse([]byte(`[{"result":1,"error":null,"id":1},{"result":2,"error":null,"id":2}]`))
+	assert.Len(suite.T(), responses, 2, "Should be equal")
+	assert.Nil(suite.T(), rpc.NewResponse([]byte("xxx")))
+}
+
+func (suite *JSONRPC1TestSuite) Test_NewNotification() {
+	rpc := suite.rpc
+	n := rpc.NewNotification([]byte(`{"method":"eth_subscription","params":{"subscription":"0x1","result":"0x2"},"id":null}`))
+	if assert.NotNil(suite.T(), n) {
+		assert.EqualValues(suite.T(), "0x1", n.Subscription(), "Should be equal")
+		assert.EqualValues(suite.T(), "0x2", n.Get("result"), "Should be equal")
+	}
+	assert.Nil(suite.T(), rpc.NewNotification([]byte(`{"result":"0x1","error":null,"id":1}`)))
+}
+
+func (suite *JSONRPC1TestSuite) Test_NewReply() {
+	rpc := suite.rpc
+	req := rpc.NewRequest("eth_chainId")
+	assert.EqualValues(suite.T(), `{"result":"0x1","error":null,"id":1}`, rpc.NewReply(req, "0x1", nil).String(), "Should be equal")
+	assert.EqualValues(suite.T(), `{"result":null,"error":{"code":-32005,"message":"limit exceeded"},"id":1}`,
+		rpc.NewReply(req, nil, &JSONRPCError{Code: CodeLimitExceeded, Message: "limit exceeded"}).String(), "Should be equal")
+}
+
+func (suite *JSONRPC1TestSuite) Test_GetMethod() {
+	assert.Equal(suite.T(), "jsonrpc", GetMethod("jsonrpc").Name(), "Should be equal")
+	assert.Equal(suite.T(), "jsonrpc1", GetMethod("jsonrpc1").Name(), "Should be equal")
+	assert.Nil(suite.T(), GetMethod("cbor"), "Should be nil")
+
+	RegisterMethod("jsonrpc-legacy", NewJSONRPC1)
+	assert.Equal(suite.T(), "jsonrpc1", GetMethod("jsonrpc-legacy").Name(), "Should be equal")
+}
+
+func (suite *JSONRPC1TestSuite) SetupTest() {
+	suite.rpc = NewJSONRPC1()
+}
+
+func (suite *JSONRPC1TestSuite) TearDownTest() {
+
+}
+
+func Test_JSONRPC1TestSuite(t *testing.T) {
+	suite.Run(t, new(JSONRPC1TestSuite))
+}
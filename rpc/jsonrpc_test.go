@@ -30,6 +30,9 @@
 package rpc
 
 import (
+	"encoding/json"
+	"fmt"
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -66,12 +69,37 @@ func (suite *JSONRPCTestSuite) Test_NewRequest() {
 	}
 }
 
+type hexParam uint64
+
+func (p hexParam) MarshalParam() interface{} {
+	return fmt.Sprintf("0x%x", uint64(p))
+}
+
+func (suite *JSONRPCTestSuite) Test_TypedParams() {
+	rpc := suite.rpc
+	req := rpc.NewRequest("eth_getBlockByNumber", hexParam(10), true)
+	assert.EqualValues(suite.T(), `{"jsonrpc":"2.0","method":"eth_getBlockByNumber","params":["0xa",true],"id":1}`, req.String(), "Should be equal")
+
+	option := struct {
+		FromBlock string   `json:"fromBlock"`
+		Topics    []string `json:"topics"`
+	}{"0x1", []string{"0x2"}}
+	req.Set("params", option)
+	assert.EqualValues(suite.T(), `{"jsonrpc":"2.0","method":"eth_getBlockByNumber","params":[{"fromBlock":"0x1","topics":["0x2"]}],"id":1}`, req.String(), "Should be equal")
+
+	req.Set("params", []interface{}{hexParam(255), false, 3, []string{"a"}})
+	assert.EqualValues(suite.T(), `{"jsonrpc":"2.0","method":"eth_getBlockByNumber","params":["0xff",false,3,["a"]],"id":1}`, req.String(), "Should be equal")
+
+	req.Set("params", nil)
+	assert.EqualValues(suite.T(), `{"jsonrpc":"2.0","method":"eth_getBlockByNumber","params":[],"id":1}`, req.String(), "Should be equal")
+}
+
 func (suite *JSONRPCTestSuite) Test_NewResponse() {
 	rpc := suite.rpc
 	resp := rpc.NewResponse([]byte(`{"jsonrpc": "2.0", "id": 1, "result": ["result1", "result2"]}`))
 	if assert.NotNil(suite.T(), resp) {
 		assert.EqualValues(suite.T(), "2.0", resp.Get("version").(string), "Should be equal")
-		assert.EqualValues(suite.T(), 1, resp.ID(), "Should be equal")
+		assert.EqualValues(suite.T(), NumberID(1), resp.ID(), "Should be equal")
 
 		var results []string
 		for _, r := range resp.Get("result").([]interface{}) {
@@ -84,7 +112,7 @@ func (suite *JSONRPCTestSuite) Test_NewResponse() {
 	resp = rpc.NewResponse([]byte(`{"jsonrpc": "2.0", "id": 1, "result": ["result1", "result2"]}`))
 	if assert.NotNil(suite.T(), resp) {
 		assert.EqualValues(suite.T(), "2.0", resp.Get("version").(string), "Should be equal")
-		assert.EqualValues(suite.T(), 1, resp.ID(), "Should be equal")
+		assert.EqualValues(suite.T(), NumberID(1), resp.ID(), "Should be equal")
 
 		var results []string
 		for _, r := range resp.Get("result").([]interface{}) {
@@ -99,6 +127,152 @@ func (suite *JSONRPCTestSuite) Test_NewResponse() {
 	assert.Nil(suite.T(), resp)
 }
 
+func (suite *JSONRPCTestSuite) Test_DecodeResult() {
+	rpc := suite.rpc
+	resp := rpc.NewResponse([]byte(`{"jsonrpc":"2.0","id":1,"result":{"balance":123456789012345678901234567890}}`))
+	if assert.NotNil(suite.T(), resp) {
+		result := struct {
+			Balance *big.Int `json:"balance"`
+		}{}
+		assert.NoError(suite.T(), resp.DecodeResult(&result), "Should be no error")
+		assert.EqualValues(suite.T(), "123456789012345678901234567890", result.Balance.String(), "Should keep precision")
+
+		var raw json.RawMessage
+		assert.NoError(suite.T(), resp.DecodeResult(&raw), "Should be no error")
+		assert.EqualValues(suite.T(), `{"balance":123456789012345678901234567890}`, string(raw), "Should be equal")
+	}
+
+	resp = rpc.NewResponse([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"test error"}}`))
+	var result string
+	assert.EqualError(suite.T(), resp.DecodeResult(&result), "test error", "Should be equal")
+
+	resp = rpc.NewReply(rpc.NewRequest("test"), json.RawMessage(`[1,2]`), nil)
+	assert.EqualValues(suite.T(), []interface{}{float64(1), float64(2)}, resp.Get("result"), "Should be equal")
+}
+
+func (suite *JSONRPCTestSuite) Test_Batch() {
+	rpc := suite.rpc
+	batch := Batch{rpc.NewRequest("test1"), rpc.NewRequest("test2", "arg1")}
+	assert.EqualValues(suite.T(),
+		`[{"jsonrpc":"2.0","method":"test1","params":[],"id":1},{"jsonrpc":"2.0","method":"test2","params":["arg1"],"id":2}]`,
+		batch.String(), "Should be equal")
+
+	responses := rpc.NewBatchResponse([]byte(`[
+		{"jsonrpc": "2.0", "id": 2, "error": {"code": -32602, "message": "invalid argument"}},
+		{"jsonrpc": "2.0", "id": 1, "result": "0x1"}]`))
+	if assert.Len(suite.T(), responses, 2) {
+		matched, err := batch.Match(responses)
+		assert.NoError(suite.T(), err, "Should be no error")
+		assert.EqualValues(suite.T(), "0x1", matched[0].Get("result"), "Should be equal")
+		assert.EqualError(suite.T(), matched[1].Error(), "invalid argument")
+
+		_, err = batch.Match(responses[:1])
+		assert.Error(suite.T(), err, "Should be an error")
+	}
+
+	assert.Nil(suite.T(), rpc.NewBatchResponse([]byte(`{"jsonrpc": "2.0", "id": null, "error": {"code": -32600, "message": "invalid request"}}`)))
+}
+
+func (suite *JSONRPCTestSuite) Test_IDs() {
+	rpc := suite.rpc
+	for data, id := range map[string]ID{
+		`{"jsonrpc":"2.0","id":7,"result":"0x1"}`:                                     NumberID(7),
+		`{"jsonrpc":"2.0","id":"abc","result":"0x1"}`:                                 StringID("abc"),
+		`{"jsonrpc":"2.0","id":"\u0061bc","result":"0x1"}`:                            StringID("abc"),
+		`{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"parse error"}}`: NullID,
+	} {
+		resp := rpc.NewResponse([]byte(data))
+		if assert.NotNil(suite.T(), resp, data) {
+			assert.EqualValues(suite.T(), id, resp.ID(), "Should be equal")
+		}
+	}
+	assert.Nil(suite.T(), rpc.NewResponse([]byte(`{"jsonrpc":"2.0","id":{},"result":"0x1"}`)))
+
+	req := rpc.NewRequest("test")
+	req.Set("id", "abc")
+	assert.EqualValues(suite.T(), `{"jsonrpc":"2.0","method":"test","params":[],"id":"abc"}`, req.String(), "Should be equal")
+	req.Set("id", 42)
+	assert.EqualValues(suite.T(), NumberID(42), req.Get("id"), "Should be equal")
+	assert.False(suite.T(), req.IsNotification(), "Should not be a notification")
+
+	batch := Batch{req, rpc.NewNotificationRequest("test_notify")}
+	matched, err := batch.Match([]Response{rpc.NewReply(req, "0x1", nil)})
+	if assert.NoError(suite.T(), err, "Should be no error") {
+		assert.EqualValues(suite.T(), "0x1", matched[0].Get("result"), "Should be equal")
+		assert.Nil(suite.T(), matched[1], "Should not be answered")
+	}
+}
+
+func (suite *JSONRPCTestSuite) Test_NotificationRequest() {
+	rpc := suite.rpc
+	req := rpc.NewNotificationRequest("test", "arg1")
+	assert.True(suite.T(), req.IsNotification(), "Should be a notification")
+	assert.True(suite.T(), req.ID().IsZero(), "Should have no id")
+	assert.EqualValues(suite.T(), `{"jsonrpc":"2.0","method":"test","params":["arg1"]}`, req.String(), "Should be equal")
+
+	req.Set("id", 1)
+	assert.False(suite.T(), req.IsNotification(), "Should not be a notification")
+	req.Set("id", nil)
+	assert.True(suite.T(), req.IsNotification(), "Should be a notification")
+}
+
+func (suite *JSONRPCTestSuite) Test_ErrorData() {
+	rpc := suite.rpc
+	resp := rpc.NewResponse([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":3,"message":"execution reverted","data":"0x08c379a0"}}`))
+	if assert.NotNil(suite.T(), resp) {
+		err := resp.Error()
+		assert.True(suite.T(), IsExecutionReverted(err), "Should be a revert")
+		assert.False(suite.T(), IsServerError(err), "Should not be a server error")
+
+		var data string
+		assert.NoError(suite.T(), err.(*JSONRPCError).DecodeData(&data), "Should be no error")
+		assert.EqualValues(suite.T(), "0x08c379a0", data, "Should be equal")
+		assert.EqualValues(suite.T(), `{"jsonrpc":"2.0","id":1,"error":{"code":3,"message":"execution reverted","data":"0x08c379a0"}}`, resp.String(), "Should be equal")
+	}
+
+	err := fmt.Errorf("eth_call: %w", &JSONRPCError{Code: CodeMethodNotFound, Message: "method not found"})
+	code, ok := ErrorCode(err)
+	assert.True(suite.T(), ok, "Should be a JSON-RPC error")
+	assert.EqualValues(suite.T(), CodeMethodNotFound, code, "Should be equal")
+	assert.True(suite.T(), IsMethodNotFound(err), "Should be method not found")
+	assert.False(suite.T(), IsInvalidParams(err), "Should not be invalid params")
+	assert.True(suite.T(), IsServerError(&JSONRPCError{Code: CodeLimitExceeded}), "Should be a server error")
+	assert.True(suite.T(), IsLimitExceeded(&JSONRPCError{Code: CodeLimitExceeded}), "Should be limit exceeded")
+
+	_, ok = ErrorCode(fmt.Errorf("boom"))
+	assert.False(suite.T(), ok, "Should not be a JSON-RPC error")
+}
+
+func (suite *JSONRPCTestSuite) Test_NewNotification() {
+	rpc := suite.rpc
+	n := rpc.NewNotification([]byte(`{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0x9ce59a13059e417087c02d3236a0b1cc","result":"0xd6fdc5cc41a9959e922f30cb772a9aef46f4daea279307bc5f7024edc4ccd7fa"}}`))
+	if assert.NotNil(suite.T(), n) {
+		assert.EqualValues(suite.T(), "eth_subscription", n.Get("method").(string), "Should be equal")
+		assert.EqualValues(suite.T(), "0x9ce59a13059e417087c02d3236a0b1cc", n.Subscription(), "Should be equal")
+		assert.EqualValues(suite.T(), "0xd6fdc5cc41a9959e922f30cb772a9aef46f4daea279307bc5f7024edc4ccd7fa", n.Get("result").(string), "Should be equal")
+	}
+
+	assert.Nil(suite.T(), rpc.NewNotification([]byte(`{"jsonrpc": "2.0", "id": 1, "result": "0x1"}`)))
+	assert.Nil(suite.T(), rpc.NewNotification([]byte(`{"jsonrpc": "2.0", "id": 1, "method": "eth_blockNumber"}`)))
+	assert.Nil(suite.T(), rpc.NewNotification([]byte("xxx")))
+}
+
+func (suite *JSONRPCTestSuite) Test_NewReply() {
+	rpc := suite.rpc
+	req := rpc.NewRequest("eth_chainId")
+
+	resp := rpc.NewReply(req, "0x1", nil)
+	assert.EqualValues(suite.T(), req.ID(), resp.ID(), "Should be equal")
+	assert.EqualValues(suite.T(), "0x1", resp.Get("result"), "Should be equal")
+	assert.NoError(suite.T(), resp.Error(), "Should be no error")
+
+	resp = rpc.NewReply(req, nil, &JSONRPCError{Code: -32005, Message: "limit exceeded"})
+	assert.EqualError(suite.T(), resp.Error(), "limit exceeded", "Should be equal")
+
+	resp = rpc.NewReply(req, nil, fmt.Errorf("boom"))
+	assert.EqualValues(suite.T(), -32603, resp.Error().(*JSONRPCError).Code, "Should be equal")
+}
+
 func (suite *JSONRPCTestSuite) SetupTest() {
 	suite.rpc = NewJSONRPC()
 }
@@ -29,27 +29,92 @@
 
 package rpc
 
+import "sync"
+
 // Request defines basic methods of an RPC request
 type Request interface {
 	Set(key string, value interface{})
 	Get(key string) interface{}
 	String() string
-	ID() uint64
+	ID() ID
+	// IsNotification tells whether the request has no id, in which case
+	// providers do not wait for a response and return a nil one.
+	IsNotification() bool
 }
 
 // Response defines basic methods of an RPC response
 type Response interface {
 	Get(key string) interface{}
 	String() string
-	ID() uint64
+	ID() ID
 	Error() error
+	// DecodeResult decodes the result into v, or returns the error of the
+	// response if there is one.
+	DecodeResult(v interface{}) error
+}
+
+// Notification defines basic methods of a server initiated RPC message, such
+// as the data pushed for a subscription
+type Notification interface {
+	Get(key string) interface{}
+	String() string
+	Subscription() string
+}
+
+// ParamMarshaler is implemented by types sent as request params in another
+// form than their JSON encoding, e.g. as hex strings.
+type ParamMarshaler interface {
+	MarshalParam() interface{}
 }
 
 // RPC defines basic methods of variety RPCs
 type RPC interface {
 	Name() string
+	// ContentType is the media type of the messages, as sent in HTTP
+	// headers.
+	ContentType() string
 	NewRequest(method string, args ...interface{}) Request
+	NewNotificationRequest(method string, args ...interface{}) Request
 	NewResponse(data []byte) Response
+	NewBatchResponse(data []byte) []Response
+	NewNotification(data []byte) Notification
+	// NewReply returns the response answering request with result, or with
+	// err if it is not nil.
+	NewReply(request Request, result interface{}, err error) Response
+	// DecodeRequest decodes a single request sent by a client, as a Server
+	// does. Its params are json.RawMessage values. On error, which is a
+	// *JSONRPCError, the request returned, if any, holds the id to answer.
+	DecodeRequest(data []byte) (Request, error)
+}
+
+var methods = struct {
+	sync.RWMutex
+	factories map[string]func() RPC
+}{factories: make(map[string]func() RPC)}
+
+func init() {
+	RegisterMethod("jsonrpc", NewJSONRPC)
+	RegisterMethod("jsonrpc1", NewJSONRPC1)
+}
+
+// RegisterMethod makes GetMethod use factory for the RPC called name,
+// replacing any factory registered before.
+func RegisterMethod(name string, factory func() RPC) {
+	methods.Lock()
+	defer methods.Unlock()
+	methods.factories[name] = factory
+}
+
+// GetMethod returns a new RPC of the given name, such as jsonrpc for JSON-RPC
+// 2.0 or jsonrpc1 for JSON-RPC 1.0, or nil if there is none.
+func GetMethod(name string) RPC {
+	methods.RLock()
+	factory, ok := methods.factories[name]
+	methods.RUnlock()
+	if !ok {
+		return nil
+	}
+	return factory()
 }
 
 // GetDefaultMethod ...
@@ -0,0 +1,609 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"reflect"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// maxRequestSize bounds the size of the HTTP requests and WebSocket
+	// messages a Server reads.
+	maxRequestSize = 5 * 1024 * 1024
+	// defaultMaxConcurrency is the number of messages a Server handles at
+	// once per WebSocket or IPC connection by default.
+	defaultMaxConcurrency = 64
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Server answers requests, batches included, by calling the methods of the Go
+// values registered with it. Requests are decoded and replies encoded with its
+// RPC, JSON-RPC 2.0 by default. It serves HTTP and WebSocket clients as an
+// http.Handler, and IPC clients through ServeConn, ServeListener or ServeIPC.
+type Server struct {
+	rpc            RPC
+	upgrader       websocket.Upgrader
+	logger         Logger
+	maxConcurrency int
+
+	mu      sync.RWMutex
+	methods map[string]*handler
+	closers map[io.Closer]bool
+	stopped bool
+}
+
+// handler is a method exposed by a Server.
+type handler struct {
+	fn       reflect.Value
+	hasCtx   bool
+	args     []reflect.Type
+	errIndex int
+}
+
+// Logger is what a Server reports failures of its methods to. *log.Logger
+// satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// ServerOption configures a Server
+type ServerOption func(*Server)
+
+// WithCheckOrigin sets the function accepting or rejecting WebSocket
+// upgrades by their Origin header. By default only requests without an
+// Origin, or from the host they are sent to, are accepted, so that web pages
+// of other sites cannot call the server. Pass a function returning true to
+// accept any origin.
+func WithCheckOrigin(check func(r *http.Request) bool) ServerOption {
+	return func(server *Server) {
+		server.upgrader.CheckOrigin = check
+	}
+}
+
+// WithServerRPC sets the RPC requests are decoded and replies encoded with,
+// e.g. NewJSONRPC1() to serve JSON-RPC 1.0 clients.
+func WithServerRPC(method RPC) ServerOption {
+	return func(server *Server) {
+		server.rpc = method
+	}
+}
+
+// WithServerLogger sets where panics of methods are logged, the standard
+// logger by default.
+func WithServerLogger(logger Logger) ServerOption {
+	return func(server *Server) {
+		server.logger = logger
+	}
+}
+
+// WithMaxConcurrency sets the number of messages handled at once per
+// WebSocket or IPC connection. Reading from the connection pauses while
+// that many are in progress.
+func WithMaxConcurrency(n int) ServerOption {
+	return func(server *Server) {
+		if n > 0 {
+			server.maxConcurrency = n
+		}
+	}
+}
+
+// NewServer returns a server without any method.
+func NewServer(options ...ServerOption) *Server {
+	server := &Server{
+		rpc:            GetDefaultMethod(),
+		logger:         log.New(os.Stderr, "", log.LstdFlags),
+		maxConcurrency: defaultMaxConcurrency,
+		methods:        make(map[string]*handler),
+		closers:        make(map[io.Closer]bool),
+	}
+	for _, option := range options {
+		option(server)
+	}
+	return server
+}
+
+// Register exposes the exported methods of receiver under namespace, method
+// Foo being called as namespace_foo. Methods may take a context.Context
+// first, which is done when the request is aborted, then arguments decoded
+// from the params with encoding/json; trailing pointer arguments are
+// optional. They return a result, an error, or both. Methods of another
+// shape are skipped.
+func (server *Server) Register(namespace string, receiver interface{}) error {
+	if namespace == "" {
+		return errors.New("Missing namespace")
+	}
+
+	value := reflect.ValueOf(receiver)
+	methods := make(map[string]*handler)
+	for i := 0; i < value.NumMethod(); i++ {
+		method := value.Type().Method(i)
+		if method.PkgPath != "" {
+			continue
+		}
+		if h := newHandler(value.Method(i)); h != nil {
+			methods[namespace+"_"+lowerFirst(method.Name)] = h
+		}
+	}
+	if len(methods) == 0 {
+		return fmt.Errorf("No suitable method on %s", value.Type())
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	for name, h := range methods {
+		server.methods[name] = h
+	}
+	return nil
+}
+
+// Methods returns the names of the registered methods, sorted.
+func (server *Server) Methods() []string {
+	server.mu.RLock()
+	defer server.mu.RUnlock()
+
+	names := make([]string, 0, len(server.methods))
+	for name := range server.methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Handle answers data, a request or a batch of them, and returns the message
+// to send back. It returns nil when there is none, i.e. when data only holds
+// notifications.
+func (server *Server) Handle(ctx context.Context, data []byte) []byte {
+	data = bytes.TrimLeft(data, " \t\r\n")
+	if len(data) == 0 || data[0] != '[' {
+		if resp := server.handleRequest(ctx, data); resp != nil {
+			return []byte(resp.String())
+		}
+		return nil
+	}
+
+	var messages []json.RawMessage
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return []byte(server.reject(&JSONRPCError{Code: CodeParseError, Message: err.Error()}).String())
+	}
+	if len(messages) == 0 {
+		return []byte(server.reject(&JSONRPCError{Code: CodeInvalidRequest, Message: "Empty batch"}).String())
+	}
+
+	responses := make([]string, 0, len(messages))
+	for _, message := range messages {
+		if resp := server.handleRequest(ctx, message); resp != nil {
+			responses = append(responses, resp.String())
+		}
+	}
+	if len(responses) == 0 {
+		return nil
+	}
+	return []byte("[" + strings.Join(responses, ",") + "]")
+}
+
+// ServeHTTP answers JSON-RPC requests POSTed to it, and serves WebSocket
+// clients when asked to upgrade the connection.
+func (server *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if websocket.IsWebSocketUpgrade(r) {
+		conn, err := server.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		conn.SetReadLimit(maxRequestSize)
+		server.serveMessages(&serverWebSocketConn{conn})
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "JSON-RPC requests must be POSTed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxRequestSize+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxRequestSize {
+		http.Error(w, "Request too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	resp := server.Handle(r.Context(), body)
+	if resp == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", server.rpc.ContentType())
+	w.Write(resp)
+}
+
+// ServeConn answers the requests read from conn, a stream of JSON values such
+// as an IPC connection, until it is closed. Requests are handled
+// concurrently.
+func (server *Server) ServeConn(conn io.ReadWriteCloser) {
+	server.serveMessages(&serverStreamConn{
+		ReadWriteCloser: conn,
+		decoder:         json.NewDecoder(bufio.NewReader(conn)),
+	})
+}
+
+// ServeListener serves the connections accepted by listener, until it fails
+// or the server is stopped.
+func (server *Server) ServeListener(listener net.Listener) error {
+	if !server.track(listener) {
+		listener.Close()
+		return nil
+	}
+	defer server.untrack(listener)
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if server.isStopped() {
+				return nil
+			}
+			return err
+		}
+		go server.ServeConn(conn)
+	}
+}
+
+// ServeIPC listens on the Unix domain socket at path and serves the
+// connections to it, until the server is stopped.
+func (server *Server) ServeIPC(path string) error {
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	return server.ServeListener(listener)
+}
+
+// Stop closes the listeners and the connections served. HTTP requests are
+// not affected, they stop with the http.Server serving them.
+func (server *Server) Stop() {
+	server.mu.Lock()
+	server.stopped = true
+	closers := server.closers
+	server.closers = make(map[io.Closer]bool)
+	server.mu.Unlock()
+
+	for closer := range closers {
+		closer.Close()
+	}
+}
+
+// handleRequest answers a single request, decoded with the RPC of the server.
+// It returns nil for notifications.
+func (server *Server) handleRequest(ctx context.Context, data []byte) Response {
+	request, err := server.rpc.DecodeRequest(data)
+	if err != nil && (request == nil || !IsInvalidParams(err)) {
+		if request == nil {
+			return server.reject(err)
+		}
+		return server.rpc.NewReply(request, nil, err)
+	}
+
+	var result json.RawMessage
+	if err == nil {
+		method, _ := request.Get("method").(string)
+		params, _ := request.Get("params").([]interface{})
+		result, err = server.call(ctx, method, params)
+	}
+	if request.IsNotification() {
+		return nil
+	}
+	if err != nil {
+		return server.rpc.NewReply(request, nil, serverError(err))
+	}
+	return server.rpc.NewReply(request, result, nil)
+}
+
+// reject returns the response reporting err for a request that could not be
+// read, whose id is null.
+func (server *Server) reject(err error) Response {
+	return server.rpc.NewReply(server.rpc.NewNotificationRequest(""), nil, err)
+}
+
+func (server *Server) call(ctx context.Context, method string, params []interface{}) (json.RawMessage, error) {
+	server.mu.RLock()
+	h, ok := server.methods[method]
+	server.mu.RUnlock()
+	if !ok {
+		return nil, &JSONRPCError{Code: CodeMethodNotFound, Message: fmt.Sprintf("The method %s does not exist", method)}
+	}
+
+	args, err := h.parseArgs(params)
+	if err != nil {
+		return nil, &JSONRPCError{Code: CodeInvalidParams, Message: err.Error()}
+	}
+	if h.hasCtx {
+		args = append([]reflect.Value{reflect.ValueOf(ctx)}, args...)
+	}
+
+	result, err := h.invoke(args)
+	if p, ok := err.(*handlerPanic); ok {
+		server.logger.Printf("Method %s panicked: %v\n%s", method, p.value, p.stack)
+		return nil, &JSONRPCError{Code: CodeInternalError, Message: "Internal error"}
+	}
+	return result, err
+}
+
+// serveMessages answers the requests read from conn until it fails, handling
+// up to maxConcurrency of them at once.
+func (server *Server) serveMessages(conn serverConn) {
+	if !server.track(conn) {
+		conn.Close()
+		return
+	}
+	defer server.untrack(conn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var writeMu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, server.maxConcurrency)
+	for {
+		data, err := conn.ReadMessage()
+		if err != nil {
+			// A stream cannot be read past malformed JSON, so answer with a
+			// parse error before closing it.
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				resp := server.reject(&JSONRPCError{Code: CodeParseError, Message: "Parse error"})
+				writeMu.Lock()
+				conn.WriteMessage([]byte(resp.String()))
+				writeMu.Unlock()
+			}
+			break
+		}
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(data []byte) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			if resp := server.Handle(ctx, data); resp != nil {
+				writeMu.Lock()
+				conn.WriteMessage(resp)
+				writeMu.Unlock()
+			}
+		}(data)
+	}
+	cancel()
+	wg.Wait()
+	conn.Close()
+}
+
+func (server *Server) track(closer io.Closer) bool {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	if server.stopped {
+		return false
+	}
+	server.closers[closer] = true
+	return true
+}
+
+func (server *Server) untrack(closer io.Closer) {
+	server.mu.Lock()
+	delete(server.closers, closer)
+	server.mu.Unlock()
+}
+
+func (server *Server) isStopped() bool {
+	server.mu.RLock()
+	defer server.mu.RUnlock()
+	return server.stopped
+}
+
+// newHandler returns the handler calling fn, or nil if fn is not suitable.
+func newHandler(fn reflect.Value) *handler {
+	typ := fn.Type()
+	if typ.IsVariadic() {
+		return nil
+	}
+
+	h := &handler{fn: fn, errIndex: -1}
+	first := 0
+	if typ.NumIn() > 0 && typ.In(0) == contextType {
+		h.hasCtx = true
+		first = 1
+	}
+	for i := first; i < typ.NumIn(); i++ {
+		h.args = append(h.args, typ.In(i))
+	}
+
+	switch typ.NumOut() {
+	case 0:
+	case 1:
+		if typ.Out(0) == errorType {
+			h.errIndex = 0
+		}
+	case 2:
+		if typ.Out(0) == errorType || typ.Out(1) != errorType {
+			return nil
+		}
+		h.errIndex = 1
+	default:
+		return nil
+	}
+	return h
+}
+
+// parseArgs decodes params, raw JSON values as decoded by the RPC, into the
+// arguments of the handler.
+func (h *handler) parseArgs(params []interface{}) ([]reflect.Value, error) {
+	raw := make([]json.RawMessage, 0, len(params))
+	for _, param := range params {
+		data, ok := param.(json.RawMessage)
+		if !ok {
+			var err error
+			if data, err = json.Marshal(param); err != nil {
+				return nil, err
+			}
+		}
+		raw = append(raw, data)
+	}
+	if len(raw) > len(h.args) {
+		return nil, fmt.Errorf("Too many params, want at most %d", len(h.args))
+	}
+
+	args := make([]reflect.Value, 0, len(h.args)+1)
+	for i, typ := range h.args {
+		if i >= len(raw) {
+			if typ.Kind() != reflect.Ptr {
+				return nil, fmt.Errorf("Missing param %d", i)
+			}
+			args = append(args, reflect.Zero(typ))
+			continue
+		}
+		arg := reflect.New(typ)
+		if err := json.Unmarshal(raw[i], arg.Interface()); err != nil {
+			return nil, fmt.Errorf("Invalid param %d, %v", i, err)
+		}
+		args = append(args, arg.Elem())
+	}
+	return args, nil
+}
+
+// handlerPanic is the error of a handler that panicked.
+type handlerPanic struct {
+	value interface{}
+	stack []byte
+}
+
+func (p *handlerPanic) Error() string {
+	return fmt.Sprintf("Method panicked, %v", p.value)
+}
+
+// invoke calls the handler and encodes its result. Panics are returned as a
+// *handlerPanic.
+func (h *handler) invoke(args []reflect.Value) (result json.RawMessage, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, &handlerPanic{value: r, stack: debug.Stack()}
+		}
+	}()
+
+	out := h.fn.Call(args)
+	if h.errIndex >= 0 && !out[h.errIndex].IsNil() {
+		return nil, out[h.errIndex].Interface().(error)
+	}
+	var value interface{}
+	if len(out) > 0 && h.errIndex != 0 {
+		value = out[0].Interface()
+	}
+	if result, err = json.Marshal(value); err != nil {
+		return nil, &JSONRPCError{Code: CodeInternalError, Message: err.Error()}
+	}
+	return result, nil
+}
+
+// serverError returns err as a JSONRPCError. Errors of other types are
+// reported as server errors.
+func serverError(err error) error {
+	var rpcErr *JSONRPCError
+	if !errors.As(err, &rpcErr) {
+		rpcErr = &JSONRPCError{Code: CodeServerError, Message: err.Error()}
+	}
+	return rpcErr
+}
+
+func lowerFirst(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToLower(r)) + name[size:]
+}
+
+// serverConn is a connection a Server reads requests from.
+type serverConn interface {
+	ReadMessage() ([]byte, error)
+	WriteMessage(data []byte) error
+	Close() error
+}
+
+type serverWebSocketConn struct {
+	conn *websocket.Conn
+}
+
+func (c *serverWebSocketConn) ReadMessage() ([]byte, error) {
+	_, data, err := c.conn.ReadMessage()
+	return data, err
+}
+
+func (c *serverWebSocketConn) WriteMessage(data []byte) error {
+	return c.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+func (c *serverWebSocketConn) Close() error {
+	return c.conn.Close()
+}
+
+type serverStreamConn struct {
+	io.ReadWriteCloser
+	decoder *json.Decoder
+}
+
+func (c *serverStreamConn) ReadMessage() ([]byte, error) {
+	var msg json.RawMessage
+	if err := c.decoder.Decode(&msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func (c *serverStreamConn) WriteMessage(data []byte) error {
+	_, err := c.Write(append(data, '\n'))
+	return err
+}
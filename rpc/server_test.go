@@ -0,0 +1,396 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type calculatorService struct {
+	notified chan string
+}
+
+func (s *calculatorService) Add(a, b int) int {
+	return a + b
+}
+
+func (s *calculatorService) Div(a, b float64) (float64, error) {
+	if b == 0 {
+		return 0, &JSONRPCError{Code: CodeInvalidParams, Message: "division by zero"}
+	}
+	return a / b, nil
+}
+
+func (s *calculatorService) Echo(ctx context.Context, message string, suffix *string) string {
+	if suffix != nil {
+		return message + *suffix
+	}
+	return message
+}
+
+func (s *calculatorService) Notify(message string) {
+	s.notified <- message
+}
+
+func (s *calculatorService) Fail() error {
+	return errors.New("failed")
+}
+
+func (s *calculatorService) Panic() (string, error) {
+	panic("boom")
+}
+
+// slowService records how many of its calls run at once.
+type slowService struct {
+	mu          sync.Mutex
+	active, max int
+}
+
+func (s *slowService) Wait() {
+	s.mu.Lock()
+	s.active++
+	if s.active > s.max {
+		s.max = s.active
+	}
+	s.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	s.mu.Lock()
+	s.active--
+	s.mu.Unlock()
+}
+
+// Variadic methods are not exposed.
+func (s *calculatorService) Sum(values ...int) int {
+	return 0
+}
+
+type bufferLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *bufferLogger) Printf(format string, v ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+type ServerTestSuite struct {
+	suite.Suite
+	service *calculatorService
+	logger  *bufferLogger
+	server  *Server
+}
+
+func (suite *ServerTestSuite) handle(data string) string {
+	return string(suite.server.Handle(context.Background(), []byte(data)))
+}
+
+func (suite *ServerTestSuite) Test_Register() {
+	assert.EqualValues(suite.T(), []string{
+		"calc_add", "calc_div", "calc_echo", "calc_fail", "calc_notify", "calc_panic",
+	}, suite.server.Methods(), "Should be equal")
+	assert.Error(suite.T(), suite.server.Register("", suite.service), "Should be an error")
+	assert.Error(suite.T(), suite.server.Register("none", struct{}{}), "Should be an error")
+}
+
+func (suite *ServerTestSuite) Test_Handle() {
+	for _, test := range []struct{ request, response string }{
+		{`{"jsonrpc":"2.0","id":1,"method":"calc_add","params":[1,2]}`,
+			`{"jsonrpc":"2.0","id":1,"result":3}`},
+		{`{"jsonrpc":"2.0","id":"a","method":"calc_div","params":[1,4]}`,
+			`{"jsonrpc":"2.0","id":"a","result":0.25}`},
+		{`{"jsonrpc":"2.0","id":1,"method":"calc_echo","params":["hi"]}`,
+			`{"jsonrpc":"2.0","id":1,"result":"hi"}`},
+		{`{"jsonrpc":"2.0","id":1,"method":"calc_echo","params":["hi","!"]}`,
+			`{"jsonrpc":"2.0","id":1,"result":"hi!"}`},
+		{`{"jsonrpc":"2.0","id":1,"method":"calc_fail"}`,
+			`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"failed"}}`},
+		{`{"jsonrpc":"2.0","id":1,"method":"calc_div","params":[1,0]}`,
+			`{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"division by zero"}}`},
+		{`{"jsonrpc":"2.0","id":1,"method":"calc_panic"}`,
+			`{"jsonrpc":"2.0","id":1,"error":{"code":-32603,"message":"Internal error"}}`},
+		{`{"jsonrpc":"2.0","id":1,"method":"calc_sum","params":[1]}`,
+			`{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"The method calc_sum does not exist"}}`},
+		{`{"jsonrpc":"2.0","id":1,"method":"calc_add","params":[1]}`,
+			`{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"Missing param 1"}}`},
+		{`{"jsonrpc":"2.0","id":1,"method":"calc_add","params":[1,2,3]}`,
+			`{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"Too many params, want at most 2"}}`},
+		{`{"jsonrpc":"2.0","id":1,"method":"calc_add","params":{"a":1}}`,
+			`{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"Params must be an array"}}`},
+		{`{"jsonrpc":"1.0","id":1,"method":"calc_add","params":[1,2]}`,
+			`{"jsonrpc":"2.0","id":1,"error":{"code":-32600,"message":"Invalid request"}}`},
+		{`{"jsonrpc":"2.0","method":1}`,
+			`{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Invalid request"}}`},
+		{`{"jsonrpc":"2.0","method"`,
+			`{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"Parse error"}}`},
+		{`[]`,
+			`{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Empty batch"}}`},
+		{`{"jsonrpc":"2.0","method":"calc_notify","params":["hello"]}`,
+			``},
+		{`[{"jsonrpc":"2.0","method":"calc_notify","params":["hello"]}]`,
+			``},
+		{`[{"jsonrpc":"2.0","id":1,"method":"calc_add","params":[1,2]},1,{"jsonrpc":"2.0","method":"calc_notify","params":["hello"]},{"jsonrpc":"2.0","id":2,"method":"calc_fail"}]`,
+			`[{"jsonrpc":"2.0","id":1,"result":3},{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Invalid request"}},{"jsonrpc":"2.0","id":2,"error":{"code":-32000,"message":"failed"}}]`},
+	} {
+		assert.EqualValues(suite.T(), test.response, suite.handle(test.request), test.request)
+	}
+	assert.Len(suite.T(), suite.service.notified, 3, "Should have been notified")
+}
+
+func (suite *ServerTestSuite) Test_JSONRPC1() {
+	server := NewServer(WithServerRPC(NewJSONRPC1()))
+	server.Register("calc", suite.service)
+	defer server.Stop()
+
+	for _, test := range []struct{ request, response string }{
+		{`{"method":"calc_add","params":[1,2],"id":1}`,
+			`{"result":3,"error":null,"id":1}`},
+		{`{"method":"calc_fail","params":[],"id":"a"}`,
+			`{"result":null,"error":{"code":-32000,"message":"failed"},"id":"a"}`},
+		{`{"method":"calc_add","params":{"a":1},"id":2}`,
+			`{"result":null,"error":{"code":-32602,"message":"Params must be an array"},"id":2}`},
+		{`{"method":"calc_notify","params":["hello"],"id":null}`,
+			``},
+		{`{"params":[],"id":3}`,
+			`{"result":null,"error":{"code":-32600,"message":"Invalid request"},"id":3}`},
+		{`{"method"`,
+			`{"result":null,"error":{"code":-32700,"message":"Parse error"},"id":null}`},
+	} {
+		assert.EqualValues(suite.T(), test.response, string(server.Handle(context.Background(), []byte(test.request))), test.request)
+	}
+	assert.EqualValues(suite.T(), "hello", <-suite.service.notified, "Should be equal")
+}
+
+func (suite *ServerTestSuite) Test_PanicLogged() {
+	suite.handle(`{"jsonrpc":"2.0","id":1,"method":"calc_panic"}`)
+	if assert.Len(suite.T(), suite.logger.lines, 1) {
+		assert.True(suite.T(), strings.HasPrefix(suite.logger.lines[0], "Method calc_panic panicked: boom\n"), suite.logger.lines[0])
+	}
+}
+
+func (suite *ServerTestSuite) Test_MaxConcurrency() {
+	service := &slowService{}
+	server := NewServer(WithMaxConcurrency(2))
+	server.Register("slow", service)
+	defer server.Stop()
+
+	client, conn := net.Pipe()
+	defer client.Close()
+	go server.ServeConn(conn)
+
+	go func() {
+		for i := 1; i <= 6; i++ {
+			fmt.Fprintf(client, `{"jsonrpc":"2.0","id":%d,"method":"slow_wait"}`, i)
+		}
+	}()
+	decoder := json.NewDecoder(client)
+	for i := 0; i < 6; i++ {
+		resp := &JSONRPCResponse{}
+		assert.NoError(suite.T(), decoder.Decode(resp), "Should be no error")
+	}
+
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	assert.True(suite.T(), service.max >= 1 && service.max <= 2, "Should handle at most 2 messages at once, got %d", service.max)
+}
+
+func (suite *ServerTestSuite) Test_CheckOrigin() {
+	dial := func(server *httptest.Server, origin string) error {
+		header := http.Header{}
+		if origin != "" {
+			header.Set("Origin", origin)
+		}
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), header)
+		if err == nil {
+			conn.Close()
+		}
+		return err
+	}
+
+	server := httptest.NewServer(suite.server)
+	defer server.Close()
+	assert.NoError(suite.T(), dial(server, ""), "Should accept clients without origin")
+	assert.NoError(suite.T(), dial(server, server.URL), "Should accept the same origin")
+	assert.Error(suite.T(), dial(server, "http://evil.example"), "Should reject other origins")
+
+	relaxed := httptest.NewServer(NewServer(WithCheckOrigin(func(r *http.Request) bool { return true })))
+	defer relaxed.Close()
+	assert.NoError(suite.T(), dial(relaxed, "http://evil.example"), "Should accept any origin")
+}
+
+func (suite *ServerTestSuite) Test_HTTP() {
+	server := httptest.NewServer(suite.server)
+	defer server.Close()
+
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"calc_add","params":[1,2]}`))
+	if assert.NoError(suite.T(), err, "Should be no error") {
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.EqualValues(suite.T(), http.StatusOK, resp.StatusCode, "Should be equal")
+		assert.EqualValues(suite.T(), "application/json", resp.Header.Get("Content-Type"), "Should be equal")
+		assert.EqualValues(suite.T(), `{"jsonrpc":"2.0","id":1,"result":3}`, string(body), "Should be equal")
+	}
+
+	resp, err = http.Post(server.URL, "application/json", strings.NewReader(`{"jsonrpc":"2.0","method":"calc_notify","params":["hello"]}`))
+	if assert.NoError(suite.T(), err, "Should be no error") {
+		resp.Body.Close()
+		assert.EqualValues(suite.T(), http.StatusNoContent, resp.StatusCode, "Should be equal")
+		assert.EqualValues(suite.T(), "hello", <-suite.service.notified, "Should be equal")
+	}
+
+	resp, err = http.Get(server.URL)
+	if assert.NoError(suite.T(), err, "Should be no error") {
+		resp.Body.Close()
+		assert.EqualValues(suite.T(), http.StatusMethodNotAllowed, resp.StatusCode, "Should be equal")
+	}
+}
+
+func (suite *ServerTestSuite) Test_WebSocket() {
+	server := httptest.NewServer(suite.server)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if !assert.NoError(suite.T(), err, "Should be no error") {
+		return
+	}
+	defer conn.Close()
+
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","method":"calc_notify","params":["hello"]}`))
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":7,"method":"calc_add","params":[3,4]}`))
+	_, data, err := conn.ReadMessage()
+	if assert.NoError(suite.T(), err, "Should be no error") {
+		assert.EqualValues(suite.T(), `{"jsonrpc":"2.0","id":7,"result":7}`, string(data), "Should be equal")
+	}
+	assert.EqualValues(suite.T(), "hello", <-suite.service.notified, "Should be equal")
+
+	// Stopping the server closes the connection.
+	suite.server.Stop()
+	_, _, err = conn.ReadMessage()
+	assert.Error(suite.T(), err, "Should be an error")
+}
+
+func (suite *ServerTestSuite) Test_WebSocketReadLimit() {
+	server := httptest.NewServer(suite.server)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if !assert.NoError(suite.T(), err, "Should be no error") {
+		return
+	}
+	defer conn.Close()
+
+	conn.WriteMessage(websocket.TextMessage, []byte(`"`+strings.Repeat("x", maxRequestSize)+`"`))
+	_, _, err = conn.ReadMessage()
+	assert.Error(suite.T(), err, "Should close the connection")
+}
+
+func (suite *ServerTestSuite) Test_MalformedStream() {
+	client, conn := net.Pipe()
+	defer client.Close()
+	go suite.server.ServeConn(conn)
+
+	go fmt.Fprint(client, `{"jsonrpc":"2.0","id":1,"method":}`)
+	resp := &JSONRPCResponse{}
+	if assert.NoError(suite.T(), json.NewDecoder(client).Decode(resp), "Should be no error") {
+		assert.True(suite.T(), IsParseError(resp.Error()), "Should be a parse error")
+	}
+}
+
+func (suite *ServerTestSuite) Test_IPC() {
+	dir, err := ioutil.TempDir("", "web3go")
+	if !assert.NoError(suite.T(), err, "Should be no error") {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.ipc")
+	served := make(chan error, 1)
+	listener, err := net.Listen("unix", path)
+	if !assert.NoError(suite.T(), err, "Should be no error") {
+		return
+	}
+	go func() { served <- suite.server.ServeListener(listener) }()
+
+	conn, err := net.Dial("unix", path)
+	if !assert.NoError(suite.T(), err, "Should be no error") {
+		return
+	}
+	defer conn.Close()
+
+	conn.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"calc_add","params":[1,2]}{"jsonrpc":"2.0","id":2,"method":"calc_echo","params":["hi"]}`))
+	decoder := json.NewDecoder(bufio.NewReader(conn))
+	answers := make(map[ID]string)
+	for i := 0; i < 2; i++ {
+		resp := &JSONRPCResponse{}
+		if assert.NoError(suite.T(), decoder.Decode(resp), "Should be no error") {
+			answers[resp.ID()] = string(resp.Result)
+		}
+	}
+	assert.EqualValues(suite.T(), map[ID]string{NumberID(1): "3", NumberID(2): `"hi"`}, answers, "Should be equal")
+
+	suite.server.Stop()
+	assert.NoError(suite.T(), <-served, "Should stop serving")
+}
+
+func (suite *ServerTestSuite) SetupTest() {
+	suite.service = &calculatorService{notified: make(chan string, 10)}
+	suite.logger = &bufferLogger{}
+	suite.server = NewServer(WithServerLogger(suite.logger))
+	suite.server.Register("calc", suite.service)
+}
+
+func (suite *ServerTestSuite) TearDownTest() {
+	suite.server.Stop()
+}
+
+func Test_ServerTestSuite(t *testing.T) {
+	suite.Run(t, new(ServerTestSuite))
+}
@@ -30,34 +30,27 @@
 package test
 
 import (
+	"context"
 	"fmt"
-	"strings"
 
 	"github.com/yangyuan6/web3go/provider"
 	"github.com/yangyuan6/web3go/rpc"
-	"github.com/stretchr/testify/mock"
 )
 
-// MockAPI ...
-type MockAPI interface {
-	Do(rpc.Request) (rpc.Response, error)
-}
-
-// MockHTTPProvider provides basic web3 interface
+// MockHTTPProvider answers requests in process with a rpc.Server serving
+// MockEthAPI and MockNetAPI
 type MockHTTPProvider struct {
-	mock mock.Mock
-	rpc  rpc.RPC
-	apis map[string]MockAPI
+	rpc    rpc.RPC
+	server *rpc.Server
 }
 
 // NewMockHTTPProvider creates a HTTP provider mock
 func NewMockHTTPProvider() provider.Provider {
 	method := rpc.GetDefaultMethod()
-	return &MockHTTPProvider{rpc: method,
-		apis: map[string]MockAPI{
-			"net": NewMockNetAPI(method),
-			"eth": NewMockEthAPI(method),
-		}}
+	server := rpc.NewServer(rpc.WithServerRPC(method))
+	server.Register("eth", NewMockEthAPI())
+	server.Register("net", NewMockNetAPI())
+	return &MockHTTPProvider{rpc: method, server: server}
 }
 
 // IsConnected ...
@@ -67,23 +60,23 @@ func (provider *MockHTTPProvider) IsConnected() bool {
 
 // Send JSON RPC request through http client
 func (provider *MockHTTPProvider) Send(request rpc.Request) (response rpc.Response, err error) {
-	m := request.Get("method")
-	switch m.(type) {
-	case string:
-		method := m.(string)
-		return provider.dispatchMethod(method, request)
-	default:
-		return nil, fmt.Errorf("Invalid method %v", m)
-	}
+	return provider.SendContext(context.Background(), request)
 }
 
-func (provider *MockHTTPProvider) dispatchMethod(method string, request rpc.Request) (response rpc.Response, err error) {
-	if index := strings.Index(method, "_"); index > 0 {
-		if api, ok := provider.apis[method[:index]]; ok {
-			return api.Do(request)
-		}
+// SendContext fails if ctx is already done, otherwise it is the same as Send
+func (provider *MockHTTPProvider) SendContext(ctx context.Context, request rpc.Request) (response rpc.Response, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	data := provider.server.Handle(ctx, []byte(request.String()))
+	if data == nil {
+		return nil, nil
+	}
+	if response = provider.rpc.NewResponse(data); response == nil {
+		return nil, fmt.Errorf("Malformed response body, %s", string(data))
 	}
-	return nil, fmt.Errorf("Unrecognized method %s", method)
+	return response, nil
 }
 
 func (provider *MockHTTPProvider) GetRPCMethod() rpc.RPC {
@@ -29,33 +29,26 @@
 
 package test
 
-import (
-	"fmt"
+// MockNetAPI answers the net_ methods with canned data. Register it with an
+// rpc.Server under the net namespace.
+type MockNetAPI struct{}
 
-	"github.com/yangyuan6/web3go/rpc"
-)
-
-// MockNetAPI ...
-type MockNetAPI struct {
-	rpc rpc.RPC
+// NewMockNetAPI ...
+func NewMockNetAPI() *MockNetAPI {
+	return &MockNetAPI{}
 }
 
-// NewMockNetAPI ...
-func NewMockNetAPI(rpc rpc.RPC) MockAPI {
-	return &MockNetAPI{rpc: rpc}
+// Version ...
+func (net *MockNetAPI) Version() string {
+	return "100"
 }
 
-// Do ...
-func (net *MockNetAPI) Do(request rpc.Request) (response rpc.Response, err error) {
-	method := request.Get("method").(string)
-	switch method {
-	case "net_version":
-		return generateResponse(net.rpc, request, "100")
-	case "net_listening":
-		return generateResponse(net.rpc, request, true)
-	case "net_peerCount":
-		return generateResponse(net.rpc, request, "0x32")
-	}
+// Listening ...
+func (net *MockNetAPI) Listening() bool {
+	return true
+}
 
-	return nil, fmt.Errorf("Invalid method %s", method)
+// PeerCount ...
+func (net *MockNetAPI) PeerCount() string {
+	return "0x32"
 }
@@ -0,0 +1,261 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package web3
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/yangyuan6/web3go/common"
+	"github.com/yangyuan6/web3go/rpc"
+)
+
+var (
+	// ErrNotFound is returned for, and set on batch calls of, a block,
+	// transaction or receipt whose result was null, e.g. the receipt of a
+	// transaction that is not mined yet.
+	ErrNotFound = errors.New("Not found")
+	// ErrBatchNotExecuted is the error of a call whose batch has not been
+	// executed yet.
+	ErrBatchNotExecuted = errors.New("Batch not executed")
+)
+
+// Batch queues calls and sends them to the node together. Typed calls take a
+// pointer that receives the decoded result once the batch has been executed.
+//
+//	batch := web3.NewBatch()
+//	number, balance := new(big.Int), new(big.Int)
+//	batch.BlockNumber(number)
+//	call := batch.GetBalance(address, common.BlockNumberOrHashWithNumber(common.Latest), balance)
+//	if err := batch.Execute(); err == nil && call.Err == nil {
+//		...
+//	}
+type Batch struct {
+	requestManager *requestManager
+	calls          []*BatchCall
+}
+
+// BatchCall is a call queued in a Batch. Err is the error of this call alone
+// and is set by Batch.Execute.
+type BatchCall struct {
+	Err      error
+	request  rpc.Request
+	response rpc.Response
+	decode   func(rpc.Response) error
+}
+
+// NewBatch creates an empty batch.
+func (web3 *Web3) NewBatch() *Batch {
+	return &Batch{requestManager: web3.requestManager}
+}
+
+// Len returns the number of queued calls.
+func (batch *Batch) Len() int {
+	return len(batch.calls)
+}
+
+// Execute sends all queued calls and decodes their results. Providers that are
+// not a provider.BatchSender get the calls one after another. The returned
+// error only reports a failure of the batch as a whole; per call errors are in
+// each BatchCall.
+func (batch *Batch) Execute() error {
+	return batch.ExecuteContext(context.Background())
+}
+
+// ExecuteContext is like Execute but takes a context.
+func (batch *Batch) ExecuteContext(ctx context.Context) error {
+	requests := make([]rpc.Request, 0, len(batch.calls))
+	for _, call := range batch.calls {
+		requests = append(requests, call.request)
+	}
+
+	responses, err := batch.requestManager.sendBatch(ctx, requests)
+	if err != nil {
+		return err
+	}
+	if len(responses) != len(batch.calls) {
+		return fmt.Errorf("Batch of %d requests got %d responses", len(batch.calls), len(responses))
+	}
+
+	for i, call := range batch.calls {
+		call.response = responses[i]
+		if call.Err = call.response.Error(); call.Err == nil && call.decode != nil {
+			call.Err = call.decode(call.response)
+		}
+	}
+	return nil
+}
+
+// Add queues an arbitrary call. Its result is decoded into result, if not nil,
+// with encoding/json.
+func (batch *Batch) Add(method string, result interface{}, args ...interface{}) *BatchCall {
+	return batch.add(batch.requestManager.newRequest(method, args...), func(resp rpc.Response) error {
+		if result == nil {
+			return nil
+		}
+		return decodeResult(resp, result)
+	})
+}
+
+// BlockNumber queues eth_blockNumber.
+func (batch *Batch) BlockNumber(result *big.Int) *BatchCall {
+	req := batch.requestManager.newRequest("eth_blockNumber")
+	return batch.add(req, bigIntDecoder(result))
+}
+
+// GasPrice queues eth_gasPrice.
+func (batch *Batch) GasPrice(result *big.Int) *BatchCall {
+	req := batch.requestManager.newRequest("eth_gasPrice")
+	return batch.add(req, bigIntDecoder(result))
+}
+
+// GetBalance queues eth_getBalance.
+func (batch *Batch) GetBalance(address common.Address, block common.BlockNumberOrHash, result *big.Int) *BatchCall {
+	req := batch.requestManager.newRequest("eth_getBalance", address, block)
+	return batch.add(req, bigIntDecoder(result))
+}
+
+// GetTransactionCount queues eth_getTransactionCount.
+func (batch *Batch) GetTransactionCount(address common.Address, block common.BlockNumberOrHash, result *big.Int) *BatchCall {
+	req := batch.requestManager.newRequest("eth_getTransactionCount", address, block)
+	return batch.add(req, bigIntDecoder(result))
+}
+
+// GetCode queues eth_getCode.
+func (batch *Batch) GetCode(address common.Address, block common.BlockNumberOrHash, result *[]byte) *BatchCall {
+	req := batch.requestManager.newRequest("eth_getCode", address, block)
+	return batch.add(req, bytesDecoder(result))
+}
+
+// Call queues eth_call.
+func (batch *Batch) Call(tx *common.TransactionRequest, block common.BlockNumberOrHash, result *[]byte) *BatchCall {
+	req := batch.requestManager.newRequest("eth_call", tx, block)
+	return batch.add(req, bytesDecoder(result))
+}
+
+// GetBlockByHash queues eth_getBlockByHash.
+func (batch *Batch) GetBlockByHash(hash common.Hash, full bool, result *common.Block) *BatchCall {
+	req := batch.requestManager.newRequest("eth_getBlockByHash", hash, full)
+	return batch.add(req, blockDecoder(full, result))
+}
+
+// GetBlockByNumber queues eth_getBlockByNumber.
+func (batch *Batch) GetBlockByNumber(number common.BlockNumber, full bool, result *common.Block) *BatchCall {
+	req := batch.requestManager.newRequest("eth_getBlockByNumber", number, full)
+	return batch.add(req, blockDecoder(full, result))
+}
+
+// GetTransactionByHash queues eth_getTransactionByHash.
+func (batch *Batch) GetTransactionByHash(hash common.Hash, result *common.Transaction) *BatchCall {
+	req := batch.requestManager.newRequest("eth_getTransactionByHash", hash)
+	return batch.add(req, func(resp rpc.Response) error {
+		tx := &jsonTransaction{}
+		if err := decodeResult(resp, tx); err != nil {
+			return err
+		}
+		*result = *tx.ToTransaction()
+		return nil
+	})
+}
+
+// GetTransactionReceipt queues eth_getTransactionReceipt.
+func (batch *Batch) GetTransactionReceipt(hash common.Hash, result *common.TransactionReceipt) *BatchCall {
+	req := batch.requestManager.newRequest("eth_getTransactionReceipt", hash)
+	return batch.add(req, func(resp rpc.Response) error {
+		receipt := &jsonTransactionReceipt{}
+		if err := decodeResult(resp, receipt); err != nil {
+			return err
+		}
+		*result = *receipt.ToTransactionReceipt()
+		return nil
+	})
+}
+
+func (batch *Batch) add(request rpc.Request, decode func(rpc.Response) error) *BatchCall {
+	call := &BatchCall{Err: ErrBatchNotExecuted, request: request, decode: decode}
+	batch.calls = append(batch.calls, call)
+	return call
+}
+
+// Response returns the raw response of the call, or nil before the batch has
+// been executed.
+func (call *BatchCall) Response() rpc.Response {
+	return call.response
+}
+
+// -----------------------------------------------------------------------------
+// Decoders
+
+func decodeResult(resp rpc.Response, result interface{}) error {
+	var raw json.RawMessage
+	if err := resp.DecodeResult(&raw); err != nil {
+		return err
+	}
+	if string(raw) == "null" {
+		return ErrNotFound
+	}
+	return json.Unmarshal(raw, result)
+}
+
+func bigIntDecoder(result *big.Int) func(rpc.Response) error {
+	return func(resp rpc.Response) error {
+		var quantity common.HexBig
+		if err := decodeResult(resp, &quantity); err != nil {
+			return err
+		}
+		result.Set(quantity.ToInt())
+		return nil
+	}
+}
+
+func bytesDecoder(result *[]byte) func(rpc.Response) error {
+	return func(resp rpc.Response) error {
+		var data common.HexBytes
+		if err := decodeResult(resp, &data); err != nil {
+			return err
+		}
+		*result = data
+		return nil
+	}
+}
+
+func blockDecoder(full bool, result *common.Block) func(rpc.Response) error {
+	return func(resp rpc.Response) error {
+		block := newJSONBlock(full)
+		if err := decodeResult(resp, block); err != nil {
+			return err
+		}
+		*result = *block.ToBlock()
+		return nil
+	}
+}
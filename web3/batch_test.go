@@ -0,0 +1,172 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package web3
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/yangyuan6/web3go/common"
+	"github.com/yangyuan6/web3go/provider"
+	"github.com/yangyuan6/web3go/rpc"
+	"github.com/yangyuan6/web3go/test"
+)
+
+// batchingProvider adds batches to the mock provider and counts them. With
+// short, it drops the last response of a batch.
+type batchingProvider struct {
+	provider.Provider
+	batches int
+	short   bool
+}
+
+func (p *batchingProvider) SendBatch(requests []rpc.Request) ([]rpc.Response, error) {
+	return p.SendBatchContext(context.Background(), requests)
+}
+
+func (p *batchingProvider) SendBatchContext(ctx context.Context, requests []rpc.Request) ([]rpc.Response, error) {
+	p.batches++
+	responses := make([]rpc.Response, 0, len(requests))
+	for _, req := range requests {
+		resp, err := p.SendContext(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, resp)
+	}
+	if p.short {
+		responses = responses[:len(responses)-1]
+	}
+	return responses, nil
+}
+
+type bufferLogger struct {
+	lines []string
+}
+
+func (l *bufferLogger) Printf(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+type BatchTestSuite struct {
+	suite.Suite
+	provider *batchingProvider
+	web3     *Web3
+}
+
+func (suite *BatchTestSuite) Test_Execute() {
+	batch := suite.web3.NewBatch()
+	number, balance := new(big.Int), new(big.Int)
+	block := &common.Block{}
+	receipt := &common.TransactionReceipt{}
+	var result []string
+
+	numberCall := batch.BlockNumber(number)
+	balanceCall := batch.GetBalance(common.NewAddress(common.HexToBytes("0x407d73d8a49eeb85d32cf465507dd71d507100c1")), common.BlockNumberOrHashWithNumber(common.Latest), balance)
+	blockCall := batch.GetBlockByNumber(common.BlockNumber(0x1b4), false, block)
+	receiptCall := batch.GetTransactionReceipt(common.NewHash(common.HexToBytes("0xb903239f8543d04b5dc1ba6579132b143087c68db1b2168786408fcbce568238")), receipt)
+	compilersCall := batch.Add("eth_getCompilers", &result)
+	assert.Equal(suite.T(), 5, batch.Len(), "should be equal")
+	assert.Equal(suite.T(), ErrBatchNotExecuted, numberCall.Err, "should be equal")
+
+	assert.NoError(suite.T(), batch.Execute(), "Should be no error")
+	assert.Equal(suite.T(), 1, suite.provider.batches, "should be sent as one batch")
+
+	assert.NoError(suite.T(), numberCall.Err, "Should be no error")
+	assert.EqualValues(suite.T(), big.NewInt(0x4b7), number, "Should be equal")
+	assert.NoError(suite.T(), balanceCall.Err, "Should be no error")
+	assert.EqualValues(suite.T(), big.NewInt(0x0234c8a3397aab58), balance, "Should be equal")
+	assert.NoError(suite.T(), blockCall.Err, "Should be no error")
+	assert.EqualValues(suite.T(), big.NewInt(0x1b4), block.Number, "Should be equal")
+	assert.NoError(suite.T(), receiptCall.Err, "Should be no error")
+	assert.EqualValues(suite.T(), big.NewInt(0x4dc), receipt.GasUsed, "Should be equal")
+	assert.NoError(suite.T(), compilersCall.Err, "Should be no error")
+	assert.EqualValues(suite.T(), []string{"solidity", "lll", "serpent"}, result, "Should be equal")
+}
+
+func (suite *BatchTestSuite) Test_ExecuteShortResponse() {
+	suite.provider.short = true
+	batch := suite.web3.NewBatch()
+	numberCall := batch.BlockNumber(new(big.Int))
+	batch.GasPrice(new(big.Int))
+
+	assert.EqualError(suite.T(), batch.Execute(), "Batch of 2 requests got 1 responses", "Should be equal")
+	assert.Equal(suite.T(), ErrBatchNotExecuted, numberCall.Err, "Should be equal")
+}
+
+func (suite *BatchTestSuite) Test_ExecuteWithoutBatchSender() {
+	web3 := NewWeb3(test.NewMockHTTPProvider())
+	batch := web3.NewBatch()
+	price := new(big.Int)
+	call := batch.GasPrice(price)
+
+	assert.NoError(suite.T(), batch.Execute(), "Should be no error")
+	assert.NoError(suite.T(), call.Err, "Should be no error")
+	assert.EqualValues(suite.T(), big.NewInt(0x09184e72a000), price, "Should be equal")
+}
+
+func (suite *BatchTestSuite) Test_ExecuteWithMiddlewares() {
+	logger := &bufferLogger{}
+	var methods []string
+	spy := func(next provider.Provider) provider.Provider {
+		return &methodSpy{Provider: next, methods: &methods}
+	}
+
+	// Built-in middlewares keep the batch whole and see each request.
+	web3 := NewWeb3(suite.provider, provider.LoggingMiddleware(logger))
+	batch := web3.NewBatch()
+	batch.BlockNumber(new(big.Int))
+	batch.GasPrice(new(big.Int))
+	assert.NoError(suite.T(), batch.Execute(), "Should be no error")
+	assert.Equal(suite.T(), 1, suite.provider.batches, "should be sent as one batch")
+	assert.Len(suite.T(), logger.lines, 2)
+
+	// Other middlewares get the requests one by one.
+	web3 = NewWeb3(suite.provider, spy)
+	batch = web3.NewBatch()
+	batch.BlockNumber(new(big.Int))
+	batch.GasPrice(new(big.Int))
+	assert.NoError(suite.T(), batch.Execute(), "Should be no error")
+	assert.Equal(suite.T(), 1, suite.provider.batches, "should not be sent as a batch")
+	assert.Equal(suite.T(), []string{"eth_blockNumber", "eth_gasPrice"}, methods, "Should be equal")
+}
+
+func (suite *BatchTestSuite) SetupTest() {
+	suite.provider = &batchingProvider{Provider: test.NewMockHTTPProvider()}
+	suite.web3 = NewWeb3(suite.provider)
+}
+
+func Test_BatchTestSuite(t *testing.T) {
+	suite.Run(t, new(BatchTestSuite))
+}
@@ -30,10 +30,11 @@
 package web3
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"math/big"
 	"strconv"
+	"strings"
 
 	"github.com/yangyuan6/web3go/common"
 	"github.com/yangyuan6/web3go/rpc"
@@ -42,47 +43,94 @@ import (
 // Eth ...
 type Eth interface {
 	ProtocolVersion() (string, error)
+	ProtocolVersionContext(ctx context.Context) (string, error)
 	Syncing() (common.SyncStatus, error)
+	SyncingContext(ctx context.Context) (common.SyncStatus, error)
 	Coinbase() (common.Address, error)
+	CoinbaseContext(ctx context.Context) (common.Address, error)
 	Mining() (bool, error)
+	MiningContext(ctx context.Context) (bool, error)
 	HashRate() (uint64, error)
+	HashRateContext(ctx context.Context) (uint64, error)
 	GasPrice() (*big.Int, error)
+	GasPriceContext(ctx context.Context) (*big.Int, error)
 	Accounts() ([]common.Address, error)
+	AccountsContext(ctx context.Context) ([]common.Address, error)
 	BlockNumber() (*big.Int, error)
-	GetBalance(address common.Address, quantity string) (*big.Int, error)
-	GetStorageAt(address common.Address, position uint64, quantity string) (uint64, error)
-	GetTransactionCount(address common.Address, quantity string) (*big.Int, error)
+	BlockNumberContext(ctx context.Context) (*big.Int, error)
+	GetBalance(address common.Address, block common.BlockNumberOrHash) (*big.Int, error)
+	GetBalanceContext(ctx context.Context, address common.Address, block common.BlockNumberOrHash) (*big.Int, error)
+	GetStorageAt(address common.Address, position uint64, block common.BlockNumberOrHash) (uint64, error)
+	GetStorageAtContext(ctx context.Context, address common.Address, position uint64, block common.BlockNumberOrHash) (uint64, error)
+	GetTransactionCount(address common.Address, block common.BlockNumberOrHash) (*big.Int, error)
+	GetTransactionCountContext(ctx context.Context, address common.Address, block common.BlockNumberOrHash) (*big.Int, error)
 	GetBlockTransactionCountByHash(hash common.Hash) (*big.Int, error)
-	GetBlockTransactionCountByNumber(quantity string) (*big.Int, error)
+	GetBlockTransactionCountByHashContext(ctx context.Context, hash common.Hash) (*big.Int, error)
+	GetBlockTransactionCountByNumber(number common.BlockNumber) (*big.Int, error)
+	GetBlockTransactionCountByNumberContext(ctx context.Context, number common.BlockNumber) (*big.Int, error)
 	GetUncleCountByBlockHash(hash common.Hash) (*big.Int, error)
-	GetUncleCountByBlockNumber(quantity string) (*big.Int, error)
-	GetCode(address common.Address, quantity string) ([]byte, error)
+	GetUncleCountByBlockHashContext(ctx context.Context, hash common.Hash) (*big.Int, error)
+	GetUncleCountByBlockNumber(number common.BlockNumber) (*big.Int, error)
+	GetUncleCountByBlockNumberContext(ctx context.Context, number common.BlockNumber) (*big.Int, error)
+	GetCode(address common.Address, block common.BlockNumberOrHash) ([]byte, error)
+	GetCodeContext(ctx context.Context, address common.Address, block common.BlockNumberOrHash) ([]byte, error)
 	Sign(address common.Address, data []byte) ([]byte, error)
+	SignContext(ctx context.Context, address common.Address, data []byte) ([]byte, error)
 	SendTransaction(tx *common.TransactionRequest) (common.Hash, error)
+	SendTransactionContext(ctx context.Context, tx *common.TransactionRequest) (common.Hash, error)
 	SendRawTransaction(tx []byte) (common.Hash, error)
-	Call(tx *common.TransactionRequest, quantity string) ([]byte, error)
-	EstimateGas(tx *common.TransactionRequest, quantity string) (*big.Int, error)
+	SendRawTransactionContext(ctx context.Context, tx []byte) (common.Hash, error)
+	Call(tx *common.TransactionRequest, block common.BlockNumberOrHash) ([]byte, error)
+	CallContext(ctx context.Context, tx *common.TransactionRequest, block common.BlockNumberOrHash) ([]byte, error)
+	EstimateGas(tx *common.TransactionRequest, block common.BlockNumberOrHash) (*big.Int, error)
+	EstimateGasContext(ctx context.Context, tx *common.TransactionRequest, block common.BlockNumberOrHash) (*big.Int, error)
 	GetBlockByHash(hash common.Hash, full bool) (*common.Block, error)
-	GetBlockByNumber(quantity string, full bool) (*common.Block, error)
+	GetBlockByHashContext(ctx context.Context, hash common.Hash, full bool) (*common.Block, error)
+	GetBlockByNumber(number common.BlockNumber, full bool) (*common.Block, error)
+	GetBlockByNumberContext(ctx context.Context, number common.BlockNumber, full bool) (*common.Block, error)
+	GetBlockWithReceipts(block common.BlockNumberOrHash) (*common.BlockWithReceipts, error)
+	GetBlockWithReceiptsContext(ctx context.Context, block common.BlockNumberOrHash) (*common.BlockWithReceipts, error)
 	GetTransactionByHash(hash common.Hash) (*common.Transaction, error)
+	GetTransactionByHashContext(ctx context.Context, hash common.Hash) (*common.Transaction, error)
 	GetTransactionByBlockHashAndIndex(hash common.Hash, index uint64) (*common.Transaction, error)
-	GetTransactionByBlockNumberAndIndex(quantity string, index uint64) (*common.Transaction, error)
+	GetTransactionByBlockHashAndIndexContext(ctx context.Context, hash common.Hash, index uint64) (*common.Transaction, error)
+	GetTransactionByBlockNumberAndIndex(number common.BlockNumber, index uint64) (*common.Transaction, error)
+	GetTransactionByBlockNumberAndIndexContext(ctx context.Context, number common.BlockNumber, index uint64) (*common.Transaction, error)
 	GetTransactionReceipt(hash common.Hash) (*common.TransactionReceipt, error)
+	GetTransactionReceiptContext(ctx context.Context, hash common.Hash) (*common.TransactionReceipt, error)
 	GetUncleByBlockHashAndIndex(hash common.Hash, index uint64) (*common.Block, error)
-	GetUncleByBlockNumberAndIndex(quantity string, index uint64) (*common.Block, error)
+	GetUncleByBlockHashAndIndexContext(ctx context.Context, hash common.Hash, index uint64) (*common.Block, error)
+	GetUncleByBlockNumberAndIndex(number common.BlockNumber, index uint64) (*common.Block, error)
+	GetUncleByBlockNumberAndIndexContext(ctx context.Context, number common.BlockNumber, index uint64) (*common.Block, error)
 	GetCompilers() ([]string, error)
+	GetCompilersContext(ctx context.Context) ([]string, error)
 	// GompileLLL
 	// CompileSolidity
 	// CompileSerpent
 	NewFilter(option *FilterOption) (Filter, error)
+	NewFilterContext(ctx context.Context, option *FilterOption) (Filter, error)
 	NewBlockFilter() (Filter, error)
+	NewBlockFilterContext(ctx context.Context) (Filter, error)
 	NewPendingTransactionFilter() (Filter, error)
+	NewPendingTransactionFilterContext(ctx context.Context) (Filter, error)
 	UninstallFilter(filter Filter) (bool, error)
+	UninstallFilterContext(ctx context.Context, filter Filter) (bool, error)
 	GetFilterChanges(filter Filter) ([]interface{}, error)
+	GetFilterChangesContext(ctx context.Context, filter Filter) ([]interface{}, error)
 	GetFilterLogs(filter Filter) ([]interface{}, error)
+	GetFilterLogsContext(ctx context.Context, filter Filter) ([]interface{}, error)
 	GetLogs(filter Filter) ([]interface{}, error)
+	GetLogsContext(ctx context.Context, filter Filter) ([]interface{}, error)
+	SubscribeNewHeads() (Subscription, error)
+	SubscribeNewHeadsContext(ctx context.Context) (Subscription, error)
+	SubscribeLogs(option *FilterOption) (Subscription, error)
+	SubscribeLogsContext(ctx context.Context, option *FilterOption) (Subscription, error)
+	SubscribeNewPendingTransactions() (Subscription, error)
+	SubscribeNewPendingTransactionsContext(ctx context.Context) (Subscription, error)
 	GetWork() (common.Hash, common.Hash, common.Hash, error)
+	GetWorkContext(ctx context.Context) (common.Hash, common.Hash, common.Hash, error)
 	SubmitWork(nonce uint64, header common.Hash, mixDigest common.Hash) (bool, error)
+	SubmitWorkContext(ctx context.Context, nonce uint64, header common.Hash, mixDigest common.Hash) (bool, error)
 	// SubmitHashrate
 }
 
@@ -99,24 +147,34 @@ func newEthAPI(requestManager *requestManager) Eth {
 
 // ProtocolVersion returns the current ethereum protocol version.
 func (eth *EthAPI) ProtocolVersion() (string, error) {
+	return eth.ProtocolVersionContext(context.Background())
+}
+
+// ProtocolVersionContext is like ProtocolVersion but takes a context.
+func (eth *EthAPI) ProtocolVersionContext(ctx context.Context) (string, error) {
 	req := eth.requestManager.newRequest("eth_protocolVersion")
-	resp, err := eth.requestManager.send(req)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return "", err
 	}
 
-	if resp.Error() != nil {
-		return "", resp.Error()
+	var result string
+	if err := resp.DecodeResult(&result); err != nil {
+		return "", err
 	}
-
-	return resp.Get("result").(string), nil
+	return result, nil
 }
 
 // Syncing returns true with an object with data about the sync status or false
 // with nil.
 func (eth *EthAPI) Syncing() (common.SyncStatus, error) {
+	return eth.SyncingContext(context.Background())
+}
+
+// SyncingContext is like Syncing but takes a context.
+func (eth *EthAPI) SyncingContext(ctx context.Context) (common.SyncStatus, error) {
 	req := eth.requestManager.newRequest("eth_syncing")
-	resp, err := eth.requestManager.send(req)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return common.SyncStatus{
 			Result: false,
@@ -136,393 +194,418 @@ func (eth *EthAPI) Syncing() (common.SyncStatus, error) {
 			Result: false,
 		}, nil
 	default:
-		var err error
-		var resultBlob []byte
-		r := common.SyncStatus{
-			Result: true,
-		}
-		if resultBlob, err = json.Marshal(result); err == nil {
-			if err = json.Unmarshal(resultBlob, &r); err == nil {
-				return r, nil
-			}
+		r := &jsonSyncStatus{}
+		if err := resp.DecodeResult(r); err != nil {
+			return common.SyncStatus{
+				Result: false,
+			}, err
 		}
-
-		return common.SyncStatus{
-			Result: false,
-		}, err
+		return r.ToSyncStatus(), nil
 	}
 }
 
 // Coinbase returns the client coinbase address.
 func (eth *EthAPI) Coinbase() (addr common.Address, err error) {
+	return eth.CoinbaseContext(context.Background())
+}
+
+// CoinbaseContext is like Coinbase but takes a context.
+func (eth *EthAPI) CoinbaseContext(ctx context.Context) (addr common.Address, err error) {
 	req := eth.requestManager.newRequest("eth_coinbase")
-	resp, err := eth.requestManager.send(req)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return common.NewAddress(nil), err
 	}
 
-	if resp.Error() != nil {
-		return common.NewAddress(nil), resp.Error()
+	var result common.Address
+	if err := resp.DecodeResult(&result); err != nil {
+		return common.NewAddress(nil), err
 	}
-
-	return common.StringToAddress(resp.Get("result").(string)), nil
+	return result, nil
 }
 
 // Mining returns true if client is actively mining new blocks.
 func (eth *EthAPI) Mining() (bool, error) {
+	return eth.MiningContext(context.Background())
+}
+
+// MiningContext is like Mining but takes a context.
+func (eth *EthAPI) MiningContext(ctx context.Context) (bool, error) {
 	req := eth.requestManager.newRequest("eth_mining")
-	resp, err := eth.requestManager.send(req)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return false, err
 	}
 
-	if resp.Error() != nil {
-		return false, resp.Error()
+	var result bool
+	if err := resp.DecodeResult(&result); err != nil {
+		return false, err
 	}
-
-	return resp.Get("result").(bool), nil
+	return result, nil
 }
 
 // HashRate returns the number of hashes per second that the node is mining
 // with.
 func (eth *EthAPI) HashRate() (uint64, error) {
+	return eth.HashRateContext(context.Background())
+}
+
+// HashRateContext is like HashRate but takes a context.
+func (eth *EthAPI) HashRateContext(ctx context.Context) (uint64, error) {
 	req := eth.requestManager.newRequest("eth_hashrate")
-	resp, err := eth.requestManager.send(req)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return 0, err
 	}
 
-	if resp.Error() != nil {
-		return 0, resp.Error()
-	}
-
-	result, err := strconv.ParseUint(common.HexToString(resp.Get("result").(string)), 16, 64)
-	if err != nil {
+	var result common.HexUint64
+	if err := resp.DecodeResult(&result); err != nil {
 		return 0, err
 	}
-	return result, nil
+	return uint64(result), nil
 }
 
 // GasPrice returns the current price per gas in wei.
 func (eth *EthAPI) GasPrice() (result *big.Int, err error) {
+	return eth.GasPriceContext(context.Background())
+}
+
+// GasPriceContext is like GasPrice but takes a context.
+func (eth *EthAPI) GasPriceContext(ctx context.Context) (result *big.Int, err error) {
 	req := eth.requestManager.newRequest("eth_gasPrice")
-	resp, err := eth.requestManager.send(req)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Error() != nil {
-		return nil, resp.Error()
-	}
-
-	result = new(big.Int)
-	_, ok := result.SetString(common.HexToString(resp.Get("result").(string)), 16)
-	if !ok {
-		return nil, fmt.Errorf("%v", resp.Get("result"))
+	var quantity common.HexBig
+	if err := resp.DecodeResult(&quantity); err != nil {
+		return nil, err
 	}
-	return result, nil
+	return quantity.ToInt(), nil
 }
 
 // Accounts returns a list of addresses owned by client.
 func (eth *EthAPI) Accounts() (addrs []common.Address, err error) {
+	return eth.AccountsContext(context.Background())
+}
+
+// AccountsContext is like Accounts but takes a context.
+func (eth *EthAPI) AccountsContext(ctx context.Context) (addrs []common.Address, err error) {
 	req := eth.requestManager.newRequest("eth_accounts")
-	resp, err := eth.requestManager.send(req)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Error() != nil {
-		return nil, resp.Error()
-	}
-
-	results := resp.Get("result").([]interface{})
-	for _, r := range results {
-		addrs = append(addrs, common.StringToAddress(r.(string)))
+	if err := resp.DecodeResult(&addrs); err != nil {
+		return nil, err
 	}
 	return addrs, nil
 }
 
 // BlockNumber returns the number of most recent block.
 func (eth *EthAPI) BlockNumber() (result *big.Int, err error) {
+	return eth.BlockNumberContext(context.Background())
+}
+
+// BlockNumberContext is like BlockNumber but takes a context.
+func (eth *EthAPI) BlockNumberContext(ctx context.Context) (result *big.Int, err error) {
 	req := eth.requestManager.newRequest("eth_blockNumber")
-	resp, err := eth.requestManager.send(req)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Error() != nil {
-		return nil, resp.Error()
-	}
-
-	result = new(big.Int)
-	_, ok := result.SetString(common.HexToString(resp.Get("result").(string)), 16)
-	if !ok {
-		return nil, fmt.Errorf("%v", resp.Get("result"))
+	var quantity common.HexBig
+	if err := resp.DecodeResult(&quantity); err != nil {
+		return nil, err
 	}
-	return result, nil
+	return quantity.ToInt(), nil
 }
 
 // GetBalance returns the balance of the account of given address.
-func (eth *EthAPI) GetBalance(address common.Address, quantity string) (result *big.Int, err error) {
-	req := eth.requestManager.newRequest("eth_getBalance")
-	req.Set("params", []string{address.String(), quantity})
-	resp, err := eth.requestManager.send(req)
+func (eth *EthAPI) GetBalance(address common.Address, block common.BlockNumberOrHash) (result *big.Int, err error) {
+	return eth.GetBalanceContext(context.Background(), address, block)
+}
+
+// GetBalanceContext is like GetBalance but takes a context.
+func (eth *EthAPI) GetBalanceContext(ctx context.Context, address common.Address, block common.BlockNumberOrHash) (result *big.Int, err error) {
+	req := eth.requestManager.newRequest("eth_getBalance", address, block)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Error() != nil {
-		return nil, resp.Error()
-	}
-
-	result = new(big.Int)
-	_, ok := result.SetString(common.HexToString(resp.Get("result").(string)), 16)
-	if !ok {
-		return nil, fmt.Errorf("%v", resp.Get("result"))
+	var quantity common.HexBig
+	if err := resp.DecodeResult(&quantity); err != nil {
+		return nil, err
 	}
-	return result, nil
+	return quantity.ToInt(), nil
 }
 
 // GetStorageAt returns the value from a storage position at a given address.
-func (eth *EthAPI) GetStorageAt(address common.Address, position uint64, quantity string) (uint64, error) {
-	req := eth.requestManager.newRequest("eth_getStorageAt")
-	req.Set("params", []string{address.String(), fmt.Sprintf("%v", position), quantity})
-	resp, err := eth.requestManager.send(req)
+func (eth *EthAPI) GetStorageAt(address common.Address, position uint64, block common.BlockNumberOrHash) (uint64, error) {
+	return eth.GetStorageAtContext(context.Background(), address, position, block)
+}
+
+// GetStorageAtContext is like GetStorageAt but takes a context.
+func (eth *EthAPI) GetStorageAtContext(ctx context.Context, address common.Address, position uint64, block common.BlockNumberOrHash) (uint64, error) {
+	req := eth.requestManager.newRequest("eth_getStorageAt", address, common.Uint64ToHex(position), block)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return 0, err
 	}
 
-	if resp.Error() != nil {
-		return 0, resp.Error()
-	}
-
-	result, err := strconv.ParseUint(common.HexToString(resp.Get("result").(string)), 16, 64)
-	if err != nil {
+	var result common.HexBytes
+	if err := resp.DecodeResult(&result); err != nil {
 		return 0, err
 	}
-	return result, nil
+	value := new(big.Int).SetBytes(result)
+	if !value.IsUint64() {
+		return 0, fmt.Errorf("Storage value %s does not fit in 64 bits", result)
+	}
+	return value.Uint64(), nil
 }
 
 // GetTransactionCount returns the number of transactions sent from an address.
-func (eth *EthAPI) GetTransactionCount(address common.Address, quantity string) (result *big.Int, err error) {
-	req := eth.requestManager.newRequest("eth_getTransactionCount")
-	req.Set("params", []string{address.String(), quantity})
-	resp, err := eth.requestManager.send(req)
+func (eth *EthAPI) GetTransactionCount(address common.Address, block common.BlockNumberOrHash) (result *big.Int, err error) {
+	return eth.GetTransactionCountContext(context.Background(), address, block)
+}
+
+// GetTransactionCountContext is like GetTransactionCount but takes a context.
+func (eth *EthAPI) GetTransactionCountContext(ctx context.Context, address common.Address, block common.BlockNumberOrHash) (result *big.Int, err error) {
+	req := eth.requestManager.newRequest("eth_getTransactionCount", address, block)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Error() != nil {
-		return nil, resp.Error()
-	}
-
-	result = new(big.Int)
-	_, ok := result.SetString(common.HexToString(resp.Get("result").(string)), 16)
-	if !ok {
-		return nil, fmt.Errorf("%v", resp.Get("result"))
+	var quantity common.HexBig
+	if err := resp.DecodeResult(&quantity); err != nil {
+		return nil, err
 	}
-	return result, nil
+	return quantity.ToInt(), nil
 }
 
 // GetBlockTransactionCountByHash returns the number of transactions in a block
 // from a block matching the given block hash.
 func (eth *EthAPI) GetBlockTransactionCountByHash(hash common.Hash) (result *big.Int, err error) {
-	req := eth.requestManager.newRequest("eth_getBlockTransactionCountByHash")
-	req.Set("params", hash.String())
-	resp, err := eth.requestManager.send(req)
+	return eth.GetBlockTransactionCountByHashContext(context.Background(), hash)
+}
+
+// GetBlockTransactionCountByHashContext is like GetBlockTransactionCountByHash but takes a context.
+func (eth *EthAPI) GetBlockTransactionCountByHashContext(ctx context.Context, hash common.Hash) (result *big.Int, err error) {
+	req := eth.requestManager.newRequest("eth_getBlockTransactionCountByHash", hash)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Error() != nil {
-		return nil, resp.Error()
-	}
-
-	result = new(big.Int)
-	_, ok := result.SetString(common.HexToString(resp.Get("result").(string)), 16)
-	if !ok {
-		return nil, fmt.Errorf("%v", resp.Get("result"))
+	var quantity common.HexBig
+	if err := resp.DecodeResult(&quantity); err != nil {
+		return nil, err
 	}
-	return result, nil
+	return quantity.ToInt(), nil
 }
 
 // GetBlockTransactionCountByNumber returns the number of transactions in a
 // block from a block matching the given block number.
-func (eth *EthAPI) GetBlockTransactionCountByNumber(quantity string) (result *big.Int, err error) {
-	req := eth.requestManager.newRequest("eth_getBlockTransactionCountByNumber")
-	req.Set("params", quantity)
-	resp, err := eth.requestManager.send(req)
+func (eth *EthAPI) GetBlockTransactionCountByNumber(number common.BlockNumber) (result *big.Int, err error) {
+	return eth.GetBlockTransactionCountByNumberContext(context.Background(), number)
+}
+
+// GetBlockTransactionCountByNumberContext is like GetBlockTransactionCountByNumber but takes a context.
+func (eth *EthAPI) GetBlockTransactionCountByNumberContext(ctx context.Context, number common.BlockNumber) (result *big.Int, err error) {
+	req := eth.requestManager.newRequest("eth_getBlockTransactionCountByNumber", number)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Error() != nil {
-		return nil, resp.Error()
-	}
-
-	result = new(big.Int)
-	_, ok := result.SetString(common.HexToString(resp.Get("result").(string)), 16)
-	if !ok {
-		return nil, fmt.Errorf("%v", resp.Get("result"))
+	var quantity common.HexBig
+	if err := resp.DecodeResult(&quantity); err != nil {
+		return nil, err
 	}
-	return result, nil
+	return quantity.ToInt(), nil
 }
 
 // GetUncleCountByBlockHash returns the number of uncles in a block from a block
 // matching the given block hash.
 func (eth *EthAPI) GetUncleCountByBlockHash(hash common.Hash) (result *big.Int, err error) {
-	req := eth.requestManager.newRequest("eth_getUncleCountByBlockHash")
-	req.Set("params", hash.String())
-	resp, err := eth.requestManager.send(req)
+	return eth.GetUncleCountByBlockHashContext(context.Background(), hash)
+}
+
+// GetUncleCountByBlockHashContext is like GetUncleCountByBlockHash but takes a context.
+func (eth *EthAPI) GetUncleCountByBlockHashContext(ctx context.Context, hash common.Hash) (result *big.Int, err error) {
+	req := eth.requestManager.newRequest("eth_getUncleCountByBlockHash", hash)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Error() != nil {
-		return nil, resp.Error()
-	}
-
-	result = new(big.Int)
-	_, ok := result.SetString(common.HexToString(resp.Get("result").(string)), 16)
-	if !ok {
-		return nil, fmt.Errorf("%v", resp.Get("result"))
+	var quantity common.HexBig
+	if err := resp.DecodeResult(&quantity); err != nil {
+		return nil, err
 	}
-	return result, nil
+	return quantity.ToInt(), nil
 }
 
 // GetUncleCountByBlockNumber returns the number of uncles in a block from a
 // block matching the given block number.
-func (eth *EthAPI) GetUncleCountByBlockNumber(quantity string) (result *big.Int, err error) {
-	req := eth.requestManager.newRequest("eth_getUncleCountByBlockNumber")
-	req.Set("params", quantity)
-	resp, err := eth.requestManager.send(req)
+func (eth *EthAPI) GetUncleCountByBlockNumber(number common.BlockNumber) (result *big.Int, err error) {
+	return eth.GetUncleCountByBlockNumberContext(context.Background(), number)
+}
+
+// GetUncleCountByBlockNumberContext is like GetUncleCountByBlockNumber but takes a context.
+func (eth *EthAPI) GetUncleCountByBlockNumberContext(ctx context.Context, number common.BlockNumber) (result *big.Int, err error) {
+	req := eth.requestManager.newRequest("eth_getUncleCountByBlockNumber", number)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Error() != nil {
-		return nil, resp.Error()
-	}
-
-	result = new(big.Int)
-	_, ok := result.SetString(common.HexToString(resp.Get("result").(string)), 16)
-	if !ok {
-		return nil, fmt.Errorf("%v", resp.Get("result"))
+	var quantity common.HexBig
+	if err := resp.DecodeResult(&quantity); err != nil {
+		return nil, err
 	}
-	return result, nil
+	return quantity.ToInt(), nil
 }
 
 // GetCode returns code at a given address.
-func (eth *EthAPI) GetCode(address common.Address, quantity string) ([]byte, error) {
-	req := eth.requestManager.newRequest("eth_getCode")
-	req.Set("params", []string{address.String(), quantity})
-	resp, err := eth.requestManager.send(req)
+func (eth *EthAPI) GetCode(address common.Address, block common.BlockNumberOrHash) ([]byte, error) {
+	return eth.GetCodeContext(context.Background(), address, block)
+}
+
+// GetCodeContext is like GetCode but takes a context.
+func (eth *EthAPI) GetCodeContext(ctx context.Context, address common.Address, block common.BlockNumberOrHash) ([]byte, error) {
+	req := eth.requestManager.newRequest("eth_getCode", address, block)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Error() != nil {
-		return nil, resp.Error()
+	var result common.HexBytes
+	if err := resp.DecodeResult(&result); err != nil {
+		return nil, err
 	}
-
-	return common.HexToBytes(resp.Get("result").(string)), nil
+	return result, nil
 }
 
 // Sign signs data with a given address.
 func (eth *EthAPI) Sign(address common.Address, data []byte) ([]byte, error) {
-	req := eth.requestManager.newRequest("eth_sign")
-	req.Set("params", []string{address.String(), string(data)})
-	resp, err := eth.requestManager.send(req)
+	return eth.SignContext(context.Background(), address, data)
+}
+
+// SignContext is like Sign but takes a context.
+func (eth *EthAPI) SignContext(ctx context.Context, address common.Address, data []byte) ([]byte, error) {
+	req := eth.requestManager.newRequest("eth_sign", address, common.BytesToHex(data))
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Error() != nil {
-		return nil, resp.Error()
+	var result common.HexBytes
+	if err := resp.DecodeResult(&result); err != nil {
+		return nil, err
 	}
-
-	return common.HexToBytes(resp.Get("result").(string)), nil
+	return result, nil
 }
 
 // SendTransaction creates new message call transaction or a contract creation,
 // if the data field contains code.
 func (eth *EthAPI) SendTransaction(tx *common.TransactionRequest) (hash common.Hash, err error) {
-	req := eth.requestManager.newRequest("eth_sendTransaction")
-	req.Set("params", []string{tx.String()})
-	resp, err := eth.requestManager.send(req)
+	return eth.SendTransactionContext(context.Background(), tx)
+}
+
+// SendTransactionContext is like SendTransaction but takes a context.
+func (eth *EthAPI) SendTransactionContext(ctx context.Context, tx *common.TransactionRequest) (hash common.Hash, err error) {
+	req := eth.requestManager.newRequest("eth_sendTransaction", tx)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return common.NewHash(nil), err
 	}
 
-	if resp.Error() != nil {
-		return common.NewHash(nil), resp.Error()
+	var result common.Hash
+	if err := resp.DecodeResult(&result); err != nil {
+		return common.NewHash(nil), err
 	}
-
-	return common.StringToHash(resp.Get("result").(string)), nil
+	return result, nil
 }
 
 // SendRawTransaction creates new message call transaction or a contract
 // creation for signed transactions.
 func (eth *EthAPI) SendRawTransaction(tx []byte) (hash common.Hash, err error) {
-	req := eth.requestManager.newRequest("eth_sendRawTransaction")
-	req.Set("params", []string{common.BytesToHex(tx)})
-	resp, err := eth.requestManager.send(req)
+	return eth.SendRawTransactionContext(context.Background(), tx)
+}
+
+// SendRawTransactionContext is like SendRawTransaction but takes a context.
+func (eth *EthAPI) SendRawTransactionContext(ctx context.Context, tx []byte) (hash common.Hash, err error) {
+	req := eth.requestManager.newRequest("eth_sendRawTransaction", common.BytesToHex(tx))
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return common.NewHash(nil), err
 	}
 
-	if resp.Error() != nil {
-		return common.NewHash(nil), resp.Error()
+	var result common.Hash
+	if err := resp.DecodeResult(&result); err != nil {
+		return common.NewHash(nil), err
 	}
-
-	return common.StringToHash(resp.Get("result").(string)), nil
+	return result, nil
 }
 
 // Call executes a new message call immediately without creating a transaction
 // on the block chain.
-func (eth *EthAPI) Call(tx *common.TransactionRequest, quantity string) ([]byte, error) {
-	req := eth.requestManager.newRequest("eth_call")
-	req.Set("params", []string{tx.String(), quantity})
-	resp, err := eth.requestManager.send(req)
+func (eth *EthAPI) Call(tx *common.TransactionRequest, block common.BlockNumberOrHash) ([]byte, error) {
+	return eth.CallContext(context.Background(), tx, block)
+}
+
+// CallContext is like Call but takes a context.
+func (eth *EthAPI) CallContext(ctx context.Context, tx *common.TransactionRequest, block common.BlockNumberOrHash) ([]byte, error) {
+	req := eth.requestManager.newRequest("eth_call", tx, block)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Error() != nil {
-		return nil, resp.Error()
+	var result common.HexBytes
+	if err := resp.DecodeResult(&result); err != nil {
+		return nil, err
 	}
-
-	return common.HexToBytes(resp.Get("result").(string)), nil
+	return result, nil
 }
 
 // EstimateGas makes a call or transaction, which won't be added to the
 // blockchain and returns the used gas, which can be used for estimating the
 // used gas.
-func (eth *EthAPI) EstimateGas(tx *common.TransactionRequest, quantity string) (result *big.Int, err error) {
-	req := eth.requestManager.newRequest("eth_estimateGas")
-	req.Set("params", []string{tx.String(), quantity})
-	resp, err := eth.requestManager.send(req)
+func (eth *EthAPI) EstimateGas(tx *common.TransactionRequest, block common.BlockNumberOrHash) (result *big.Int, err error) {
+	return eth.EstimateGasContext(context.Background(), tx, block)
+}
+
+// EstimateGasContext is like EstimateGas but takes a context.
+func (eth *EthAPI) EstimateGasContext(ctx context.Context, tx *common.TransactionRequest, block common.BlockNumberOrHash) (result *big.Int, err error) {
+	req := eth.requestManager.newRequest("eth_estimateGas", tx, block)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Error() != nil {
-		return nil, resp.Error()
-	}
-
-	result = new(big.Int)
-	_, ok := result.SetString(common.HexToString(resp.Get("result").(string)), 16)
-	if !ok {
-		return nil, fmt.Errorf("%v", resp.Get("result"))
+	var quantity common.HexBig
+	if err := resp.DecodeResult(&quantity); err != nil {
+		return nil, err
 	}
-	return result, nil
+	return quantity.ToInt(), nil
 }
 
 // GetBlockByHash returns information about a block by hash.
 func (eth *EthAPI) GetBlockByHash(hash common.Hash, full bool) (*common.Block, error) {
-	req := eth.requestManager.newRequest("eth_getBlockByHash")
-	req.Set("params", []interface{}{hash.String(), full})
-	resp, err := eth.requestManager.send(req)
+	return eth.GetBlockByHashContext(context.Background(), hash, full)
+}
+
+// GetBlockByHashContext is like GetBlockByHash but takes a context.
+func (eth *EthAPI) GetBlockByHashContext(ctx context.Context, hash common.Hash, full bool) (*common.Block, error) {
+	req := eth.requestManager.newRequest("eth_getBlockByHash", hash, full)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -531,21 +614,22 @@ func (eth *EthAPI) GetBlockByHash(hash common.Hash, full bool) (*common.Block, e
 		return nil, resp.Error()
 	}
 
-	result := &jsonBlock{}
-	if jsonBytes, err := json.Marshal(resp.Get("result")); err == nil {
-		if err := json.Unmarshal(jsonBytes, result); err == nil {
-			return result.ToBlock(), nil
-		}
+	result := newJSONBlock(full)
+	if err := decodeResult(resp, result); err != nil {
+		return nil, err
 	}
-
-	return nil, fmt.Errorf("%v", resp.Get("result"))
+	return result.ToBlock(), nil
 }
 
 // GetBlockByNumber returns information about a block by block number.
-func (eth *EthAPI) GetBlockByNumber(quantity string, full bool) (*common.Block, error) {
-	req := eth.requestManager.newRequest("eth_getBlockByNumber")
-	req.Set("params", []interface{}{quantity, full})
-	resp, err := eth.requestManager.send(req)
+func (eth *EthAPI) GetBlockByNumber(number common.BlockNumber, full bool) (*common.Block, error) {
+	return eth.GetBlockByNumberContext(context.Background(), number, full)
+}
+
+// GetBlockByNumberContext is like GetBlockByNumber but takes a context.
+func (eth *EthAPI) GetBlockByNumberContext(ctx context.Context, number common.BlockNumber, full bool) (*common.Block, error) {
+	req := eth.requestManager.newRequest("eth_getBlockByNumber", number, full)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -554,22 +638,91 @@ func (eth *EthAPI) GetBlockByNumber(quantity string, full bool) (*common.Block,
 		return nil, resp.Error()
 	}
 
-	result := &jsonBlock{}
-	if jsonBytes, err := json.Marshal(resp.Get("result")); err == nil {
-		if err := json.Unmarshal(jsonBytes, result); err == nil {
-			return result.ToBlock(), nil
+	result := newJSONBlock(full)
+	if err := decodeResult(resp, result); err != nil {
+		return nil, err
+	}
+	return result.ToBlock(), nil
+}
+
+// GetBlockWithReceipts returns a block with its full transactions, each paired
+// with its receipt. The receipts are fetched in a single batch. A block named
+// by hash with RequireCanonical is checked against the canonical block at its
+// number, at the cost of one more request.
+func (eth *EthAPI) GetBlockWithReceipts(block common.BlockNumberOrHash) (*common.BlockWithReceipts, error) {
+	return eth.GetBlockWithReceiptsContext(context.Background(), block)
+}
+
+// GetBlockWithReceiptsContext is like GetBlockWithReceipts but takes a context.
+func (eth *EthAPI) GetBlockWithReceiptsContext(ctx context.Context, block common.BlockNumberOrHash) (*common.BlockWithReceipts, error) {
+	var result *common.Block
+	var err error
+	if hash, ok := block.Hash(); ok {
+		result, err = eth.GetBlockByHashContext(ctx, hash, true)
+		if err == nil && block.RequireCanonical {
+			err = eth.checkCanonical(ctx, result)
 		}
+	} else {
+		number, _ := block.Number()
+		result, err = eth.GetBlockByNumberContext(ctx, number, true)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, fmt.Errorf("%v", resp.Get("result"))
+	txs, ok := result.Transactions.Full()
+	if !ok {
+		return nil, fmt.Errorf("Block %s has transaction hashes instead of full transactions", result.Hash)
+	}
+	transactions := make([]common.TransactionWithReceipt, len(txs))
+	batch := &Batch{requestManager: eth.requestManager}
+	for i, tx := range txs {
+		transactions[i].Transaction = tx
+		batch.GetTransactionReceipt(tx.Hash, &transactions[i].Receipt)
+	}
+	if batch.Len() > 0 {
+		if err := batch.ExecuteContext(ctx); err != nil {
+			return nil, err
+		}
+		for _, call := range batch.calls {
+			if call.Err != nil {
+				return nil, call.Err
+			}
+		}
+	}
+	return &common.BlockWithReceipts{Block: result, Transactions: transactions}, nil
+}
+
+// checkCanonical fails unless block is the one of the canonical chain at its
+// number.
+func (eth *EthAPI) checkCanonical(ctx context.Context, block *common.Block) error {
+	if block.Number == nil {
+		return fmt.Errorf("Block %s has no number", block.Hash)
+	}
+	number, err := common.BigToBlockNumber(block.Number)
+	if err != nil {
+		return err
+	}
+	canonical, err := eth.GetBlockByNumberContext(ctx, number, false)
+	if err != nil {
+		return err
+	}
+	if canonical.Hash != block.Hash {
+		return fmt.Errorf("Block %s is not canonical", block.Hash)
+	}
+	return nil
 }
 
 // GetTransactionByHash returns the information about a transaction requested by
 // transaction hash.
 func (eth *EthAPI) GetTransactionByHash(hash common.Hash) (*common.Transaction, error) {
-	req := eth.requestManager.newRequest("eth_getTransactionByHash")
-	req.Set("params", hash.String())
-	resp, err := eth.requestManager.send(req)
+	return eth.GetTransactionByHashContext(context.Background(), hash)
+}
+
+// GetTransactionByHashContext is like GetTransactionByHash but takes a context.
+func (eth *EthAPI) GetTransactionByHashContext(ctx context.Context, hash common.Hash) (*common.Transaction, error) {
+	req := eth.requestManager.newRequest("eth_getTransactionByHash", hash)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -579,21 +732,22 @@ func (eth *EthAPI) GetTransactionByHash(hash common.Hash) (*common.Transaction,
 	}
 
 	result := &jsonTransaction{}
-	if jsonBytes, err := json.Marshal(resp.Get("result")); err == nil {
-		if err := json.Unmarshal(jsonBytes, result); err == nil {
-			return result.ToTransaction(), nil
-		}
+	if err := decodeResult(resp, result); err != nil {
+		return nil, err
 	}
-
-	return nil, fmt.Errorf("%v", resp.Get("result"))
+	return result.ToTransaction(), nil
 }
 
 // GetTransactionByBlockHashAndIndex returns information about a transaction by
 // block hash and transaction index position.
 func (eth *EthAPI) GetTransactionByBlockHashAndIndex(hash common.Hash, index uint64) (*common.Transaction, error) {
-	req := eth.requestManager.newRequest("eth_getTransactionByBlockHashAndIndex")
-	req.Set("params", []string{hash.String(), fmt.Sprintf("%v", index)})
-	resp, err := eth.requestManager.send(req)
+	return eth.GetTransactionByBlockHashAndIndexContext(context.Background(), hash, index)
+}
+
+// GetTransactionByBlockHashAndIndexContext is like GetTransactionByBlockHashAndIndex but takes a context.
+func (eth *EthAPI) GetTransactionByBlockHashAndIndexContext(ctx context.Context, hash common.Hash, index uint64) (*common.Transaction, error) {
+	req := eth.requestManager.newRequest("eth_getTransactionByBlockHashAndIndex", hash, common.Uint64ToHex(index))
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -603,21 +757,22 @@ func (eth *EthAPI) GetTransactionByBlockHashAndIndex(hash common.Hash, index uin
 	}
 
 	result := &jsonTransaction{}
-	if jsonBytes, err := json.Marshal(resp.Get("result")); err == nil {
-		if err := json.Unmarshal(jsonBytes, result); err == nil {
-			return result.ToTransaction(), nil
-		}
+	if err := decodeResult(resp, result); err != nil {
+		return nil, err
 	}
-
-	return nil, fmt.Errorf("%v", resp.Get("result"))
+	return result.ToTransaction(), nil
 }
 
 // GetTransactionByBlockNumberAndIndex returns information about a transaction
 // by block number and transaction index position.
-func (eth *EthAPI) GetTransactionByBlockNumberAndIndex(quantity string, index uint64) (*common.Transaction, error) {
-	req := eth.requestManager.newRequest("eth_getTransactionByBlockNumberAndIndex")
-	req.Set("params", []string{quantity, fmt.Sprintf("%v", index)})
-	resp, err := eth.requestManager.send(req)
+func (eth *EthAPI) GetTransactionByBlockNumberAndIndex(number common.BlockNumber, index uint64) (*common.Transaction, error) {
+	return eth.GetTransactionByBlockNumberAndIndexContext(context.Background(), number, index)
+}
+
+// GetTransactionByBlockNumberAndIndexContext is like GetTransactionByBlockNumberAndIndex but takes a context.
+func (eth *EthAPI) GetTransactionByBlockNumberAndIndexContext(ctx context.Context, number common.BlockNumber, index uint64) (*common.Transaction, error) {
+	req := eth.requestManager.newRequest("eth_getTransactionByBlockNumberAndIndex", number, common.Uint64ToHex(index))
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -627,20 +782,21 @@ func (eth *EthAPI) GetTransactionByBlockNumberAndIndex(quantity string, index ui
 	}
 
 	result := &jsonTransaction{}
-	if jsonBytes, err := json.Marshal(resp.Get("result")); err == nil {
-		if err := json.Unmarshal(jsonBytes, result); err == nil {
-			return result.ToTransaction(), nil
-		}
+	if err := decodeResult(resp, result); err != nil {
+		return nil, err
 	}
-
-	return nil, fmt.Errorf("%v", resp.Get("result"))
+	return result.ToTransaction(), nil
 }
 
 // GetTransactionReceipt Returns the receipt of a transaction by transaction hash.
 func (eth *EthAPI) GetTransactionReceipt(hash common.Hash) (*common.TransactionReceipt, error) {
-	req := eth.requestManager.newRequest("eth_getTransactionReceipt")
-	req.Set("params", hash.String())
-	resp, err := eth.requestManager.send(req)
+	return eth.GetTransactionReceiptContext(context.Background(), hash)
+}
+
+// GetTransactionReceiptContext is like GetTransactionReceipt but takes a context.
+func (eth *EthAPI) GetTransactionReceiptContext(ctx context.Context, hash common.Hash) (*common.TransactionReceipt, error) {
+	req := eth.requestManager.newRequest("eth_getTransactionReceipt", hash)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -650,21 +806,22 @@ func (eth *EthAPI) GetTransactionReceipt(hash common.Hash) (*common.TransactionR
 	}
 
 	result := &jsonTransactionReceipt{}
-	if jsonBytes, err := json.Marshal(resp.Get("result")); err == nil {
-		if err := json.Unmarshal(jsonBytes, result); err == nil {
-			return result.ToTransactionReceipt(), nil
-		}
+	if err := decodeResult(resp, result); err != nil {
+		return nil, err
 	}
-
-	return nil, fmt.Errorf("%v", resp.Get("result"))
+	return result.ToTransactionReceipt(), nil
 }
 
 // GetUncleByBlockHashAndIndex returns information about a uncle of a block by
 // hash and uncle index position.
 func (eth *EthAPI) GetUncleByBlockHashAndIndex(hash common.Hash, index uint64) (*common.Block, error) {
-	req := eth.requestManager.newRequest("eth_getUncleByBlockHashAndIndex")
-	req.Set("params", []string{hash.String(), fmt.Sprintf("%d", index)})
-	resp, err := eth.requestManager.send(req)
+	return eth.GetUncleByBlockHashAndIndexContext(context.Background(), hash, index)
+}
+
+// GetUncleByBlockHashAndIndexContext is like GetUncleByBlockHashAndIndex but takes a context.
+func (eth *EthAPI) GetUncleByBlockHashAndIndexContext(ctx context.Context, hash common.Hash, index uint64) (*common.Block, error) {
+	req := eth.requestManager.newRequest("eth_getUncleByBlockHashAndIndex", hash, common.Uint64ToHex(index))
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -674,21 +831,22 @@ func (eth *EthAPI) GetUncleByBlockHashAndIndex(hash common.Hash, index uint64) (
 	}
 
 	result := &jsonBlock{}
-	if jsonBytes, err := json.Marshal(resp.Get("result")); err == nil {
-		if err := json.Unmarshal(jsonBytes, result); err == nil {
-			return result.ToBlock(), nil
-		}
+	if err := decodeResult(resp, result); err != nil {
+		return nil, err
 	}
-
-	return nil, fmt.Errorf("%v", resp.Get("result"))
+	return result.ToBlock(), nil
 }
 
 // GetUncleByBlockNumberAndIndex returns information about a uncle of a block by
 // number and uncle index position.
-func (eth *EthAPI) GetUncleByBlockNumberAndIndex(quantity string, index uint64) (*common.Block, error) {
-	req := eth.requestManager.newRequest("eth_getUncleByBlockNumberAndIndex")
-	req.Set("params", []string{quantity, fmt.Sprintf("%d", index)})
-	resp, err := eth.requestManager.send(req)
+func (eth *EthAPI) GetUncleByBlockNumberAndIndex(number common.BlockNumber, index uint64) (*common.Block, error) {
+	return eth.GetUncleByBlockNumberAndIndexContext(context.Background(), number, index)
+}
+
+// GetUncleByBlockNumberAndIndexContext is like GetUncleByBlockNumberAndIndex but takes a context.
+func (eth *EthAPI) GetUncleByBlockNumberAndIndexContext(ctx context.Context, number common.BlockNumber, index uint64) (*common.Block, error) {
+	req := eth.requestManager.newRequest("eth_getUncleByBlockNumberAndIndex", number, common.Uint64ToHex(index))
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -698,29 +856,27 @@ func (eth *EthAPI) GetUncleByBlockNumberAndIndex(quantity string, index uint64)
 	}
 
 	result := &jsonBlock{}
-	if jsonBytes, err := json.Marshal(resp.Get("result")); err == nil {
-		if err := json.Unmarshal(jsonBytes, result); err == nil {
-			return result.ToBlock(), nil
-		}
+	if err := decodeResult(resp, result); err != nil {
+		return nil, err
 	}
-
-	return nil, fmt.Errorf("%v", resp.Get("result"))
+	return result.ToBlock(), nil
 }
 
 // GetCompilers returns a list of available compilers in the client.
 func (eth *EthAPI) GetCompilers() (result []string, err error) {
+	return eth.GetCompilersContext(context.Background())
+}
+
+// GetCompilersContext is like GetCompilers but takes a context.
+func (eth *EthAPI) GetCompilersContext(ctx context.Context) (result []string, err error) {
 	req := eth.requestManager.newRequest("eth_getCompilers")
-	resp, err := eth.requestManager.send(req)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Error() != nil {
-		return nil, resp.Error()
-	}
-
-	for _, r := range resp.Get("result").([]interface{}) {
-		result = append(result, r.(string))
+	if err := resp.DecodeResult(&result); err != nil {
+		return nil, err
 	}
 	return result, nil
 }
@@ -729,21 +885,21 @@ func (eth *EthAPI) GetCompilers() (result []string, err error) {
 // the state changes (logs). To check if the state has changed, call
 // eth_getFilterChanges.
 func (eth *EthAPI) NewFilter(option *FilterOption) (Filter, error) {
-	req := eth.requestManager.newRequest("eth_newFilter")
+	return eth.NewFilterContext(context.Background(), option)
+}
+
+// NewFilterContext is like NewFilter but takes a context.
+func (eth *EthAPI) NewFilterContext(ctx context.Context, option *FilterOption) (Filter, error) {
 	if option == nil {
 		option = &FilterOption{}
 	}
-	req.Set("params", option)
-	resp, err := eth.requestManager.send(req)
+	req := eth.requestManager.newRequest("eth_newFilter", option)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Error() != nil {
-		return nil, resp.Error()
-	}
-
-	id, err := strconv.ParseUint(common.HexToString(resp.Get("result").(string)), 16, 64)
+	id, err := decodeFilterID(resp)
 	if err != nil {
 		return nil, err
 	}
@@ -753,17 +909,18 @@ func (eth *EthAPI) NewFilter(option *FilterOption) (Filter, error) {
 // NewBlockFilter creates a filter in the node, to notify when a new block
 // arrives. To check if the state has changed, call eth_getFilterChanges.
 func (eth *EthAPI) NewBlockFilter() (Filter, error) {
+	return eth.NewBlockFilterContext(context.Background())
+}
+
+// NewBlockFilterContext is like NewBlockFilter but takes a context.
+func (eth *EthAPI) NewBlockFilterContext(ctx context.Context) (Filter, error) {
 	req := eth.requestManager.newRequest("eth_newBlockFilter")
-	resp, err := eth.requestManager.send(req)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Error() != nil {
-		return nil, resp.Error()
-	}
-
-	id, err := strconv.ParseUint(common.HexToString(resp.Get("result").(string)), 16, 64)
+	id, err := decodeFilterID(resp)
 	if err != nil {
 		return nil, err
 	}
@@ -774,126 +931,195 @@ func (eth *EthAPI) NewBlockFilter() (Filter, error) {
 // pending transactions arrive. To check if the state has changed, call
 // eth_getFilterChanges.
 func (eth *EthAPI) NewPendingTransactionFilter() (Filter, error) {
+	return eth.NewPendingTransactionFilterContext(context.Background())
+}
+
+// NewPendingTransactionFilterContext is like NewPendingTransactionFilter but takes a context.
+func (eth *EthAPI) NewPendingTransactionFilterContext(ctx context.Context) (Filter, error) {
 	req := eth.requestManager.newRequest("eth_newPendingTransactionFilter")
-	resp, err := eth.requestManager.send(req)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Error() != nil {
-		return nil, resp.Error()
-	}
-
-	id, err := strconv.ParseUint(common.HexToString(resp.Get("result").(string)), 16, 64)
+	id, err := decodeFilterID(resp)
 	if err != nil {
 		return nil, err
 	}
 	return newFilter(eth, TypeTransactionFilter, id), nil
 }
 
+// decodeFilterID decodes the id of a new filter. Filter ids are hex strings
+// that may have leading zeros, so they are not decoded as quantities.
+func decodeFilterID(resp rpc.Response) (uint64, error) {
+	var id string
+	if err := resp.DecodeResult(&id); err != nil {
+		return 0, err
+	}
+	if !strings.HasPrefix(id, "0x") {
+		return 0, fmt.Errorf("Invalid filter id %q", id)
+	}
+	return strconv.ParseUint(id[2:], 16, 64)
+}
+
 // UninstallFilter uninstalls a filter with given id. Should always be called
 // when watch is no longer needed. Additonally Filters timeout when they aren't
 // requested with eth_getFilterChanges for a period of time.
 func (eth *EthAPI) UninstallFilter(filter Filter) (bool, error) {
-	req := eth.requestManager.newRequest("eth_uninstallFilter")
-	req.Set("params", fmt.Sprintf("0x%x", filter.ID()))
-	resp, err := eth.requestManager.send(req)
+	return eth.UninstallFilterContext(context.Background(), filter)
+}
+
+// UninstallFilterContext is like UninstallFilter but takes a context.
+func (eth *EthAPI) UninstallFilterContext(ctx context.Context, filter Filter) (bool, error) {
+	req := eth.requestManager.newRequest("eth_uninstallFilter", common.Uint64ToHex(filter.ID()))
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return false, err
 	}
 
-	if resp.Error() != nil {
-		return false, resp.Error()
+	var result bool
+	if err := resp.DecodeResult(&result); err != nil {
+		return false, err
 	}
-
-	return resp.Get("result").(bool), nil
+	return result, nil
 }
 
 // GetFilterChanges polling method for a filter, which returns an array of logs
 // which occurred since last poll.
 func (eth *EthAPI) GetFilterChanges(filter Filter) (result []interface{}, err error) {
-	req := eth.requestManager.newRequest("eth_getFilterChanges")
-	req.Set("params", fmt.Sprintf("0x%x", filter.ID()))
-	resp, err := eth.requestManager.send(req)
+	return eth.GetFilterChangesContext(context.Background(), filter)
+}
+
+// GetFilterChangesContext is like GetFilterChanges but takes a context.
+func (eth *EthAPI) GetFilterChangesContext(ctx context.Context, filter Filter) (result []interface{}, err error) {
+	req := eth.requestManager.newRequest("eth_getFilterChanges", common.Uint64ToHex(filter.ID()))
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Error() != nil {
-		return nil, resp.Error()
+	if err := resp.DecodeResult(&result); err != nil {
+		return nil, err
 	}
-
-	return resp.Get("result").([]interface{}), nil
+	return result, nil
 }
 
 // GetFilterLogs returns an array of all logs matching filter with given id.
 func (eth *EthAPI) GetFilterLogs(filter Filter) (result []interface{}, err error) {
-	req := eth.requestManager.newRequest("eth_getFilterLogs")
-	req.Set("params", fmt.Sprintf("0x%x", filter.ID()))
-	resp, err := eth.requestManager.send(req)
+	return eth.GetFilterLogsContext(context.Background(), filter)
+}
+
+// GetFilterLogsContext is like GetFilterLogs but takes a context.
+func (eth *EthAPI) GetFilterLogsContext(ctx context.Context, filter Filter) (result []interface{}, err error) {
+	req := eth.requestManager.newRequest("eth_getFilterLogs", common.Uint64ToHex(filter.ID()))
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Error() != nil {
-		return nil, resp.Error()
+	if err := resp.DecodeResult(&result); err != nil {
+		return nil, err
 	}
-
-	return resp.Get("result").([]interface{}), nil
+	return result, nil
 }
 
 // GetLogs returns an array of all logs matching a given filter object.
 func (eth *EthAPI) GetLogs(filter Filter) (result []interface{}, err error) {
-	req := eth.requestManager.newRequest("eth_getLogs")
-	req.Set("params", fmt.Sprintf("0x%x", filter.ID()))
-	resp, err := eth.requestManager.send(req)
+	return eth.GetLogsContext(context.Background(), filter)
+}
+
+// GetLogsContext is like GetLogs but takes a context.
+func (eth *EthAPI) GetLogsContext(ctx context.Context, filter Filter) (result []interface{}, err error) {
+	req := eth.requestManager.newRequest("eth_getLogs", common.Uint64ToHex(filter.ID()))
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Error() != nil {
-		return nil, resp.Error()
+	if err := resp.DecodeResult(&result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SubscribeNewHeads subscribes to new block headers. Each notification is a
+// header, including during chain reorganizations.
+func (eth *EthAPI) SubscribeNewHeads() (Subscription, error) {
+	return eth.SubscribeNewHeadsContext(context.Background())
+}
+
+// SubscribeNewHeadsContext is like SubscribeNewHeads but takes a context. The
+// context only bounds the subscription call; use Close to end the subscription.
+func (eth *EthAPI) SubscribeNewHeadsContext(ctx context.Context) (Subscription, error) {
+	return eth.subscribe(ctx, "newHeads")
+}
+
+// SubscribeLogs subscribes to logs matching the filter options that are
+// included in new blocks.
+func (eth *EthAPI) SubscribeLogs(option *FilterOption) (Subscription, error) {
+	return eth.SubscribeLogsContext(context.Background(), option)
+}
+
+// SubscribeLogsContext is like SubscribeLogs but takes a context.
+func (eth *EthAPI) SubscribeLogsContext(ctx context.Context, option *FilterOption) (Subscription, error) {
+	if option == nil {
+		option = &FilterOption{}
 	}
+	return eth.subscribe(ctx, "logs", option)
+}
 
-	return resp.Get("result").([]interface{}), nil
+// SubscribeNewPendingTransactions subscribes to the hashes of transactions
+// that are added to the pending state.
+func (eth *EthAPI) SubscribeNewPendingTransactions() (Subscription, error) {
+	return eth.SubscribeNewPendingTransactionsContext(context.Background())
+}
+
+// SubscribeNewPendingTransactionsContext is like
+// SubscribeNewPendingTransactions but takes a context.
+func (eth *EthAPI) SubscribeNewPendingTransactionsContext(ctx context.Context) (Subscription, error) {
+	return eth.subscribe(ctx, "newPendingTransactions")
 }
 
 // GetWork returns the hash of the current block, the seedHash, and the boundary
 // condition to be met ("target").
 func (eth *EthAPI) GetWork() (header, seed, boundary common.Hash, err error) {
+	return eth.GetWorkContext(context.Background())
+}
+
+// GetWorkContext is like GetWork but takes a context.
+func (eth *EthAPI) GetWorkContext(ctx context.Context) (header, seed, boundary common.Hash, err error) {
 	req := eth.requestManager.newRequest("eth_getWork")
-	resp, err := eth.requestManager.send(req)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return common.NewHash(nil), common.NewHash(nil), common.NewHash(nil), err
 	}
 
-	if resp.Error() != nil {
-		return common.NewHash(nil), common.NewHash(nil), common.NewHash(nil), resp.Error()
+	var results []common.Hash
+	if err := resp.DecodeResult(&results); err != nil {
+		return common.NewHash(nil), common.NewHash(nil), common.NewHash(nil), err
 	}
-
-	results := resp.Get("result").([]interface{})
-	header = common.StringToHash(results[0].(string))
-	seed = common.StringToHash(results[1].(string))
-	boundary = common.StringToHash(results[2].(string))
-	return header, seed, boundary, nil
+	if len(results) != 3 {
+		return common.NewHash(nil), common.NewHash(nil), common.NewHash(nil), fmt.Errorf("Invalid work %v, want 3 hashes", results)
+	}
+	return results[0], results[1], results[2], nil
 }
 
 // SubmitWork is used for submitting a proof-of-work solution.
 func (eth *EthAPI) SubmitWork(nonce uint64, header, mixDigest common.Hash) (bool, error) {
-	req := eth.requestManager.newRequest("eth_submitWork")
-	req.Set("params", []string{
-		fmt.Sprintf("0x%16x", nonce),
-		header.String(),
-		mixDigest.String(),
-	})
-	resp, err := eth.requestManager.send(req)
+	return eth.SubmitWorkContext(context.Background(), nonce, header, mixDigest)
+}
+
+// SubmitWorkContext is like SubmitWork but takes a context.
+func (eth *EthAPI) SubmitWorkContext(ctx context.Context, nonce uint64, header, mixDigest common.Hash) (bool, error) {
+	req := eth.requestManager.newRequest("eth_submitWork", fmt.Sprintf("0x%016x", nonce), header, mixDigest)
+	resp, err := eth.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return false, err
 	}
 
-	if resp.Error() != nil {
-		return false, resp.Error()
+	var result bool
+	if err := resp.DecodeResult(&result); err != nil {
+		return false, err
 	}
-
-	return resp.Get("result").(bool), nil
+	return result, nil
 }
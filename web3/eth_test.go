@@ -30,17 +30,42 @@
 package web3
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"math/big"
 	"strings"
 	"testing"
 
-	"github.com/yangyuan6/web3go/common"
-	"github.com/yangyuan6/web3go/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	"github.com/yangyuan6/web3go/common"
+	"github.com/yangyuan6/web3go/provider"
+	"github.com/yangyuan6/web3go/rpc"
+	"github.com/yangyuan6/web3go/test"
 )
 
+// rewritingProvider alters the results of method sent through the mock.
+type rewritingProvider struct {
+	provider.Provider
+	method  string
+	rewrite func(result map[string]interface{})
+}
+
+func (p *rewritingProvider) SendContext(ctx context.Context, request rpc.Request) (rpc.Response, error) {
+	resp, err := p.Provider.SendContext(ctx, request)
+	if err != nil || request.Get("method") != p.method {
+		return resp, err
+	}
+	var result map[string]interface{}
+	if err := resp.DecodeResult(&result); err != nil {
+		return nil, err
+	}
+	p.rewrite(result)
+	data, _ := json.Marshal(result)
+	return p.GetRPCMethod().NewResponse([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":%s}`, request.ID(), data))), nil
+}
+
 type EthTestSuite struct {
 	suite.Suite
 	web3 *Web3
@@ -108,7 +133,7 @@ func (suite *EthTestSuite) Test_BlockNumber() {
 
 func (suite *EthTestSuite) Test_GetBalance() {
 	eth := suite.eth
-	balance, err := eth.GetBalance(common.NewAddress(common.HexToBytes("0x407d73d8a49eeb85d32cf465507dd71d507100c1")), "latest")
+	balance, err := eth.GetBalance(common.NewAddress(common.HexToBytes("0x407d73d8a49eeb85d32cf465507dd71d507100c1")), common.BlockNumberOrHashWithNumber(common.Latest))
 	assert.NoError(suite.T(), err, "Should be no error")
 	assert.EqualValues(suite.T(),
 		big.NewInt(0x0234c8a3397aab58),
@@ -118,7 +143,7 @@ func (suite *EthTestSuite) Test_GetBalance() {
 
 func (suite *EthTestSuite) Test_GetStorageAt() {
 	eth := suite.eth
-	storage, err := eth.GetStorageAt(common.NewAddress(common.HexToBytes("0x407d73d8a49eeb85d32cf465507dd71d507100c1")), 0, "latest")
+	storage, err := eth.GetStorageAt(common.NewAddress(common.HexToBytes("0x407d73d8a49eeb85d32cf465507dd71d507100c1")), 0, common.BlockNumberOrHashWithNumber(common.Latest))
 	assert.NoError(suite.T(), err, "Should be no error")
 	assert.EqualValues(suite.T(),
 		0x03,
@@ -128,7 +153,7 @@ func (suite *EthTestSuite) Test_GetStorageAt() {
 
 func (suite *EthTestSuite) Test_GetTransactionCount() {
 	eth := suite.eth
-	transactionCount, err := eth.GetTransactionCount(common.NewAddress(common.HexToBytes("0x407d73d8a49eeb85d32cf465507dd71d507100c1")), "latest")
+	transactionCount, err := eth.GetTransactionCount(common.NewAddress(common.HexToBytes("0x407d73d8a49eeb85d32cf465507dd71d507100c1")), common.BlockNumberOrHashWithNumber(common.Latest))
 	assert.NoError(suite.T(), err, "Should be no error")
 	assert.EqualValues(suite.T(),
 		big.NewInt(0x1),
@@ -148,7 +173,7 @@ func (suite *EthTestSuite) Test_GetBlockTransactionCountByHash() {
 
 func (suite *EthTestSuite) Test_GetBlockTransactionCountByNumber() {
 	eth := suite.eth
-	transactionCount, err := eth.GetBlockTransactionCountByNumber("latest")
+	transactionCount, err := eth.GetBlockTransactionCountByNumber(common.Latest)
 	assert.NoError(suite.T(), err, "Should be no error")
 	assert.EqualValues(suite.T(),
 		big.NewInt(0xa),
@@ -168,7 +193,7 @@ func (suite *EthTestSuite) Test_GetUncleCountByBlockHash() {
 
 func (suite *EthTestSuite) Test_GetUncleCountByBlockNumber() {
 	eth := suite.eth
-	uncleCount, err := eth.GetUncleCountByBlockNumber("latest")
+	uncleCount, err := eth.GetUncleCountByBlockNumber(common.Latest)
 	assert.NoError(suite.T(), err, "Should be no error")
 	assert.EqualValues(suite.T(),
 		big.NewInt(0x1),
@@ -178,7 +203,7 @@ func (suite *EthTestSuite) Test_GetUncleCountByBlockNumber() {
 
 func (suite *EthTestSuite) Test_GetCode() {
 	eth := suite.eth
-	code, err := eth.GetCode(common.NewAddress(common.HexToBytes("0xa94f5374fce5edbc8e2a8697c15331677e6ebf0b")), "0x2")
+	code, err := eth.GetCode(common.NewAddress(common.HexToBytes("0xa94f5374fce5edbc8e2a8697c15331677e6ebf0b")), common.BlockNumberOrHashWithNumber(2))
 	assert.NoError(suite.T(), err, "Should be no error")
 	assert.EqualValues(suite.T(),
 		common.HexToBytes("0x600160008035811a818181146012578301005b601b6001356025565b8060005260206000f25b600060078202905091905056"),
@@ -209,7 +234,7 @@ func (suite *EthTestSuite) Test_SendTransaction() {
 	tx, err := eth.SendTransaction(req)
 	assert.NoError(suite.T(), err, "Should be no error")
 	assert.EqualValues(suite.T(),
-		common.NewHash(common.HexToBytes("0xe670ec64341771606e55d6b4ca35a1a6b75ee3d5145a99d05921026d1527331")),
+		common.StringToHash("0xe670ec64341771606e55d6b4ca35a1a6b75ee3d5145a99d05921026d1527331b"),
 		tx,
 		"Should be equal")
 }
@@ -219,7 +244,7 @@ func (suite *EthTestSuite) Test_SendRawTransaction() {
 	tx, err := eth.SendRawTransaction(common.HexToBytes("0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"))
 	assert.NoError(suite.T(), err, "Should be no error")
 	assert.EqualValues(suite.T(),
-		common.NewHash(common.HexToBytes("0xe670ec64341771606e55d6b4ca35a1a6b75ee3d5145a99d05921026d1527331")),
+		common.StringToHash("0xe670ec64341771606e55d6b4ca35a1a6b75ee3d5145a99d05921026d1527331b"),
 		tx,
 		"Should be equal")
 }
@@ -234,7 +259,7 @@ func (suite *EthTestSuite) Test_Call() {
 		Value:    big.NewInt(0x9184e72a),
 		Data:     common.HexToBytes("0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"),
 	}
-	result, err := eth.Call(req, "latest")
+	result, err := eth.Call(req, common.BlockNumberOrHashWithNumber(common.Latest))
 	assert.NoError(suite.T(), err, "Should be no error")
 	assert.EqualValues(suite.T(),
 		common.HexToBytes("0x"),
@@ -252,7 +277,7 @@ func (suite *EthTestSuite) Test_EstimateGas() {
 		Value:    big.NewInt(0x9184e72a),
 		Data:     common.HexToBytes("0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"),
 	}
-	gas, err := eth.EstimateGas(req, "latest")
+	gas, err := eth.EstimateGas(req, common.BlockNumberOrHashWithNumber(common.Latest))
 	assert.NoError(suite.T(), err, "Should be no error")
 	assert.EqualValues(suite.T(),
 		big.NewInt(0x5208),
@@ -266,23 +291,23 @@ func (suite *EthTestSuite) Test_GetBlockByHash() {
 		Number:          big.NewInt(0x1b4),
 		Hash:            common.NewHash(common.HexToBytes("0xe670ec64341771606e55d6b4ca35a1a6b75ee3d5145a99d05921026d1527331")),
 		ParentHash:      common.NewHash(common.HexToBytes("0x9646252be9520f6e71339a8df9c55e4d7619deeb018d2a3f2d21fc165dde5eb5")),
-		Nonce:           common.NewHash(common.HexToBytes("0xe04d296d2460cfb8472af2c5fd05b5a214109c25688d3704aed5484f9a7792f2")),
+		Nonce:           common.Uint64ToBlockNonce(0xe04d296d2460cfb8),
 		Sha3Uncles:      common.NewHash(common.HexToBytes("0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347")),
-		Bloom:           common.NewHash(common.HexToBytes("0xe670ec64341771606e55d6b4ca35a1a6b75ee3d5145a99d05921026d1527331")),
+		Bloom:           testBloom(),
 		TransactionRoot: common.NewHash(common.HexToBytes("0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")),
 		StateRoot:       common.NewHash(common.HexToBytes("0xd5855eb08b3387c0af375e9cdb6acfc05eb8f519e419b874b6ff2ffda7ed1dff")),
 		Miner:           common.NewAddress(common.HexToBytes("0x4e65fda2159562a496f9f3522f89122a3088497a")),
 		Difficulty:      big.NewInt(0x027f07),
 		TotalDifficulty: big.NewInt(0x027f07),
-		ExtraData:       common.NewHash(common.HexToBytes("0x0000000000000000000000000000000000000000000000000000000000000000")),
+		ExtraData:       common.HexToBytes("0xd883010d0e846765746888676f312e32302e33856c696e7578"),
 		Size:            big.NewInt(0x027f07),
 		GasLimit:        big.NewInt(0x9f759),
 		GasUsed:         big.NewInt(0x9f759),
 		Timestamp:       big.NewInt(0x54e34e8e),
-		Transactions:    []common.Hash{},
+		Transactions:    common.NewTransactionHashes([]common.Hash{common.NewHash(common.HexToBytes("0xc6ef2fc5426d6ad6fd9e2a26abeab0aa2411b7ab17f30a99d3cb96aed1d1055b"))}),
 		Uncles:          []common.Hash{},
 	}
-	returnedBlock, err := eth.GetBlockByHash(common.NewHash(common.HexToBytes("0xe670ec64341771606e55d6b4ca35a1a6b75ee3d5145a99d05921026d1527331")), true)
+	returnedBlock, err := eth.GetBlockByHash(common.NewHash(common.HexToBytes("0xe670ec64341771606e55d6b4ca35a1a6b75ee3d5145a99d05921026d1527331")), false)
 	assert.NoError(suite.T(), err, "Should be no error")
 	assert.EqualValues(suite.T(),
 		block, returnedBlock, "Should be equal")
@@ -294,38 +319,111 @@ func (suite *EthTestSuite) Test_GetBlockByNumber() {
 		Number:          big.NewInt(0x1b4),
 		Hash:            common.NewHash(common.HexToBytes("0xe670ec64341771606e55d6b4ca35a1a6b75ee3d5145a99d05921026d1527331")),
 		ParentHash:      common.NewHash(common.HexToBytes("0x9646252be9520f6e71339a8df9c55e4d7619deeb018d2a3f2d21fc165dde5eb5")),
-		Nonce:           common.NewHash(common.HexToBytes("0xe04d296d2460cfb8472af2c5fd05b5a214109c25688d3704aed5484f9a7792f2")),
+		Nonce:           common.Uint64ToBlockNonce(0xe04d296d2460cfb8),
 		Sha3Uncles:      common.NewHash(common.HexToBytes("0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347")),
-		Bloom:           common.NewHash(common.HexToBytes("0xe670ec64341771606e55d6b4ca35a1a6b75ee3d5145a99d05921026d1527331")),
+		Bloom:           testBloom(),
 		TransactionRoot: common.NewHash(common.HexToBytes("0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")),
 		StateRoot:       common.NewHash(common.HexToBytes("0xd5855eb08b3387c0af375e9cdb6acfc05eb8f519e419b874b6ff2ffda7ed1dff")),
 		Miner:           common.NewAddress(common.HexToBytes("0x4e65fda2159562a496f9f3522f89122a3088497a")),
 		Difficulty:      big.NewInt(0x027f07),
 		TotalDifficulty: big.NewInt(0x027f07),
-		ExtraData:       common.NewHash(common.HexToBytes("0x0000000000000000000000000000000000000000000000000000000000000000")),
+		ExtraData:       common.HexToBytes("0xd883010d0e846765746888676f312e32302e33856c696e7578"),
 		Size:            big.NewInt(0x027f07),
 		GasLimit:        big.NewInt(0x9f759),
 		GasUsed:         big.NewInt(0x9f759),
 		Timestamp:       big.NewInt(0x54e34e8e),
-		Transactions:    []common.Hash{},
-		Uncles:          []common.Hash{},
+		Transactions: common.NewFullTransactions([]common.Transaction{
+			{
+				Hash:             common.NewHash(common.HexToBytes("0xc6ef2fc5426d6ad6fd9e2a26abeab0aa2411b7ab17f30a99d3cb96aed1d1055b")),
+				Nonce:            0x15,
+				BlockHash:        common.NewHash(common.HexToBytes("0xbeab0aa2411b7ab17f30a99d3cb9c6ef2fc5426d6ad6fd9e2a26a6aed1d1055b")),
+				BlockNumber:      big.NewInt(0x15df),
+				TransactionIndex: 0x1,
+				From:             common.NewAddress(common.HexToBytes("0x407d73d8a49eeb85d32cf465507dd71d507100c1")),
+				To:               common.NewAddress(common.HexToBytes("0x85f43d8a49eeb85d32cf465507dd71d507100c1f")),
+				Value:            big.NewInt(0x7f110),
+				Gas:              big.NewInt(0x7f110),
+				GasPrice:         big.NewInt(0x09184e72a000),
+				Data:             common.HexToBytes("0x603880600c6000396000f300603880600c6000396000f3603880600c6000396000f360"),
+			},
+		}),
+		Uncles: []common.Hash{},
 	}
-	returnedBlock, err := eth.GetBlockByNumber("0x1b4", true)
+	returnedBlock, err := eth.GetBlockByNumber(common.BlockNumber(0x1b4), true)
 	assert.NoError(suite.T(), err, "Should be no error")
 	assert.EqualValues(suite.T(),
 		block, returnedBlock, "Should be equal")
 }
 
+func (suite *EthTestSuite) Test_GetBlockWithReceipts() {
+	eth := suite.eth
+	hash := common.NewHash(common.HexToBytes("0xc6ef2fc5426d6ad6fd9e2a26abeab0aa2411b7ab17f30a99d3cb96aed1d1055b"))
+	result, err := eth.GetBlockWithReceipts(common.BlockNumberOrHashWithNumber(0x1b4))
+	if assert.NoError(suite.T(), err, "Should be no error") && assert.Len(suite.T(), result.Transactions, 1) {
+		assert.EqualValues(suite.T(), []common.Hash{hash}, result.Block.Transactions.Hashes(), "Should be equal")
+		full, ok := result.Block.Transactions.Full()
+		assert.True(suite.T(), ok, "Should have the full transactions")
+		assert.EqualValues(suite.T(), full[0], result.Transactions[0].Transaction, "Should be equal")
+		assert.EqualValues(suite.T(), big.NewInt(0x4dc), result.Transactions[0].Receipt.GasUsed, "Should be equal")
+	}
+
+	_, err = eth.GetBlockWithReceipts(common.BlockNumberOrHashWithHash(common.Hash{}, false))
+	assert.Equal(suite.T(), ErrNotFound, err, "Should be equal")
+	_, err = eth.GetBlockByHash(common.Hash{}, false)
+	assert.Equal(suite.T(), ErrNotFound, err, "Should be equal")
+
+	block, err := eth.GetBlockByHash(hash, false)
+	if assert.NoError(suite.T(), err, "Should be no error") {
+		_, ok := block.Transactions.Full()
+		assert.False(suite.T(), ok, "Should only have the hashes")
+		assert.Equal(suite.T(), 1, block.Transactions.Len(), "Should be equal")
+		data, _ := json.Marshal(block.Transactions)
+		assert.Equal(suite.T(), `["`+hash.String()+`"]`, string(data), "Should be equal")
+	}
+}
+
+func (suite *EthTestSuite) Test_GetBlockWithReceiptsChecks() {
+	hash := common.NewHash(common.HexToBytes("0xc6ef2fc5426d6ad6fd9e2a26abeab0aa2411b7ab17f30a99d3cb96aed1d1055b"))
+	_, err := suite.eth.GetBlockWithReceipts(common.BlockNumberOrHashWithHash(hash, true))
+	assert.NoError(suite.T(), err, "Should be no error")
+
+	rewrite := func(rewrite func(result map[string]interface{})) Eth {
+		return NewWeb3(&rewritingProvider{Provider: test.NewMockHTTPProvider(), method: "eth_getBlockByNumber", rewrite: rewrite}).Eth
+	}
+
+	eth := rewrite(func(result map[string]interface{}) {
+		result["hash"] = hash
+	})
+	_, err = eth.GetBlockWithReceipts(common.BlockNumberOrHashWithHash(hash, true))
+	assert.Error(suite.T(), err, "Should not be canonical")
+	_, err = eth.GetBlockWithReceipts(common.BlockNumberOrHashWithHash(hash, false))
+	assert.NoError(suite.T(), err, "Should be no error")
+
+	eth = rewrite(func(result map[string]interface{}) {
+		result["transactions"] = []common.Hash{hash}
+	})
+	_, err = eth.GetBlockWithReceipts(common.BlockNumberOrHashWithNumber(0x1b4))
+	assert.Error(suite.T(), err, "Should not accept transaction hashes")
+
+	eth = rewrite(func(result map[string]interface{}) {
+		result["transactions"] = []interface{}{}
+	})
+	result, err := eth.GetBlockWithReceipts(common.BlockNumberOrHashWithNumber(0x1b4))
+	if assert.NoError(suite.T(), err, "Should be no error") {
+		assert.Empty(suite.T(), result.Transactions, "Should be empty")
+	}
+}
+
 func (suite *EthTestSuite) Test_GetTransactionByHash() {
 	eth := suite.eth
 	tx := &common.Transaction{
 		Hash:             common.NewHash(common.HexToBytes("0xc6ef2fc5426d6ad6fd9e2a26abeab0aa2411b7ab17f30a99d3cb96aed1d1055b")),
-		Nonce:            common.NewHash(common.HexToBytes("0x")),
+		Nonce:            0x15,
 		BlockHash:        common.NewHash(common.HexToBytes("0xbeab0aa2411b7ab17f30a99d3cb9c6ef2fc5426d6ad6fd9e2a26a6aed1d1055b")),
 		BlockNumber:      big.NewInt(0x15df),
 		TransactionIndex: 0x1,
 		From:             common.NewAddress(common.HexToBytes("0x407d73d8a49eeb85d32cf465507dd71d507100c1")),
-		To:               common.NewAddress(common.HexToBytes("0x85h43d8a49eeb85d32cf465507dd71d507100c1")),
+		To:               common.NewAddress(common.HexToBytes("0x85f43d8a49eeb85d32cf465507dd71d507100c1f")),
 		Value:            big.NewInt(0x7f110),
 		Gas:              big.NewInt(0x7f110),
 		GasPrice:         big.NewInt(0x09184e72a000),
@@ -341,12 +439,12 @@ func (suite *EthTestSuite) Test_GetTransactionByHashAndIndex() {
 	eth := suite.eth
 	tx := &common.Transaction{
 		Hash:             common.NewHash(common.HexToBytes("0xc6ef2fc5426d6ad6fd9e2a26abeab0aa2411b7ab17f30a99d3cb96aed1d1055b")),
-		Nonce:            common.NewHash(common.HexToBytes("0x")),
+		Nonce:            0x15,
 		BlockHash:        common.NewHash(common.HexToBytes("0xbeab0aa2411b7ab17f30a99d3cb9c6ef2fc5426d6ad6fd9e2a26a6aed1d1055b")),
 		BlockNumber:      big.NewInt(0x15df),
 		TransactionIndex: 0x1,
 		From:             common.NewAddress(common.HexToBytes("0x407d73d8a49eeb85d32cf465507dd71d507100c1")),
-		To:               common.NewAddress(common.HexToBytes("0x85h43d8a49eeb85d32cf465507dd71d507100c1")),
+		To:               common.NewAddress(common.HexToBytes("0x85f43d8a49eeb85d32cf465507dd71d507100c1f")),
 		Value:            big.NewInt(0x7f110),
 		Gas:              big.NewInt(0x7f110),
 		GasPrice:         big.NewInt(0x09184e72a000),
@@ -362,18 +460,18 @@ func (suite *EthTestSuite) Test_GetTransactionByNumberAndIndex() {
 	eth := suite.eth
 	tx := &common.Transaction{
 		Hash:             common.NewHash(common.HexToBytes("0xc6ef2fc5426d6ad6fd9e2a26abeab0aa2411b7ab17f30a99d3cb96aed1d1055b")),
-		Nonce:            common.NewHash(common.HexToBytes("0x")),
+		Nonce:            0x15,
 		BlockHash:        common.NewHash(common.HexToBytes("0xbeab0aa2411b7ab17f30a99d3cb9c6ef2fc5426d6ad6fd9e2a26a6aed1d1055b")),
 		BlockNumber:      big.NewInt(0x15df),
 		TransactionIndex: 0x1,
 		From:             common.NewAddress(common.HexToBytes("0x407d73d8a49eeb85d32cf465507dd71d507100c1")),
-		To:               common.NewAddress(common.HexToBytes("0x85h43d8a49eeb85d32cf465507dd71d507100c1")),
+		To:               common.NewAddress(common.HexToBytes("0x85f43d8a49eeb85d32cf465507dd71d507100c1f")),
 		Value:            big.NewInt(0x7f110),
 		Gas:              big.NewInt(0x7f110),
 		GasPrice:         big.NewInt(0x09184e72a000),
 		Data:             common.HexToBytes("0x603880600c6000396000f300603880600c6000396000f3603880600c6000396000f360"),
 	}
-	returnedTx, err := eth.GetTransactionByBlockNumberAndIndex("0x29c", 0)
+	returnedTx, err := eth.GetTransactionByBlockNumberAndIndex(common.BlockNumber(0x29c), 0)
 	assert.NoError(suite.T(), err, "Should be no error")
 	assert.EqualValues(suite.T(),
 		tx, returnedTx, "Should be equal")
@@ -403,20 +501,20 @@ func (suite *EthTestSuite) Test_GetUncleByBlockHashAndIndex() {
 		Number:          big.NewInt(0x1b4),
 		Hash:            common.NewHash(common.HexToBytes("0xe670ec64341771606e55d6b4ca35a1a6b75ee3d5145a99d05921026d1527331")),
 		ParentHash:      common.NewHash(common.HexToBytes("0x9646252be9520f6e71339a8df9c55e4d7619deeb018d2a3f2d21fc165dde5eb5")),
-		Nonce:           common.NewHash(common.HexToBytes("0xe04d296d2460cfb8472af2c5fd05b5a214109c25688d3704aed5484f9a7792f2")),
+		Nonce:           common.Uint64ToBlockNonce(0xe04d296d2460cfb8),
 		Sha3Uncles:      common.NewHash(common.HexToBytes("0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347")),
-		Bloom:           common.NewHash(common.HexToBytes("0xe670ec64341771606e55d6b4ca35a1a6b75ee3d5145a99d05921026d1527331")),
+		Bloom:           testBloom(),
 		TransactionRoot: common.NewHash(common.HexToBytes("0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")),
 		StateRoot:       common.NewHash(common.HexToBytes("0xd5855eb08b3387c0af375e9cdb6acfc05eb8f519e419b874b6ff2ffda7ed1dff")),
 		Miner:           common.NewAddress(common.HexToBytes("0x4e65fda2159562a496f9f3522f89122a3088497a")),
 		Difficulty:      big.NewInt(0x027f07),
 		TotalDifficulty: big.NewInt(0x027f07),
-		ExtraData:       common.NewHash(common.HexToBytes("0x0000000000000000000000000000000000000000000000000000000000000000")),
+		ExtraData:       common.HexToBytes("0xd883010d0e846765746888676f312e32302e33856c696e7578"),
 		Size:            big.NewInt(0x027f07),
 		GasLimit:        big.NewInt(0x9f759),
 		GasUsed:         big.NewInt(0x9f759),
 		Timestamp:       big.NewInt(0x54e34e8e),
-		Transactions:    []common.Hash{},
+		Transactions:    common.NewTransactionHashes([]common.Hash{common.NewHash(common.HexToBytes("0xc6ef2fc5426d6ad6fd9e2a26abeab0aa2411b7ab17f30a99d3cb96aed1d1055b"))}),
 		Uncles:          []common.Hash{},
 	}
 	returnedBlock, err := eth.GetUncleByBlockHashAndIndex(common.NewHash(common.HexToBytes("0xc6ef2fc5426d6ad6fd9e2a26abeab0aa2411b7ab17f30a99d3cb96aed1d1055b")), 0)
@@ -431,23 +529,23 @@ func (suite *EthTestSuite) Test_GetUncleByBlockNumberAndIndex() {
 		Number:          big.NewInt(0x1b4),
 		Hash:            common.NewHash(common.HexToBytes("0xe670ec64341771606e55d6b4ca35a1a6b75ee3d5145a99d05921026d1527331")),
 		ParentHash:      common.NewHash(common.HexToBytes("0x9646252be9520f6e71339a8df9c55e4d7619deeb018d2a3f2d21fc165dde5eb5")),
-		Nonce:           common.NewHash(common.HexToBytes("0xe04d296d2460cfb8472af2c5fd05b5a214109c25688d3704aed5484f9a7792f2")),
+		Nonce:           common.Uint64ToBlockNonce(0xe04d296d2460cfb8),
 		Sha3Uncles:      common.NewHash(common.HexToBytes("0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347")),
-		Bloom:           common.NewHash(common.HexToBytes("0xe670ec64341771606e55d6b4ca35a1a6b75ee3d5145a99d05921026d1527331")),
+		Bloom:           testBloom(),
 		TransactionRoot: common.NewHash(common.HexToBytes("0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")),
 		StateRoot:       common.NewHash(common.HexToBytes("0xd5855eb08b3387c0af375e9cdb6acfc05eb8f519e419b874b6ff2ffda7ed1dff")),
 		Miner:           common.NewAddress(common.HexToBytes("0x4e65fda2159562a496f9f3522f89122a3088497a")),
 		Difficulty:      big.NewInt(0x027f07),
 		TotalDifficulty: big.NewInt(0x027f07),
-		ExtraData:       common.NewHash(common.HexToBytes("0x0000000000000000000000000000000000000000000000000000000000000000")),
+		ExtraData:       common.HexToBytes("0xd883010d0e846765746888676f312e32302e33856c696e7578"),
 		Size:            big.NewInt(0x027f07),
 		GasLimit:        big.NewInt(0x9f759),
 		GasUsed:         big.NewInt(0x9f759),
 		Timestamp:       big.NewInt(0x54e34e8e),
-		Transactions:    []common.Hash{},
+		Transactions:    common.NewTransactionHashes([]common.Hash{common.NewHash(common.HexToBytes("0xc6ef2fc5426d6ad6fd9e2a26abeab0aa2411b7ab17f30a99d3cb96aed1d1055b"))}),
 		Uncles:          []common.Hash{},
 	}
-	returnedBlock, err := eth.GetUncleByBlockNumberAndIndex("0x29c", 0)
+	returnedBlock, err := eth.GetUncleByBlockNumberAndIndex(common.BlockNumber(0x29c), 0)
 	assert.NoError(suite.T(), err, "Should be no error")
 	assert.EqualValues(suite.T(),
 		block, returnedBlock, "Should be equal")
@@ -525,12 +623,12 @@ func (suite *EthTestSuite) Test_GetFilterChanges() {
 	returnedLogs, err := eth.GetFilterChanges(filter)
 	if assert.NoError(suite.T(), err, "Should be no error") {
 		for i, l := range returnedLogs {
-			log := common.Log{}
+			log := jsonLog{}
 			rawBytes, err := json.Marshal(l)
 			assert.NoError(suite.T(), err, "Should be no error")
 			err = json.Unmarshal(rawBytes, &log)
 			assert.NoError(suite.T(), err, "Should be no error")
-			assert.EqualValues(suite.T(), logs[i], log, "Should be equal")
+			assert.EqualValues(suite.T(), logs[i], log.ToLog(), "Should be equal")
 		}
 	}
 }
@@ -558,12 +656,12 @@ func (suite *EthTestSuite) Test_GetFilterLogs() {
 	returnedLogs, err := eth.GetFilterLogs(filter)
 	if assert.NoError(suite.T(), err, "Should be no error") {
 		for i, l := range returnedLogs {
-			log := common.Log{}
+			log := jsonLog{}
 			rawBytes, err := json.Marshal(l)
 			assert.NoError(suite.T(), err, "Should be no error")
 			err = json.Unmarshal(rawBytes, &log)
 			assert.NoError(suite.T(), err, "Should be no error")
-			assert.EqualValues(suite.T(), logs[i], log, "Should be equal")
+			assert.EqualValues(suite.T(), logs[i], log.ToLog(), "Should be equal")
 		}
 	}
 }
@@ -591,16 +689,45 @@ func (suite *EthTestSuite) Test_GetLogs() {
 	returnedLogs, err := eth.GetLogs(filter)
 	if assert.NoError(suite.T(), err, "Should be no error") {
 		for i, l := range returnedLogs {
-			log := common.Log{}
+			log := jsonLog{}
 			rawBytes, err := json.Marshal(l)
 			assert.NoError(suite.T(), err, "Should be no error")
 			err = json.Unmarshal(rawBytes, &log)
 			assert.NoError(suite.T(), err, "Should be no error")
-			assert.EqualValues(suite.T(), logs[i], log, "Should be equal")
+			assert.EqualValues(suite.T(), logs[i], log.ToLog(), "Should be equal")
 		}
 	}
 }
 
+func (suite *EthTestSuite) Test_WatchContext() {
+	eth := suite.eth
+	filter, err := eth.NewBlockFilter()
+	assert.NoError(suite.T(), err, "Should be no error")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	watchCh := filter.WatchContext(ctx)
+	_, err = watchCh.Next()
+	assert.NoError(suite.T(), err, "Should be no error")
+
+	cancel()
+	for err == nil {
+		_, err = watchCh.Next()
+	}
+	assert.Equal(suite.T(), ErrChannelClosed, err, "Should be equal")
+	watchCh.Close()
+}
+
+func (suite *EthTestSuite) Test_Context() {
+	eth := suite.eth
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := eth.BlockNumberContext(ctx)
+	assert.Equal(suite.T(), context.Canceled, err, "Should be equal")
+	_, err = suite.web3.Net.PeerCountContext(ctx)
+	assert.Equal(suite.T(), context.Canceled, err, "Should be equal")
+}
+
 func (suite *EthTestSuite) Test_GetWork() {
 	eth := suite.eth
 	works := []string{
@@ -623,11 +750,138 @@ func (suite *EthTestSuite) Test_SubmitWork() {
 	assert.True(suite.T(), result, "Should be true")
 }
 
+// paramsSpy records the params of the requests sent through it, as JSON.
+type paramsSpy struct {
+	provider.Provider
+	params []string
+}
+
+func (p *paramsSpy) SendContext(ctx context.Context, request rpc.Request) (rpc.Response, error) {
+	data, _ := json.Marshal(request.Get("params"))
+	p.params = append(p.params, string(data))
+	return p.Provider.SendContext(ctx, request)
+}
+
+func (suite *EthTestSuite) Test_Params() {
+	spy := &paramsSpy{}
+	eth := NewWeb3(test.NewMockHTTPProvider(), func(next provider.Provider) provider.Provider {
+		spy.Provider = next
+		return spy
+	}).Eth
+
+	address := common.StringToAddress("0x407d73d8a49eeb85d32cf465507dd71d507100c1")
+	hash := common.StringToHash("0xe670ec64341771606e55d6b4ca35a1a6b75ee3d5145a99d05921026d1527331b")
+	tx := &common.TransactionRequest{From: address, Gas: big.NewInt(21000), Value: big.NewInt(1), Data: []byte{0xca, 0xfe}}
+
+	eth.GetBlockByNumber(common.BlockNumber(0x1b4), true)
+	eth.GetBlockByHash(hash, false)
+	eth.GetStorageAt(address, 10, common.BlockNumberOrHashWithNumber(common.Latest))
+	eth.GetTransactionByBlockNumberAndIndex(common.BlockNumber(0x1b4), 255)
+	eth.Call(tx, common.BlockNumberOrHashWithNumber(common.Latest))
+	eth.GetBalance(address, common.BlockNumberOrHashWithHash(hash, true))
+	eth.GetCode(address, common.BlockNumberOrHash{})
+	eth.SubmitWork(1, hash, hash)
+	from, to := common.BlockNumber(1), common.Finalized
+	eth.NewFilter(&FilterOption{FromBlock: &from, ToBlock: &to, Topics: common.Topics{
+		{Data: hash[:]},
+		{},
+		{Any: [][]byte{hash[:], make([]byte, 32)}},
+	}})
+
+	assert.Equal(suite.T(), []string{
+		`["0x1b4",true]`,
+		`["0xe670ec64341771606e55d6b4ca35a1a6b75ee3d5145a99d05921026d1527331b",false]`,
+		`["0x407d73d8a49eeb85d32cf465507dd71d507100c1","0xa","latest"]`,
+		`["0x1b4","0xff"]`,
+		`[{"data":"0xcafe","from":"0x407d73d8a49eeb85d32cf465507dd71d507100c1","gas":"0x5208","value":"0x1"},"latest"]`,
+		`["0x407d73d8a49eeb85d32cf465507dd71d507100c1",{"blockHash":"0xe670ec64341771606e55d6b4ca35a1a6b75ee3d5145a99d05921026d1527331b","requireCanonical":true}]`,
+		`["0x407d73d8a49eeb85d32cf465507dd71d507100c1","latest"]`,
+		`["0x0000000000000001","0xe670ec64341771606e55d6b4ca35a1a6b75ee3d5145a99d05921026d1527331b","0xe670ec64341771606e55d6b4ca35a1a6b75ee3d5145a99d05921026d1527331b"]`,
+		`[{"fromBlock":"0x1","toBlock":"finalized","topics":["0xe670ec64341771606e55d6b4ca35a1a6b75ee3d5145a99d05921026d1527331b",null,["0xe670ec64341771606e55d6b4ca35a1a6b75ee3d5145a99d05921026d1527331b","0x0000000000000000000000000000000000000000000000000000000000000000"]]}]`,
+	}, spy.params, "Should be equal")
+}
+
+// gethBlock and gethTransaction are mainnet block 1 and the first mainnet
+// transaction, as geth returns them.
+const (
+	gethBlock = `{"difficulty":"0x3ff800000","extraData":"0x476574682f76312e302e302f6c696e75782f676f312e342e32",` +
+		`"gasLimit":"0x1388","gasUsed":"0x0","hash":"0x88e96d4537bea4d9c05d12549907b32561d3bf31f45aae734cdc119f13406cb6",` +
+		`"logsBloom":"0x00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",` +
+		`"miner":"0x05a56e2d52c817161883f50c441c3228cfe54d9f","mixHash":"0x969b900de27b6ac6a67742365dd65f55a0526c41fd18e1b16f1a1215c2e66f59",` +
+		`"nonce":"0x689056015818adbe","number":"0x1","parentHash":"0xd4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3",` +
+		`"receiptsRoot":"0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421",` +
+		`"sha3Uncles":"0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347","size":"0x219",` +
+		`"stateRoot":"0xd67e4d450343046425ae4271474353857ab860dbc0a1dde64b41b5cd3a532bf3","timestamp":"0x55ba4224",` +
+		`"totalDifficulty":"0x7ff800000","transactions":[],` +
+		`"transactionsRoot":"0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421","uncles":[]}`
+	gethTransaction = `{"blockHash":"0x4e3a3754410177e6937ef1f84bba68ea139e8d1a2258c5f85db9f1cd715a1bdd","blockNumber":"0xb443",` +
+		`"from":"0xa1e4380a3b1f749673e270229993ee55f35663b4","gas":"0x5208","gasPrice":"0x2d79883d2000",` +
+		`"hash":"0x5c504ed432cb51138bcf09aa5e8a410dd4a1e204ef84bfed1be16dfba1b22060","input":"0x","nonce":"0x0",` +
+		`"to":"0x5df9b87991262f6ba471f09758cde1c0fc1de734","transactionIndex":"0x0","value":"0x7a69","type":"0x0",` +
+		`"v":"0x1c","r":"0x88ff6cf0fefd94db46111149ae4bfc179e9b94721fffd821d38d16464b3f71d0",` +
+		`"s":"0x45e0aff800961cfce805daef7016b9b675c137a6a41a548f7b60a3484c06a33a"}`
+)
+
+func (suite *EthTestSuite) Test_GethFixtures() {
+	block := &jsonBlock{}
+	if assert.NoError(suite.T(), json.Unmarshal([]byte(gethBlock), block), "Should be no error") {
+		result := block.ToBlock()
+		assert.EqualValues(suite.T(), big.NewInt(1), result.Number, "Should be equal")
+		assert.EqualValues(suite.T(), uint64(0x689056015818adbe), result.Nonce.Uint64(), "Should be equal")
+		assert.EqualValues(suite.T(), common.Bloom{}, result.Bloom, "Should be equal")
+		assert.EqualValues(suite.T(), "Geth/v1.0.0/linux/go1.4.2", string(result.ExtraData), "Should be equal")
+		assert.EqualValues(suite.T(), big.NewInt(0x7ff800000), result.TotalDifficulty, "Should be equal")
+		assert.EqualValues(suite.T(), 0, result.Transactions.Len(), "Should be equal")
+	}
+
+	tx := &jsonTransaction{}
+	if assert.NoError(suite.T(), json.Unmarshal([]byte(gethTransaction), tx), "Should be no error") {
+		result := tx.ToTransaction()
+		assert.EqualValues(suite.T(), uint64(0), result.Nonce, "Should be equal")
+		assert.EqualValues(suite.T(), big.NewInt(0xb443), result.BlockNumber, "Should be equal")
+		assert.EqualValues(suite.T(), "0x5df9b87991262f6ba471f09758cde1c0fc1de734", result.To.String(), "Should be equal")
+		assert.EqualValues(suite.T(), big.NewInt(31337), result.Value, "Should be equal")
+		assert.Empty(suite.T(), result.Data, "Should be empty")
+	}
+	assert.NoError(suite.T(), json.Unmarshal([]byte(`{"nonce":"0x15"}`), tx), "Should be no error")
+	assert.EqualValues(suite.T(), 0x15, tx.Nonce, "Should be equal")
+}
+
+func (suite *EthTestSuite) Test_HexQuantities() {
+	data := `{"number":"0x1b4","difficulty":"0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff","transactions":[]}`
+	block := &jsonBlock{}
+	if assert.NoError(suite.T(), json.Unmarshal([]byte(data), block), "Should be no error") {
+		max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+		assert.EqualValues(suite.T(), big.NewInt(0x1b4), block.ToBlock().Number, "Should be equal")
+		assert.EqualValues(suite.T(), max, block.ToBlock().Difficulty, "Should be equal")
+	}
+	assert.Error(suite.T(), json.Unmarshal([]byte(`{"number":436}`), &jsonBlock{}), "Should be error")
+	assert.Error(suite.T(), json.Unmarshal([]byte(`{"gas":"0x0f"}`), &jsonTransaction{}), "Should be error")
+
+	status := &jsonSyncStatus{}
+	data = `{"startingBlock":"0x384","currentBlock":"0x386","highestBlock":"0x454"}`
+	if assert.NoError(suite.T(), json.Unmarshal([]byte(data), status), "Should be no error") {
+		assert.EqualValues(suite.T(), common.SyncStatus{
+			Result:        true,
+			StartingBlock: big.NewInt(0x384),
+			CurrentBlock:  big.NewInt(0x386),
+			HighestBlock:  big.NewInt(0x454),
+		}, status.ToSyncStatus(), "Should be equal")
+	}
+}
+
 func (suite *EthTestSuite) SetupTest() {
 	suite.web3 = NewWeb3(test.NewMockHTTPProvider())
 	suite.eth = suite.web3.Eth
 }
 
+// testBloom is the logs bloom of the mock blocks.
+func testBloom() common.Bloom {
+	var bloom common.Bloom
+	bloom[0], bloom[31], bloom[128], bloom[255] = 0x20, 0x01, 0x80, 0x04
+	return bloom
+}
+
 func Test_EthTestSuite(t *testing.T) {
 	suite.Run(t, new(EthTestSuite))
 }
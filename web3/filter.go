@@ -30,9 +30,9 @@
 package web3
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
-	"sync"
 	"time"
 
 	"github.com/yangyuan6/web3go/common"
@@ -57,10 +57,10 @@ const (
 
 // FilterOption ...
 type FilterOption struct {
-	FromBlock string        `json:"fromBlock,omitempty"`
-	ToBlock   string        `json:"toBlock,omitempty"`
-	Address   interface{}   `json:"address,omitempty"`
-	Topics    common.Topics `json:"topics,omitempty"`
+	FromBlock *common.BlockNumber `json:"fromBlock,omitempty"`
+	ToBlock   *common.BlockNumber `json:"toBlock,omitempty"`
+	Address   interface{}         `json:"address,omitempty"`
+	Topics    common.Topics       `json:"topics,omitempty"`
 }
 
 func (opt *FilterOption) String() string {
@@ -71,6 +71,7 @@ func (opt *FilterOption) String() string {
 // Filter ...
 type Filter interface {
 	Watch() WatchChannel
+	WatchContext(ctx context.Context) WatchChannel
 	ID() uint64
 }
 
@@ -87,8 +88,8 @@ type WatchChannel interface {
 }
 
 type watchChannel struct {
-	dataCh  chan interface{}
-	closeCh chan struct{}
+	dataCh <-chan interface{}
+	cancel context.CancelFunc
 }
 
 // -----------------------------------------------------------------------------
@@ -104,35 +105,41 @@ func newFilter(eth Eth, filterType FilterType, id uint64) Filter {
 }
 
 func (f *baseFilter) Watch() WatchChannel {
+	return f.WatchContext(context.Background())
+}
+
+// WatchContext polls the filter for changes until the returned channel is
+// closed or ctx is done. Cancelling ctx also aborts a poll in flight.
+func (f *baseFilter) WatchContext(ctx context.Context) WatchChannel {
+	ctx, cancel := context.WithCancel(ctx)
 	dataCh := make(chan interface{}, dataBufferSize)
-	closeCh := make(chan struct{})
-	var wg sync.WaitGroup
-	wg.Add(1)
 
-	go func(wg *sync.WaitGroup, closeCh <-chan struct{}, dataCh chan<- interface{}) {
+	go func(ctx context.Context, dataCh chan<- interface{}) {
 		// TODO: configurable timer
 		ticker := time.NewTicker(pollInterval)
 		defer ticker.Stop()
-
-		wg.Done()
+		defer close(dataCh)
 
 		for {
 			select {
-			case <-closeCh:
-				close(dataCh)
+			case <-ctx.Done():
 				return
 			case <-ticker.C:
-				results, _ := f.eth.GetFilterChanges(f)
+				results, _ := f.eth.GetFilterChangesContext(ctx, f)
 				for _, r := range results {
-					dataCh <- r
+					select {
+					case dataCh <- r:
+					case <-ctx.Done():
+						return
+					}
 				}
 			}
 		}
-	}(&wg, closeCh, dataCh)
+	}(ctx, dataCh)
 
 	return &watchChannel{
-		dataCh:  dataCh,
-		closeCh: closeCh,
+		dataCh: dataCh,
+		cancel: cancel,
 	}
 }
 
@@ -152,5 +159,5 @@ func (wc *watchChannel) Next() (interface{}, error) {
 }
 
 func (wc *watchChannel) Close() {
-	wc.closeCh <- struct{}{}
+	wc.cancel()
 }
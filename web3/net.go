@@ -30,7 +30,7 @@
 package web3
 
 import (
-	"strconv"
+	"context"
 
 	"github.com/yangyuan6/web3go/common"
 )
@@ -38,8 +38,11 @@ import (
 // Net ...
 type Net interface {
 	Version() (string, error)
+	VersionContext(ctx context.Context) (string, error)
 	PeerCount() (uint64, error)
+	PeerCountContext(ctx context.Context) (uint64, error)
 	Listening() (bool, error)
+	ListeningContext(ctx context.Context) (bool, error)
 }
 
 // NetAPI ...
@@ -54,34 +57,60 @@ func newNetAPI(requestManager *requestManager) Net {
 
 // Version returns the current network protocol version.
 func (net *NetAPI) Version() (string, error) {
+	return net.VersionContext(context.Background())
+}
+
+// VersionContext is like Version but takes a context.
+func (net *NetAPI) VersionContext(ctx context.Context) (string, error) {
 	req := net.requestManager.newRequest("net_version")
-	resp, err := net.requestManager.send(req)
+	resp, err := net.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return "", err
 	}
-	return resp.Get("result").(string), nil
+
+	var result string
+	if err := resp.DecodeResult(&result); err != nil {
+		return "", err
+	}
+	return result, nil
 }
 
 // PeerCount returns number of peers currenly connected to the client.
 func (net *NetAPI) PeerCount() (uint64, error) {
+	return net.PeerCountContext(context.Background())
+}
+
+// PeerCountContext is like PeerCount but takes a context.
+func (net *NetAPI) PeerCountContext(ctx context.Context) (uint64, error) {
 	req := net.requestManager.newRequest("net_peerCount")
-	resp, err := net.requestManager.send(req)
+	resp, err := net.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return 0, err
 	}
-	result, err := strconv.ParseUint(common.HexToString(resp.Get("result").(string)), 16, 64)
-	if err != nil {
+
+	var result common.HexUint64
+	if err := resp.DecodeResult(&result); err != nil {
 		return 0, err
 	}
-	return result, nil
+	return uint64(result), nil
 }
 
 // Listening returns true if client is actively listening for network connections.
 func (net *NetAPI) Listening() (bool, error) {
+	return net.ListeningContext(context.Background())
+}
+
+// ListeningContext is like Listening but takes a context.
+func (net *NetAPI) ListeningContext(ctx context.Context) (bool, error) {
 	req := net.requestManager.newRequest("net_listening")
-	resp, err := net.requestManager.send(req)
+	resp, err := net.requestManager.sendContext(ctx, req)
 	if err != nil {
 		return false, err
 	}
-	return resp.Get("result").(bool), nil
+
+	var result bool
+	if err := resp.DecodeResult(&result); err != nil {
+		return false, err
+	}
+	return result, nil
 }
@@ -30,24 +30,56 @@
 package web3
 
 import (
+	"context"
+
 	"github.com/yangyuan6/web3go/provider"
 	"github.com/yangyuan6/web3go/rpc"
 )
 
 // requestManager is responsible for passing messages to providers
 type requestManager struct {
+	// provider is the base provider, which may support more than sending.
 	provider provider.Provider
-	rpc      rpc.RPC
+	// chain is the provider wrapped by the middlewares, used for sending.
+	chain provider.Provider
+	rpc   rpc.RPC
 }
 
-func newRequestManager(provider provider.Provider) *requestManager {
-	return &requestManager{provider: provider, rpc: provider.GetRPCMethod()}
+func newRequestManager(base provider.Provider, middlewares ...provider.Middleware) *requestManager {
+	return &requestManager{provider: base, chain: provider.Chain(base, middlewares...), rpc: base.GetRPCMethod()}
 }
 
-func (rm *requestManager) newRequest(method string) rpc.Request {
-	return rm.rpc.NewRequest(method)
+func (rm *requestManager) newRequest(method string, args ...interface{}) rpc.Request {
+	return rm.rpc.NewRequest(method, args...)
 }
 
 func (rm *requestManager) send(request rpc.Request) (rpc.Response, error) {
-	return rm.provider.Send(request)
+	return rm.sendContext(context.Background(), request)
+}
+
+func (rm *requestManager) sendContext(ctx context.Context, request rpc.Request) (rpc.Response, error) {
+	return rm.chain.SendContext(ctx, request)
+}
+
+// sendBatch sends the requests through the middlewares, in one round trip if
+// the provider supports it, one after another otherwise.
+func (rm *requestManager) sendBatch(ctx context.Context, requests []rpc.Request) ([]rpc.Response, error) {
+	return provider.SendBatch(ctx, rm.chain, requests)
+}
+
+func (rm *requestManager) subscribe(ctx context.Context, request rpc.Request, handler provider.NotificationHandler) (rpc.Response, error) {
+	return provider.Subscribe(ctx, rm.subscriber(), request, handler)
+}
+
+func (rm *requestManager) unsubscribe(subscriptionID string) {
+	provider.Unsubscribe(rm.subscriber(), subscriptionID)
+}
+
+// subscriber returns the provider to subscribe through: the middlewares if
+// the outermost one forwards subscriptions, the base provider otherwise.
+func (rm *requestManager) subscriber() provider.Provider {
+	if _, ok := rm.chain.(provider.Subscriber); ok {
+		return rm.chain
+	}
+	return rm.provider
 }
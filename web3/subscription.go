@@ -0,0 +1,181 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package web3
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/yangyuan6/web3go/provider"
+	"github.com/yangyuan6/web3go/rpc"
+)
+
+var (
+	// ErrSubscriptionNotSupported is returned when subscribing through a
+	// provider that cannot deliver notifications, e.g. the HTTP provider.
+	ErrSubscriptionNotSupported = provider.ErrSubscriptionNotSupported
+	// ErrSubscriptionOverflow is delivered on Err() when notifications arrive
+	// faster than they are consumed and the subscription had to be dropped.
+	ErrSubscriptionOverflow = errors.New("Subscription buffer overflow")
+)
+
+// Subscription is a WatchChannel fed by notifications the node pushes through
+// eth_subscribe instead of by polling. Err() delivers the reason the
+// subscription ended, if it was not closed by the caller, and is closed once
+// the subscription is over.
+type Subscription interface {
+	WatchChannel
+	ID() string
+	Err() <-chan error
+}
+
+type subscription struct {
+	requestManager *requestManager
+	id             string
+
+	mu     sync.Mutex
+	dataCh chan interface{}
+	errCh  chan error
+	done   bool
+}
+
+// -----------------------------------------------------------------------------
+// Subscription
+
+func newSubscription(requestManager *requestManager) *subscription {
+	return &subscription{
+		requestManager: requestManager,
+		dataCh:         make(chan interface{}, dataBufferSize),
+		errCh:          make(chan error, 1),
+	}
+}
+
+// subscribe issues eth_subscribe with params and returns a subscription that
+// receives the resulting notifications.
+func (eth *EthAPI) subscribe(ctx context.Context, params ...interface{}) (Subscription, error) {
+	sub := newSubscription(eth.requestManager)
+	req := eth.requestManager.newRequest("eth_subscribe", params...)
+	resp, err := eth.requestManager.subscribe(ctx, req, sub.handle)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error() != nil {
+		return nil, resp.Error()
+	}
+
+	id, ok := resp.Get("result").(string)
+	if !ok {
+		return nil, errors.New("Invalid subscription id")
+	}
+	sub.mu.Lock()
+	sub.id = id
+	sub.mu.Unlock()
+	return sub, nil
+}
+
+// handle is the provider.NotificationHandler of the subscription.
+func (sub *subscription) handle(notification rpc.Notification, err error) {
+	if err != nil {
+		sub.terminate(err)
+		return
+	}
+
+	sub.mu.Lock()
+	if sub.done {
+		sub.mu.Unlock()
+		return
+	}
+	select {
+	case sub.dataCh <- notification.Get("result"):
+		sub.mu.Unlock()
+	default:
+		sub.mu.Unlock()
+		// Handlers run on the provider's read loop, so the node has to be told
+		// from another goroutine.
+		go sub.unsubscribe()
+		sub.terminate(ErrSubscriptionOverflow)
+	}
+}
+
+// ID returns the subscription identifier assigned by the node.
+func (sub *subscription) ID() string {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	return sub.id
+}
+
+// Err returns a channel that delivers the error that ended the subscription.
+func (sub *subscription) Err() <-chan error {
+	return sub.errCh
+}
+
+// Next returns the result of the next notification.
+func (sub *subscription) Next() (interface{}, error) {
+	if data, ok := <-sub.dataCh; ok {
+		return data, nil
+	}
+	return nil, ErrChannelClosed
+}
+
+// Close cancels the subscription on the node and closes the channels.
+func (sub *subscription) Close() {
+	sub.mu.Lock()
+	done := sub.done
+	sub.mu.Unlock()
+
+	if !done {
+		sub.unsubscribe()
+	}
+	sub.terminate(nil)
+}
+
+func (sub *subscription) unsubscribe() {
+	id := sub.ID()
+	sub.requestManager.unsubscribe(id)
+	req := sub.requestManager.newRequest("eth_unsubscribe", id)
+	sub.requestManager.send(req)
+}
+
+func (sub *subscription) terminate(err error) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	if sub.done {
+		return
+	}
+	sub.done = true
+	if err != nil {
+		sub.errCh <- err
+	}
+	close(sub.errCh)
+	close(sub.dataCh)
+}
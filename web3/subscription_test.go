@@ -0,0 +1,175 @@
+// Copyright (c) 2016, Alan Chen
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package web3
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/yangyuan6/web3go/provider"
+	"github.com/yangyuan6/web3go/rpc"
+	"github.com/yangyuan6/web3go/test"
+)
+
+// subscribingProvider adds subscriptions to the mock provider, letting tests
+// push notifications by hand.
+type subscribingProvider struct {
+	provider.Provider
+
+	mu           sync.Mutex
+	handler      provider.NotificationHandler
+	unsubscribed []string
+}
+
+func (p *subscribingProvider) Subscribe(ctx context.Context, request rpc.Request, handler provider.NotificationHandler) (rpc.Response, error) {
+	p.mu.Lock()
+	p.handler = handler
+	p.mu.Unlock()
+	return p.GetRPCMethod().NewResponse([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":"0x1"}`, request.ID()))), nil
+}
+
+func (p *subscribingProvider) Unsubscribe(subscriptionID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handler = nil
+}
+
+func (p *subscribingProvider) SendContext(ctx context.Context, request rpc.Request) (rpc.Response, error) {
+	if request.Get("method") == "eth_unsubscribe" {
+		p.mu.Lock()
+		p.unsubscribed = append(p.unsubscribed, fmt.Sprintf("%v", request.Get("params").([]interface{})[0]))
+		p.mu.Unlock()
+		return p.GetRPCMethod().NewResponse([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":true}`, request.ID()))), nil
+	}
+	return p.Provider.SendContext(ctx, request)
+}
+
+func (p *subscribingProvider) push(result string) {
+	p.mu.Lock()
+	handler := p.handler
+	p.mu.Unlock()
+	handler(p.GetRPCMethod().NewNotification([]byte(
+		`{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0x1","result":"`+result+`"}}`)), nil)
+}
+
+type SubscriptionTestSuite struct {
+	suite.Suite
+	provider *subscribingProvider
+	eth      Eth
+}
+
+func (suite *SubscriptionTestSuite) Test_SubscribeNewPendingTransactions() {
+	sub, err := suite.eth.SubscribeNewPendingTransactions()
+	if !assert.NoError(suite.T(), err, "Should be no error") {
+		return
+	}
+	assert.EqualValues(suite.T(), "0x1", sub.ID(), "should be equal")
+
+	suite.provider.push("0xd6fdc5cc41a9959e922f30cb772a9aef46f4daea279307bc5f7024edc4ccd7fa")
+	result, err := sub.Next()
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.EqualValues(suite.T(), "0xd6fdc5cc41a9959e922f30cb772a9aef46f4daea279307bc5f7024edc4ccd7fa", result, "should be equal")
+
+	sub.Close()
+	assert.EqualValues(suite.T(), []string{"0x1"}, suite.provider.unsubscribed, "should be equal")
+	_, err = sub.Next()
+	assert.Equal(suite.T(), ErrChannelClosed, err, "should be equal")
+	_, ok := <-sub.Err()
+	assert.False(suite.T(), ok, "should be closed without error")
+}
+
+func (suite *SubscriptionTestSuite) Test_ConnectionLost() {
+	sub, err := suite.eth.SubscribeNewHeads()
+	if !assert.NoError(suite.T(), err, "Should be no error") {
+		return
+	}
+
+	suite.provider.handler(nil, provider.ErrConnectionLost)
+	assert.Equal(suite.T(), provider.ErrConnectionLost, <-sub.Err(), "should be equal")
+	_, err = sub.Next()
+	assert.Equal(suite.T(), ErrChannelClosed, err, "should be equal")
+}
+
+func (suite *SubscriptionTestSuite) Test_Overflow() {
+	sub, err := suite.eth.SubscribeNewHeads()
+	if !assert.NoError(suite.T(), err, "Should be no error") {
+		return
+	}
+
+	for i := 0; i <= dataBufferSize; i++ {
+		suite.provider.push("0x0")
+	}
+	assert.Equal(suite.T(), ErrSubscriptionOverflow, <-sub.Err(), "should be equal")
+}
+
+func (suite *SubscriptionTestSuite) Test_NotSupported() {
+	web3 := NewWeb3(test.NewMockHTTPProvider())
+	_, err := web3.Eth.SubscribeNewHeads()
+	assert.Equal(suite.T(), ErrSubscriptionNotSupported, err, "should be equal")
+}
+
+func (suite *SubscriptionTestSuite) Test_Middlewares() {
+	var methods []string
+	spy := func(next provider.Provider) provider.Provider {
+		return &methodSpy{Provider: next, methods: &methods}
+	}
+
+	eth := NewWeb3(suite.provider, spy).Eth
+	sub, err := eth.SubscribeNewHeads()
+	if !assert.NoError(suite.T(), err, "Should be no error") {
+		return
+	}
+	sub.Close()
+	assert.Equal(suite.T(), []string{"eth_unsubscribe"}, methods, "should be equal")
+}
+
+// methodSpy records the methods of the requests sent through it.
+type methodSpy struct {
+	provider.Provider
+	methods *[]string
+}
+
+func (p *methodSpy) SendContext(ctx context.Context, request rpc.Request) (rpc.Response, error) {
+	*p.methods = append(*p.methods, request.Get("method").(string))
+	return p.Provider.SendContext(ctx, request)
+}
+
+func (suite *SubscriptionTestSuite) SetupTest() {
+	suite.provider = &subscribingProvider{Provider: test.NewMockHTTPProvider()}
+	suite.eth = NewWeb3(suite.provider).Eth
+}
+
+func Test_SubscriptionTestSuite(t *testing.T) {
+	suite.Run(t, new(SubscriptionTestSuite))
+}
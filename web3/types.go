@@ -31,35 +31,55 @@ package web3
 
 import (
 	"encoding/json"
-	"math/big"
 
 	"github.com/yangyuan6/web3go/common"
 )
 
+type jsonSyncStatus struct {
+	StartingBlock *common.HexBig `json:"startingBlock"`
+	CurrentBlock  *common.HexBig `json:"currentBlock"`
+	HighestBlock  *common.HexBig `json:"highestBlock"`
+}
+
+func (s *jsonSyncStatus) ToSyncStatus() common.SyncStatus {
+	return common.SyncStatus{
+		Result:        true,
+		StartingBlock: s.StartingBlock.ToInt(),
+		CurrentBlock:  s.CurrentBlock.ToInt(),
+		HighestBlock:  s.HighestBlock.ToInt(),
+	}
+}
+
 type jsonBlock struct {
-	Number          json.Number    `json:"number"`
-	Hash            common.Hash    `json:"hash"`
-	ParentHash      common.Hash    `json:"parentHash"`
-	Nonce           common.Hash    `json:"nonce"`
-	Sha3Uncles      common.Hash    `json:"sha3Uncles"`
-	Bloom           common.Hash    `json:"logsBloom"`
-	TransactionRoot common.Hash    `json:"transactionsRoot"`
-	StateRoot       common.Hash    `json:"stateRoot"`
-	Miner           common.Address `json:"miner"`
-	Difficulty      json.Number    `json:"difficulty"`
-	TotalDifficulty json.Number    `json:"totalDifficulty"`
-	ExtraData       common.Hash    `json:"extraData"`
-	Size            json.Number    `json:"size"`
-	GasLimit        json.Number    `json:"gasLimit"`
-	GasUsed         json.Number    `json:"gasUsed"`
-	Timestamp       json.Number    `json:"timestamp"`
-	Transactions    []common.Hash  `json:"transactions"`
-	Uncles          []common.Hash  `json:"uncles"`
+	Number          *common.HexBig        `json:"number"`
+	Hash            common.Hash           `json:"hash"`
+	ParentHash      common.Hash           `json:"parentHash"`
+	Nonce           common.BlockNonce     `json:"nonce"`
+	Sha3Uncles      common.Hash           `json:"sha3Uncles"`
+	Bloom           common.Bloom          `json:"logsBloom"`
+	TransactionRoot common.Hash           `json:"transactionsRoot"`
+	StateRoot       common.Hash           `json:"stateRoot"`
+	Miner           common.Address        `json:"miner"`
+	Difficulty      *common.HexBig        `json:"difficulty"`
+	TotalDifficulty *common.HexBig        `json:"totalDifficulty"`
+	ExtraData       common.HexBytes       `json:"extraData"`
+	Size            *common.HexBig        `json:"size"`
+	GasLimit        *common.HexBig        `json:"gasLimit"`
+	GasUsed         *common.HexBig        `json:"gasUsed"`
+	Timestamp       *common.HexBig        `json:"timestamp"`
+	Transactions    jsonBlockTransactions `json:"transactions"`
+	Uncles          []common.Hash         `json:"uncles"`
+}
+
+// newJSONBlock returns a block to decode the response to a request for it,
+// with or without its full transactions.
+func newJSONBlock(full bool) *jsonBlock {
+	return &jsonBlock{Transactions: jsonBlockTransactions{isFull: full}}
 }
 
 func (b *jsonBlock) ToBlock() (block *common.Block) {
 	block = &common.Block{}
-	block.Number = jsonNumbertoInt(b.Number)
+	block.Number = b.Number.ToInt()
 	block.Hash = b.Hash
 	block.ParentHash = b.ParentHash
 	block.Nonce = b.Nonce
@@ -68,67 +88,102 @@ func (b *jsonBlock) ToBlock() (block *common.Block) {
 	block.TransactionRoot = b.TransactionRoot
 	block.StateRoot = b.StateRoot
 	block.Miner = b.Miner
-	block.Difficulty = jsonNumbertoInt(b.Difficulty)
-	block.TotalDifficulty = jsonNumbertoInt(b.TotalDifficulty)
+	block.Difficulty = b.Difficulty.ToInt()
+	block.TotalDifficulty = b.TotalDifficulty.ToInt()
 	block.ExtraData = b.ExtraData
-	block.Size = jsonNumbertoInt(b.Size)
-	block.GasLimit = jsonNumbertoInt(b.GasLimit)
-	block.GasUsed = jsonNumbertoInt(b.GasUsed)
-	block.Timestamp = jsonNumbertoInt(b.Timestamp)
-	block.Transactions = b.Transactions
+	block.Size = b.Size.ToInt()
+	block.GasLimit = b.GasLimit.ToInt()
+	block.GasUsed = b.GasUsed.ToInt()
+	block.Timestamp = b.Timestamp.ToInt()
+	block.Transactions = b.Transactions.ToBlockTransactions()
 	block.Uncles = b.Uncles
 	return block
 }
 
+// jsonBlockTransactions holds the transaction hashes of a block, or its full
+// transactions, as the node sent them. An empty array keeps isFull as set
+// before decoding, i.e. as requested.
+type jsonBlockTransactions struct {
+	hashes []common.Hash
+	full   []jsonTransaction
+	isFull bool
+}
+
+func (t *jsonBlockTransactions) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw) == 0 {
+		return nil
+	}
+	t.isFull = len(raw[0]) > 0 && raw[0][0] == '{'
+	if t.isFull {
+		return json.Unmarshal(data, &t.full)
+	}
+	return json.Unmarshal(data, &t.hashes)
+}
+
+func (t jsonBlockTransactions) ToBlockTransactions() common.BlockTransactions {
+	if !t.isFull {
+		return common.NewTransactionHashes(t.hashes)
+	}
+	txs := make([]common.Transaction, 0, len(t.full))
+	for _, tx := range t.full {
+		txs = append(txs, *tx.ToTransaction())
+	}
+	return common.NewFullTransactions(txs)
+}
+
 type jsonTransaction struct {
-	Hash             common.Hash    `json:"hash"`
-	Nonce            common.Hash    `json:"nonce"`
-	BlockHash        common.Hash    `json:"blockHash"`
-	BlockNumber      json.Number    `json:"blockNumber"`
-	TransactionIndex uint64         `json:"transactionIndex"`
-	From             common.Address `json:"from"`
-	To               common.Address `json:"to"`
-	Gas              json.Number    `json:"gas"`
-	GasPrice         json.Number    `json:"gasprice"`
-	Value            json.Number    `json:"value"`
-	Data             []byte         `json:"input"`
+	Hash             common.Hash      `json:"hash"`
+	Nonce            common.HexUint64 `json:"nonce"`
+	BlockHash        common.Hash      `json:"blockHash"`
+	BlockNumber      *common.HexBig   `json:"blockNumber"`
+	TransactionIndex common.HexUint64 `json:"transactionIndex"`
+	From             common.Address   `json:"from"`
+	To               common.Address   `json:"to"`
+	Gas              *common.HexBig   `json:"gas"`
+	GasPrice         *common.HexBig   `json:"gasPrice"`
+	Value            *common.HexBig   `json:"value"`
+	Data             common.HexBytes  `json:"input"`
 }
 
 func (t *jsonTransaction) ToTransaction() (tx *common.Transaction) {
 	tx = &common.Transaction{}
 	tx.Hash = t.Hash
-	tx.Nonce = t.Nonce
+	tx.Nonce = uint64(t.Nonce)
 	tx.BlockHash = t.BlockHash
-	tx.BlockNumber = jsonNumbertoInt(t.BlockNumber)
-	tx.TransactionIndex = t.TransactionIndex
+	tx.BlockNumber = t.BlockNumber.ToInt()
+	tx.TransactionIndex = uint64(t.TransactionIndex)
 	tx.From = t.From
 	tx.To = t.To
-	tx.Gas = jsonNumbertoInt(t.Gas)
-	tx.GasPrice = jsonNumbertoInt(t.GasPrice)
-	tx.Value = jsonNumbertoInt(t.Value)
+	tx.Gas = t.Gas.ToInt()
+	tx.GasPrice = t.GasPrice.ToInt()
+	tx.Value = t.Value.ToInt()
 	tx.Data = t.Data
 	return tx
 }
 
 type jsonTransactionReceipt struct {
-	Hash              common.Hash    `json:"transactionHash"`
-	TransactionIndex  uint64         `json:"transactionIndex"`
-	BlockNumber       json.Number    `json:"blockNumber"`
-	BlockHash         common.Hash    `json:"blockHash"`
-	CumulativeGasUsed json.Number    `json:"cumulativeGasUsed"`
-	GasUsed           json.Number    `json:"gasUsed"`
-	ContractAddress   common.Address `json:"contractAddress"`
-	Logs              []jsonLog      `json:"logs"`
+	Hash              common.Hash      `json:"transactionHash"`
+	TransactionIndex  common.HexUint64 `json:"transactionIndex"`
+	BlockNumber       *common.HexBig   `json:"blockNumber"`
+	BlockHash         common.Hash      `json:"blockHash"`
+	CumulativeGasUsed *common.HexBig   `json:"cumulativeGasUsed"`
+	GasUsed           *common.HexBig   `json:"gasUsed"`
+	ContractAddress   common.Address   `json:"contractAddress"`
+	Logs              []jsonLog        `json:"logs"`
 }
 
 func (r *jsonTransactionReceipt) ToTransactionReceipt() (receipt *common.TransactionReceipt) {
 	receipt = &common.TransactionReceipt{}
 	receipt.Hash = r.Hash
-	receipt.TransactionIndex = r.TransactionIndex
-	receipt.BlockNumber = jsonNumbertoInt(r.BlockNumber)
+	receipt.TransactionIndex = uint64(r.TransactionIndex)
+	receipt.BlockNumber = r.BlockNumber.ToInt()
 	receipt.BlockHash = r.BlockHash
-	receipt.CumulativeGasUsed = jsonNumbertoInt(r.CumulativeGasUsed)
-	receipt.GasUsed = jsonNumbertoInt(r.GasUsed)
+	receipt.CumulativeGasUsed = r.CumulativeGasUsed.ToInt()
+	receipt.GasUsed = r.GasUsed.ToInt()
 	receipt.ContractAddress = r.ContractAddress
 	receipt.Logs = make([]common.Log, 0)
 	for _, l := range r.Logs {
@@ -138,32 +193,25 @@ func (r *jsonTransactionReceipt) ToTransactionReceipt() (receipt *common.Transac
 }
 
 type jsonLog struct {
-	LogIndex         uint64         `json:"logIndex"`
-	BlockNumber      json.Number    `json:"blockNumber"`
-	BlockHash        common.Hash    `json:"blockHash"`
-	TransactionHash  common.Hash    `json:"transactionHash"`
-	TransactionIndex uint64         `json:"transactionIndex"`
-	Address          common.Address `json:"address"`
-	Data             []byte         `json:"data"`
-	Topics           common.Topics  `json:"topics"`
+	LogIndex         common.HexUint64 `json:"logIndex"`
+	BlockNumber      *common.HexBig   `json:"blockNumber"`
+	BlockHash        common.Hash      `json:"blockHash"`
+	TransactionHash  common.Hash      `json:"transactionHash"`
+	TransactionIndex common.HexUint64 `json:"transactionIndex"`
+	Address          common.Address   `json:"address"`
+	Data             common.HexBytes  `json:"data"`
+	Topics           common.Topics    `json:"topics"`
 }
 
 func (l jsonLog) ToLog() (log common.Log) {
 	log = common.Log{}
-	log.LogIndex = l.LogIndex
-	log.BlockNumber = jsonNumbertoInt(l.BlockNumber)
+	log.LogIndex = uint64(l.LogIndex)
+	log.BlockNumber = l.BlockNumber.ToInt()
 	log.BlockHash = l.BlockHash
 	log.TransactionHash = l.TransactionHash
-	log.TransactionIndex = l.TransactionIndex
+	log.TransactionIndex = uint64(l.TransactionIndex)
 	log.Address = l.Address
 	log.Data = l.Data
 	log.Topics = l.Topics
 	return log
 }
-
-func jsonNumbertoInt(data json.Number) *big.Int {
-	f := big.NewFloat(0.0)
-	f.SetString(string(data))
-	result, _ := f.Int(nil)
-	return result
-}
@@ -31,11 +31,11 @@ package web3
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"math"
 	"math/big"
-	"regexp"
 	"strconv"
 	"strings"
 
@@ -87,9 +87,13 @@ type Web3 struct {
 	Net            Net
 }
 
-// NewWeb3 creates a new web3 object.
-func NewWeb3(provider provider.Provider) *Web3 {
-	requestManager := newRequestManager(provider)
+// NewWeb3 creates a new web3 object. Requests, batches and subscriptions go
+// through the middlewares, the first one being the outermost. Batches pass a
+// middleware that is not a provider.BatchSender one request after another, and
+// subscriptions skip the chain if its outermost middleware is not a
+// provider.Subscriber.
+func NewWeb3(provider provider.Provider, middlewares ...provider.Middleware) *Web3 {
+	requestManager := newRequestManager(provider, middlewares...)
 	return &Web3{
 		provider:       provider,
 		requestManager: requestManager,
@@ -112,6 +116,26 @@ func (web3 *Web3) CurrentProvider() provider.Provider {
 	return web3.provider
 }
 
+// CallRaw sends a request to any method, e.g. one this package does not wrap
+// yet, and returns its result undecoded.
+func (web3 *Web3) CallRaw(method string, params ...interface{}) (json.RawMessage, error) {
+	return web3.CallRawContext(context.Background(), method, params...)
+}
+
+// CallRawContext is like CallRaw but takes a context.
+func (web3 *Web3) CallRawContext(ctx context.Context, method string, params ...interface{}) (json.RawMessage, error) {
+	resp, err := web3.requestManager.sendContext(ctx, web3.requestManager.newRequest(method, params...))
+	if err != nil {
+		return nil, err
+	}
+
+	var result json.RawMessage
+	if err := resp.DecodeResult(&result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 // Reset state of web3. Resets everything except manager. Uninstalls all
 // filters. Stops polling. If keepSyncing is true, it will uninstall all
 // filters, but will keep the web3.eth.IsSyncing() polls.
@@ -296,34 +320,21 @@ func (web3 *Web3) ToBigNumber(value interface{}) (result *big.Rat) {
 	return result
 }
 
-// IsAddress checks if the given string is an address.
+// IsAddress checks if the given string is an address. Addresses in mixed case
+// must carry a valid EIP-55 checksum.
 func (web3 *Web3) IsAddress(address string) bool {
-	smallCapsMatcher := regexp.MustCompile("^(0x)?[0-9a-f]{40}$")
-	smallCapsMatched := smallCapsMatcher.MatchString(address)
-	allCapsMatcher := regexp.MustCompile("^(0x)?[0-9A-F]{40}$")
-	allCapsMatched := allCapsMatcher.MatchString(address)
-	if smallCapsMatched || allCapsMatched {
-		return true
-	}
-	return web3.isChecksumAddress(address)
+	_, err := common.ParseAddress(address)
+	return err == nil
 }
 
-func (web3 *Web3) isChecksumAddress(address string) bool {
-	addr := strings.Replace(address, "0x", "", -1)
-	addressHash := web3.Sha3(strings.ToLower(addr), "")
-
-	for i := 0; i < 40; i++ {
-		d, err := strconv.ParseInt(string(addressHash[i]), 16, 32)
-		if err != nil {
-			return false
-		}
-
-		if d > 7 && strings.ToUpper(string(address[i])) == string(address[i]) ||
-			d <= 7 && strings.ToLower(string(address[i])) == string(address[i]) {
-			return false
-		}
+// ToChecksumAddress returns the address with the EIP-55 checksum, or an empty
+// string if it is not a valid address.
+func (web3 *Web3) ToChecksumAddress(address string) string {
+	addr, err := common.ParseAddress(address)
+	if err != nil {
+		return ""
 	}
-	return true
+	return addr.Hex()
 }
 
 func (web3 *Web3) sha3Hash(data ...[]byte) []byte {
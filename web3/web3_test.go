@@ -48,6 +48,15 @@ func (suite *Web3TestSuite) Test_IsConnected() {
 	assert.Equal(suite.T(), web3.IsConnected(), true, "should be true")
 }
 
+func (suite *Web3TestSuite) Test_CallRaw() {
+	result, err := suite.web3.CallRaw("eth_getBalance", "0x407d73d8a49eeb85d32cf465507dd71d507100c1", "latest")
+	assert.NoError(suite.T(), err, "Should be no error")
+	assert.EqualValues(suite.T(), `"0x234c8a3397aab58"`, string(result), "Should be equal")
+
+	_, err = suite.web3.CallRaw("eth_unknown")
+	assert.Error(suite.T(), err, "Should be error")
+}
+
 func (suite *Web3TestSuite) Test_Sha3() {
 	web3 := suite.web3
 	s := "Some string to be hashed"
@@ -167,6 +176,13 @@ func (suite *Web3TestSuite) Test_IsAddress() {
 	assert.Equal(suite.T(), false, web3.IsAddress(s), "should be equal")
 }
 
+func (suite *Web3TestSuite) Test_ToChecksumAddress() {
+	web3 := suite.web3
+	assert.Equal(suite.T(), "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", web3.ToChecksumAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"), "should be equal")
+	assert.Equal(suite.T(), true, web3.IsAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"), "should be equal")
+	assert.Equal(suite.T(), "", web3.ToChecksumAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD"), "should be equal")
+}
+
 func (suite *Web3TestSuite) SetupTest() {
 	suite.web3 = NewWeb3(test.NewMockHTTPProvider())
 }